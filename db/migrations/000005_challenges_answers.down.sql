@@ -0,0 +1 @@
+DROP TABLE IF EXISTS chall_answers;
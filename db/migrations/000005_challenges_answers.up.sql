@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS chall_answers(
+    id              UUID PRIMARY KEY,
+    user_id         UUID REFERENCES users(id) NOT NULL,
+    question_id     UUID REFERENCES chall_questions(id) NOT NULL,
+    answers         TEXT NOT NULL,
+    answered_at     TIMESTAMP NOT NULL
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS points_couple_day_idx;
+ALTER TABLE points DROP COLUMN IF EXISTS couple_id;
+ALTER TABLE points DROP COLUMN IF EXISTS source_id;
+ALTER TABLE points DROP COLUMN IF EXISTS reason;
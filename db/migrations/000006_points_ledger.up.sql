@@ -0,0 +1,9 @@
+ALTER TABLE points ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT 'CONNECTED';
+ALTER TABLE points ADD COLUMN IF NOT EXISTS source_id UUID;
+ALTER TABLE points ADD COLUMN IF NOT EXISTS couple_id UUID REFERENCES couples(id);
+ALTER TABLE points ALTER COLUMN reason DROP DEFAULT;
+
+UPDATE points p SET couple_id = c.id 
+FROM couples c WHERE p.user_id = c.he_id OR p.user_id = c.she_id;
+
+CREATE INDEX IF NOT EXISTS points_couple_day_idx ON points(couple_id, day);
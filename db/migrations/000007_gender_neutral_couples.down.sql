@@ -0,0 +1,3 @@
+ALTER TABLE couples DROP CONSTRAINT IF EXISTS couples_different_partners;
+ALTER TABLE couples RENAME COLUMN partner2_id TO she_id;
+ALTER TABLE couples RENAME COLUMN partner1_id TO he_id;
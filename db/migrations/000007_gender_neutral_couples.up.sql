@@ -0,0 +1,3 @@
+ALTER TABLE couples RENAME COLUMN he_id TO partner1_id;
+ALTER TABLE couples RENAME COLUMN she_id TO partner2_id;
+ALTER TABLE couples ADD CONSTRAINT couples_different_partners CHECK (partner1_id <> partner2_id);
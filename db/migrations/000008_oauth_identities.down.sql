@@ -0,0 +1 @@
+DROP INDEX IF EXISTS users_auth_oauth_identity_idx;
@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS users_auth_oauth_identity_idx ON users_auth(oauth_provider, oauth_id) WHERE oauth_provider IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auth_tokens;
+ALTER TABLE users_auth DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,13 @@
+ALTER TABLE users_auth ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS auth_tokens(
+    id                  UUID PRIMARY KEY,
+    token_hash          TEXT NOT NULL UNIQUE,
+    purpose             TEXT NOT NULL,
+    expires_at          TIMESTAMP NOT NULL,
+    used_at             TIMESTAMP,
+    created_at          TIMESTAMP NOT NULL,
+    user_auth_id        UUID REFERENCES users_auth(id) ON DELETE CASCADE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS auth_tokens_user_auth_idx ON auth_tokens(user_auth_id, purpose);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS rotated_tokens;
+DROP INDEX IF EXISTS sessions_token_idx;
+-- the hashes can't be reverted, so the sessions are closed
+DELETE FROM sessions;
@@ -0,0 +1,10 @@
+-- the tokens are stored hashed from now on
+UPDATE sessions SET token = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_token_idx ON sessions(token);
+
+CREATE TABLE IF NOT EXISTS rotated_tokens(
+    token_hash      TEXT PRIMARY KEY,
+    session_id      UUID REFERENCES sessions(id) ON DELETE CASCADE NOT NULL,
+    rotated_at      TIMESTAMP NOT NULL
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS admin_sessions_token_idx;
+DELETE FROM admin_sessions;
+ALTER TABLE admin_sessions DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE admin_sessions DROP COLUMN IF EXISTS admin_id;
+DROP TABLE IF EXISTS admins;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS admins(
+    id              UUID PRIMARY KEY,
+    email           TEXT NOT NULL UNIQUE,
+    hash            TEXT NOT NULL,
+    totp_secret     TEXT,
+    totp_enabled    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMP NOT NULL
+);
+
+-- the admin sessions without an admin can't be trusted, and their tokens are stored hashed from now on
+DELETE FROM admin_sessions;
+ALTER TABLE admin_sessions ADD COLUMN IF NOT EXISTS admin_id UUID REFERENCES admins(id) ON DELETE CASCADE NOT NULL;
+ALTER TABLE admin_sessions ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS admin_sessions_token_idx ON admin_sessions(token);
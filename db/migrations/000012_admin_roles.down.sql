@@ -0,0 +1,2 @@
+ALTER TABLE admins DROP COLUMN IF EXISTS permissions;
+ALTER TABLE admins DROP COLUMN IF EXISTS role;
@@ -0,0 +1,4 @@
+-- the admins created before the roles keep being able to do everything
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'SUPER_ADMIN';
+ALTER TABLE admins ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS permissions TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS rate_limits(
+    key             TEXT PRIMARY KEY,
+    hits            INT NOT NULL,
+    window_start    TIMESTAMP NOT NULL,
+    locked_until    TIMESTAMP
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS temp_couples_expires_at_idx;
+ALTER TABLE temp_couples DROP COLUMN IF EXISTS qr_url;
+ALTER TABLE temp_couples DROP COLUMN IF EXISTS qr_image_id;
+ALTER TABLE temp_couples DROP COLUMN IF EXISTS invite_token;
+ALTER TABLE temp_couples DROP COLUMN IF EXISTS expires_at;
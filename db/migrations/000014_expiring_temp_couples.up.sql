@@ -0,0 +1,10 @@
+ALTER TABLE temp_couples ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+-- the existing codes get the default life from their last update
+UPDATE temp_couples SET expires_at = updated_at + INTERVAL '1 hour';
+ALTER TABLE temp_couples ALTER COLUMN expires_at SET NOT NULL;
+
+ALTER TABLE temp_couples ADD COLUMN IF NOT EXISTS invite_token TEXT UNIQUE;
+ALTER TABLE temp_couples ADD COLUMN IF NOT EXISTS qr_image_id UUID REFERENCES files(id);
+ALTER TABLE temp_couples ADD COLUMN IF NOT EXISTS qr_url TEXT;
+
+CREATE INDEX IF NOT EXISTS temp_couples_expires_at_idx ON temp_couples(expires_at);
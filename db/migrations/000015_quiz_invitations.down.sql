@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS quiz_invitations_status_idx;
+DROP INDEX IF EXISTS quiz_invitations_pending_idx;
+DROP TABLE IF EXISTS quiz_invitations;
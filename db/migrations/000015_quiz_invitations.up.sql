@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS quiz_invitations(
+    id              UUID PRIMARY KEY,
+    quiz_id         UUID REFERENCES quizzes(id) ON DELETE CASCADE NOT NULL,
+    couple_id       UUID REFERENCES couples(id) ON DELETE CASCADE NOT NULL,
+    inviter_id      UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    invitee_id      UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    play_id         UUID REFERENCES quizzes_played(id) ON DELETE CASCADE NOT NULL,
+    status          TEXT NOT NULL,
+    created_at      TIMESTAMP NOT NULL,
+    expires_at      TIMESTAMP NOT NULL,
+    reminded_at     TIMESTAMP,
+    accepted_at     TIMESTAMP
+);
+
+-- a couple can only have one pending invitation per quiz
+CREATE UNIQUE INDEX IF NOT EXISTS quiz_invitations_pending_idx ON quiz_invitations(couple_id, quiz_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS quiz_invitations_status_idx ON quiz_invitations(status, created_at);
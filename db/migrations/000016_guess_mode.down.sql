@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS quiz_guesses_couple_quiz_user_idx;
+DROP TABLE IF EXISTS quiz_guesses;
+ALTER TABLE quizzes_played DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,15 @@
+ALTER TABLE quizzes_played ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT 'NORMAL';
+
+-- the guesses of each user about the partner answers, scored once both partners completed the quiz
+CREATE TABLE IF NOT EXISTS quiz_guesses(
+    id              UUID PRIMARY KEY,
+    play_id         UUID REFERENCES quizzes_played(id) ON DELETE CASCADE NOT NULL,
+    quiz_id         UUID REFERENCES quizzes(id) ON DELETE CASCADE NOT NULL,
+    couple_id       UUID REFERENCES couples(id) ON DELETE CASCADE NOT NULL,
+    user_id         UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    guessed_right   REAL NOT NULL,
+    guesses         INTEGER NOT NULL,
+    scored_at       TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS quiz_guesses_couple_quiz_user_idx ON quiz_guesses(couple_id, quiz_id, user_id);
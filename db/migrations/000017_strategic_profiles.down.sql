@@ -0,0 +1 @@
+DROP TABLE IF EXISTS strategic_profiles;
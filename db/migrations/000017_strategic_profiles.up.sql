@@ -0,0 +1,23 @@
+-- running tally of the strategic types that the answers of each user map to
+CREATE TABLE IF NOT EXISTS strategic_profiles(
+    user_id                 UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    strategic_answer_id     UUID REFERENCES strategic_type_answers(id) ON DELETE CASCADE NOT NULL,
+    score                   REAL NOT NULL,
+    answers                 INTEGER NOT NULL,
+    updated_at              TIMESTAMP NOT NULL,
+    PRIMARY KEY(user_id, strategic_answer_id)
+);
+
+-- the answers given before, with the same weights as the service: true counts fully, false doesn't,
+-- the slider counts its value over 100 and the rest of the types count fully
+INSERT INTO strategic_profiles(user_id, strategic_answer_id, score, answers, updated_at)
+SELECT a.user_id, q.strategic_answer_id,
+    SUM(CASE q.question_type
+        WHEN 'TRUE_FALSE' THEN CASE WHEN (a.answers::jsonb->>'ownAnswer')::boolean THEN 1 ELSE 0 END
+        WHEN 'SLIDER' THEN (a.answers::jsonb->>'ownAnswer')::real / 100
+        ELSE 1 END),
+    COUNT(*), NOW()
+FROM user_answers a JOIN quiz_questions q ON q.id = a.question_id
+WHERE q.strategic_answer_id IS NOT NULL
+GROUP BY a.user_id, q.strategic_answer_id
+ON CONFLICT DO NOTHING;
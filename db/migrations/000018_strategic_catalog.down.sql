@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS strategic_type_translations;
+ALTER TABLE strategic_type_answers DROP COLUMN IF EXISTS active;
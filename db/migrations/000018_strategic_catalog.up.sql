@@ -0,0 +1,10 @@
+-- retired types stay for the questions and profiles that use them but can't be assigned anymore
+ALTER TABLE strategic_type_answers ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE TABLE IF NOT EXISTS strategic_type_translations(
+    strategic_answer_id     UUID REFERENCES strategic_type_answers(id) ON DELETE CASCADE NOT NULL,
+    language_code           TEXT NOT NULL,
+    name                    TEXT NOT NULL,
+    description             TEXT NOT NULL,
+    PRIMARY KEY(strategic_answer_id, language_code)
+);
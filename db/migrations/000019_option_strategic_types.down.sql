@@ -0,0 +1,17 @@
+-- the multiple choice questions whose options share one type get it back, the rest of the options lose theirs
+UPDATE quiz_questions q SET strategic_answer_id = (q.options_json->'opts'->0->>'stId')::uuid
+WHERE q.question_type = 'MULTIPLE_CH' AND q.strategic_answer_id IS NULL
+    AND jsonb_array_length(q.options_json->'opts') > 0
+    AND (SELECT bool_and(e.opt ? 'stId') AND COUNT(DISTINCT e.opt->>'stId') = 1
+        FROM jsonb_array_elements(q.options_json->'opts') AS e(opt));
+
+UPDATE quiz_questions q SET options_json = (
+    SELECT jsonb_object_agg(f.key, CASE WHEN jsonb_typeof(f.value) = 'array' THEN
+            (SELECT COALESCE(jsonb_agg(
+                CASE WHEN jsonb_typeof(e.opt) = 'object' THEN e.opt - 'stId' - 'stW' ELSE e.opt END ORDER BY e.i), '[]'::jsonb)
+            FROM jsonb_array_elements(f.value) WITH ORDINALITY AS e(opt, i))
+        ELSE f.value END)
+    FROM jsonb_each(q.options_json) AS f(key, value)
+)
+WHERE q.question_type IN ('ORDERING', 'MULTIPLE_CH', 'MATCHING', 'DRAG_AND_DROP')
+    AND q.options_json::text LIKE '%"st%';
@@ -0,0 +1,12 @@
+-- the options can carry their own strategic type (stId) and weight (stW). The single answer multiple
+-- choice questions give the same credit with the type in every option, so their type moves to the options
+UPDATE quiz_questions q SET 
+    options_json = jsonb_set(
+        q.options_json, '{opts}',
+        (SELECT jsonb_agg(e.opt || jsonb_build_object('stId', q.strategic_answer_id) ORDER BY e.i)
+        FROM jsonb_array_elements(q.options_json->'opts') WITH ORDINALITY AS e(opt, i))
+    ),
+    strategic_answer_id = NULL
+WHERE q.question_type = 'MULTIPLE_CH' AND q.strategic_answer_id IS NOT NULL
+    AND NOT COALESCE((q.options_json->>'multAns')::boolean, FALSE)
+    AND jsonb_array_length(q.options_json->'opts') > 0;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS user_answers_play_idx;
+DROP INDEX IF EXISTS quizzes_played_quiz_user_couple_idx;
+ALTER TABLE user_answers DROP COLUMN IF EXISTS play_id;
+ALTER TABLE quizzes_played DROP COLUMN IF EXISTS couple_id;
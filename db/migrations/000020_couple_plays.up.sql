@@ -0,0 +1,20 @@
+-- the plays belong to the couple the user had when starting them and the answers to their play,
+-- so a new couple starts the quizzes from zero
+ALTER TABLE quizzes_played ADD COLUMN IF NOT EXISTS couple_id UUID REFERENCES couples(id);
+ALTER TABLE user_answers ADD COLUMN IF NOT EXISTS play_id UUID REFERENCES quizzes_played(id) ON DELETE CASCADE;
+
+-- the plays before the first couple of the user go to it, since they were already compared with it
+UPDATE quizzes_played p SET couple_id = (
+    SELECT c.id FROM couples c
+    WHERE (c.partner1_id = p.user_id OR c.partner2_id = p.user_id)
+        AND (c.end_date IS NULL OR c.end_date >= p.started_at::date)
+    ORDER BY c.relation_start LIMIT 1
+);
+
+-- until now every user had a single play per quiz
+UPDATE user_answers a SET play_id = p.id
+FROM quizzes_played p JOIN quiz_questions q ON q.quiz_id = p.quiz_id
+WHERE q.id = a.question_id AND p.user_id = a.user_id;
+
+CREATE INDEX IF NOT EXISTS quizzes_played_quiz_user_couple_idx ON quizzes_played(quiz_id, user_id, couple_id);
+CREATE INDEX IF NOT EXISTS user_answers_play_idx ON user_answers(play_id);
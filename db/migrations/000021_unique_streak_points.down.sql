@@ -0,0 +1 @@
+DROP INDEX IF EXISTS points_couple_day_streak_idx;
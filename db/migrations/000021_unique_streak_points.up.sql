@@ -0,0 +1,5 @@
+-- the streak bonus is awarded once a day per couple, the duplicates of concurrent completions are removed
+DELETE FROM points p USING points d
+WHERE p.reason = 'STREAK' AND d.reason = 'STREAK' AND p.couple_id = d.couple_id AND p.day = d.day AND p.id > d.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS points_couple_day_streak_idx ON points(couple_id, day) WHERE reason = 'STREAK';
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -10,12 +11,30 @@ type Config struct {
 	AuthConfig 	*AuthConfig
 	PostgresConfig *PostgresConfig
 	InteractionConfig *InteractionConfig
+	MailConfig 	*MailConfig
+	RateLimitConfig *RateLimitConfig
+	CouplesConfig 	*CouplesConfig
+	EventsConfig 	*EventsConfig
+	QuizInvitationsConfig *QuizInvitationsConfig
 }
 
 type AuthConfig struct{
 	AccessTokenLife 	int64
 	RefreshTokenLife 	int64
-	JwtSecret 			string
+	JwtKeysDir 			string
+	JwtActiveKid 		string
+	SessionsSweepInterval int64
+	AdminEmail 			string
+	AdminPassword 		string
+	TotpIssuer 			string
+	OAuthProviders 		map[string]*OAuthProviderConfig
+}
+
+// the provider is only enabled if its client id (the expected audience) is configured
+type OAuthProviderConfig struct{
+	JwksSource 		string
+	Audience 		string
+	Issuers 		[]string
 }
 
 type PostgresConfig struct{
@@ -24,6 +43,43 @@ type PostgresConfig struct{
 
 type InteractionConfig struct{
 	MaxFetchResult	int
+	LiveQuestionTime int64
+	StrategicCatalogOnly bool
+}
+
+type MailConfig struct{
+	Sender 			string
+	SmtpHost 		string
+	SmtpPort 		string
+	SmtpUsername 	string
+	SmtpPassword 	string
+	From 			string
+	FilesFolder 	string
+	LinksUrl 		string
+}
+
+// the postgres store shares the limits between instances, the memory one only works with a single instance
+type RateLimitConfig struct{
+	Store 			string
+	CleanInterval 	int64
+}
+
+// the invite links open the app, which connects the couple with the token of the link
+type CouplesConfig struct{
+	CodeLife 		int64
+	InviteLinksUrl 	string
+}
+
+// the invitations of a partner to play a quiz later, the times are in seconds
+type QuizInvitationsConfig struct{
+	Life 			int64
+	RemindAfter 	int64
+	CheckInterval 	int64
+}
+
+// the postgres bus is needed to run more than one instance, it uses the same database
+type EventsConfig struct{
+	Bus 			string
 }
 
 func NewConfig() *Config {
@@ -32,17 +88,47 @@ func NewConfig() *Config {
 		AuthConfig: NewAuthConfig(),
 		PostgresConfig: NewPostgresConfig(),
 		InteractionConfig: NewInteractionConfig(),
+		MailConfig: NewMailConfig(),
+		RateLimitConfig: NewRateLimitConfig(),
+		CouplesConfig: NewCouplesConfig(),
+		EventsConfig: NewEventsConfig(),
+		QuizInvitationsConfig: NewQuizInvitationsConfig(),
 	}
 }
 
 func NewAuthConfig() *AuthConfig{
 	return &AuthConfig{
 		AccessTokenLife: getEnvAsInt64("ACCESS_TOKEN_LIFE", 3600),
-		JwtSecret: getEnv("JWT_SECRET", "secret"),
-		RefreshTokenLife: getEnvAsInt64("REFRESH_TOKEN_LIFE", 1000000000),
+		JwtKeysDir: getEnv("JWT_KEYS_DIR", "keys"),
+		JwtActiveKid: getEnv("JWT_ACTIVE_KID", ""),
+		RefreshTokenLife: getEnvAsInt64("REFRESH_TOKEN_LIFE", 2592000),
+		SessionsSweepInterval: getEnvAsInt64("SESSIONS_SWEEP_INTERVAL", 3600),
+		AdminEmail: getEnv("ADMIN_EMAIL", ""),
+		AdminPassword: getEnv("ADMIN_PASSWORD", ""),
+		TotpIssuer: getEnv("TOTP_ISSUER", "Couples"),
+		OAuthProviders: NewOAuthProviders(),
 	}
 }
 
+func NewOAuthProviders() map[string]*OAuthProviderConfig{
+	providers := make(map[string]*OAuthProviderConfig)
+	if audience := getEnv("OAUTH_GOOGLE_CLIENT_ID", ""); audience != ""{
+		providers["google"] = &OAuthProviderConfig{
+			JwksSource: getEnv("OAUTH_GOOGLE_JWKS", "https://www.googleapis.com/oauth2/v3/certs"),
+			Audience: audience,
+			Issuers: getEnvAsList("OAUTH_GOOGLE_ISSUERS", "https://accounts.google.com,accounts.google.com"),
+		}
+	}
+	if audience := getEnv("OAUTH_APPLE_CLIENT_ID", ""); audience != ""{
+		providers["apple"] = &OAuthProviderConfig{
+			JwksSource: getEnv("OAUTH_APPLE_JWKS", "https://appleid.apple.com/auth/keys"),
+			Audience: audience,
+			Issuers: getEnvAsList("OAUTH_APPLE_ISSUERS", "https://appleid.apple.com"),
+		}
+	}
+	return providers
+}
+
 func NewPostgresConfig() *PostgresConfig{
 	return &PostgresConfig{
 		Address: getEnv("POSTGRES_DB", ""),
@@ -52,6 +138,49 @@ func NewPostgresConfig() *PostgresConfig{
 func NewInteractionConfig() *InteractionConfig{
 	return &InteractionConfig{
 		MaxFetchResult: int(getEnvAsInt64("MAX_RESULT_LIMIT", 20)),
+		LiveQuestionTime: getEnvAsInt64("LIVE_QUESTION_TIME", 60),
+		StrategicCatalogOnly: getEnv("STRATEGIC_CATALOG_ONLY", "false") == "true",
+	}
+}
+
+func NewMailConfig() *MailConfig{
+	return &MailConfig{
+		Sender: getEnv("MAIL_SENDER", "file"),
+		SmtpHost: getEnv("SMTP_HOST", ""),
+		SmtpPort: getEnv("SMTP_PORT", "587"),
+		SmtpUsername: getEnv("SMTP_USERNAME", ""),
+		SmtpPassword: getEnv("SMTP_PASSWORD", ""),
+		From: getEnv("MAIL_FROM", "no-reply@couples.app"),
+		FilesFolder: getEnv("MAIL_FILES_FOLDER", "mails"),
+		LinksUrl: getEnv("MAIL_LINKS_URL", "couples://auth"),
+	}
+}
+
+func NewRateLimitConfig() *RateLimitConfig{
+	return &RateLimitConfig{
+		Store: getEnv("RATE_LIMIT_STORE", "memory"),
+		CleanInterval: getEnvAsInt64("RATE_LIMIT_CLEAN_INTERVAL", 3600),
+	}
+}
+
+func NewCouplesConfig() *CouplesConfig{
+	return &CouplesConfig{
+		CodeLife: getEnvAsInt64("COUPLE_CODE_LIFE", 3600),
+		InviteLinksUrl: getEnv("COUPLE_INVITE_LINKS_URL", "couples://invite"),
+	}
+}
+
+func NewEventsConfig() *EventsConfig{
+	return &EventsConfig{
+		Bus: getEnv("EVENT_BUS", "memory"),
+	}
+}
+
+func NewQuizInvitationsConfig() *QuizInvitationsConfig{
+	return &QuizInvitationsConfig{
+		Life: getEnvAsInt64("QUIZ_INVITATION_LIFE", 604800),
+		RemindAfter: getEnvAsInt64("QUIZ_INVITATION_REMIND_AFTER", 86400),
+		CheckInterval: getEnvAsInt64("QUIZ_INVITATIONS_CHECK_INTERVAL", 900),
 	}
 }
 
@@ -73,4 +202,15 @@ func getEnvAsInt64(envir string, fallCase int64) int64{
 		return num
 	}
 	return fallCase
-}
\ No newline at end of file
+}
+
+func getEnvAsList(envir string, fallCase string) []string{
+	values := strings.Split(getEnv(envir, fallCase), ",")
+	list := make([]string, 0, len(values))
+	for _, value := range values{
+		if value = strings.TrimSpace(value); value != ""{
+			list = append(list, value)
+		}
+	}
+	return list
+}
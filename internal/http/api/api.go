@@ -9,14 +9,25 @@ import (
 	"github.com/diegobermudez03/couples-backend/internal/config"
 	"github.com/diegobermudez03/couples-backend/internal/http/handlers"
 	"github.com/diegobermudez03/couples-backend/internal/http/middlewares"
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
 	"github.com/diegobermudez03/couples-backend/pkg/auth/appauth"
 	"github.com/diegobermudez03/couples-backend/pkg/auth/repoauth"
+	"github.com/diegobermudez03/couples-backend/pkg/challenges/appchallenges"
+	"github.com/diegobermudez03/couples-backend/pkg/challenges/repochallenges"
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/diegobermudez03/couples-backend/pkg/events/appevents"
+	"github.com/diegobermudez03/couples-backend/pkg/events/repoevents"
 	"github.com/diegobermudez03/couples-backend/pkg/files/appfiles"
 	"github.com/diegobermudez03/couples-backend/pkg/files/repofiles"
 	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
 	"github.com/diegobermudez03/couples-backend/pkg/localization/applocalization"
+	"github.com/diegobermudez03/couples-backend/pkg/mail"
+	"github.com/diegobermudez03/couples-backend/pkg/mail/repomail"
 	"github.com/diegobermudez03/couples-backend/pkg/quizzes/appquizzes"
 	"github.com/diegobermudez03/couples-backend/pkg/quizzes/repoquizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit/appratelimit"
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit/reporatelimit"
 	"github.com/diegobermudez03/couples-backend/pkg/users/appusers"
 	"github.com/diegobermudez03/couples-backend/pkg/users/repousers"
 	"github.com/go-chi/chi/v5"
@@ -28,6 +39,10 @@ type APIServer struct {
 	server 	http.Server
 	config  *config.Config
 	db 		*sql.DB
+	sessionsSweeper *appauth.SessionsSweeper
+	rateLimitCleaner *appratelimit.StaleKeysCleaner
+	invitationsReminder *appquizzes.InvitationsReminder
+	eventBus 	events.Bus
 }
 
 func NewAPIServer(config *config.Config, db *sql.DB) *APIServer {
@@ -47,7 +62,6 @@ func (s *APIServer) Run() error{
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 	r.Use(cors.Handler(cors.Options{
 		// AllowedOrigins:   []string{"https://foo.com"}, // Use this to allow specific origin hosts
 		AllowedOrigins:   []string{"https://*", "http://*"},
@@ -58,7 +72,10 @@ func (s *APIServer) Run() error{
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
 
-	r.Mount("/v1", router)
+	//the event streams outlive the request timeout, so only the rest of the API has it
+	streams := chi.NewMux()
+	r.Mount("/v1", streams)
+	streams.With(middleware.Timeout(60 * time.Second)).Mount("/", router)
 	// Health check
 	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -67,7 +84,9 @@ func (s *APIServer) Run() error{
 
 
 	//depencency injections
-	s.injectDependencies(router)
+	if err := s.injectDependencies(r, streams, router); err != nil{
+		return err
+	}
 
 	s.server = http.Server{
 		Addr: "localhost:" + s.config.Port,
@@ -78,11 +97,23 @@ func (s *APIServer) Run() error{
 
 
 func (s *APIServer) Shutdown() error{
+	if s.sessionsSweeper != nil{
+		s.sessionsSweeper.Stop()
+	}
+	if s.rateLimitCleaner != nil{
+		s.rateLimitCleaner.Stop()
+	}
+	if s.invitationsReminder != nil{
+		s.invitationsReminder.Stop()
+	}
+	if s.eventBus != nil{
+		s.eventBus.Close()
+	}
 	return s.server.Shutdown(context.TODO())
 }
 
 
-func (s *APIServer) injectDependencies(router *chi.Mux){
+func (s *APIServer) injectDependencies(root *chi.Mux, streams *chi.Mux, router *chi.Mux) error{
 	baseUrl := "http://localhost:" + s.config.Port + "/v1"
 
 	transactions := infraestructure.NewTransactions(s.db)
@@ -90,29 +121,108 @@ func (s *APIServer) injectDependencies(router *chi.Mux){
 	authRepository := repoauth.NewAuthPostgresRepo(s.db)
 	usersRepository := repousers.NewUsersPostgresRepo(s.db)
 	quizzesRepository := repoquizzes.NewQuizzesPostgresRepo(s.db)
+	challengesRepository := repochallenges.NewChallengesPostgresRepo(s.db)
 	filesRepository := repofiles.NewLocalStorage()
 	filesRepo := repofiles.NewFilesPostgresRepo(s.db)
+	rateLimitStore := s.createRateLimitStore()
+	s.eventBus = s.createEventBus()
 
 	//create services
+	eventsHub, err := appevents.NewHubImpl(s.eventBus)
+	if err != nil{
+		return err
+	}
 	filesService := appfiles.NewFilesServiceImpl(filesRepository, filesRepo, baseUrl)
 	localizationService := applocalization.NewLocalizationServiceImpl()
-	usersService := appusers.NewUsersServiceImpl(localizationService, usersRepository)
-	authService := appauth.NewAuthService(transactions, authRepository, usersService, s.config.AuthConfig.AccessTokenLife, s.config.AuthConfig.RefreshTokenLife, s.config.AuthConfig.JwtSecret)
-	authAdminService := appauth.NewAdminAuthService(authRepository, s.config.AuthConfig.JwtSecret, s.config.AuthConfig.AccessTokenLife)
+	usersService := appusers.NewUsersServiceImpl(transactions, localizationService, filesService, eventsHub, usersRepository, s.config.CouplesConfig.CodeLife, s.config.CouplesConfig.InviteLinksUrl)
+	pointsService := appusers.NewPointsServiceImpl(usersRepository, eventsHub)
+	limiter := appratelimit.NewServiceImpl(rateLimitStore)
+	keyring, err := appauth.LoadKeyring(s.config.AuthConfig.JwtKeysDir, s.config.AuthConfig.JwtActiveKid)
+	if err != nil{
+		return err
+	}
+	mailSender := s.createMailSender()
+	oauthVerifiers := make(map[string]auth.OAuthVerifier)
+	for provider, providerConfig := range s.config.AuthConfig.OAuthProviders{
+		oauthVerifiers[provider] = appauth.NewJwksOAuthVerifier(providerConfig.JwksSource, providerConfig.Audience, providerConfig.Issuers)
+	}
+	authService, err := appauth.NewAuthService(transactions, authRepository, usersService, s.config.AuthConfig.AccessTokenLife, s.config.AuthConfig.RefreshTokenLife, keyring, oauthVerifiers, mailSender, s.config.MailConfig.LinksUrl, limiter, s.eventBus)
+	if err != nil{
+		return err
+	}
+	authAdminService := appauth.NewAdminAuthService(authRepository, keyring, s.config.AuthConfig.AccessTokenLife, s.config.AuthConfig.RefreshTokenLife, s.config.AuthConfig.TotpIssuer, limiter)
 	quizzesAdminService := appquizzes.NewAdminServiceImpl(transactions, filesService, localizationService,quizzesRepository)
-	quizzesUserService := appquizzes.NewUserService(transactions,filesService, usersService, localizationService,quizzesRepository, s.config.InteractionConfig.MaxFetchResult)
+	quizzesUserService := appquizzes.NewUserService(transactions,filesService, usersService, pointsService, localizationService, eventsHub, quizzesRepository, s.config.InteractionConfig.MaxFetchResult, s.config.QuizInvitationsConfig.Life, s.config.InteractionConfig.StrategicCatalogOnly)
+	liveService := appquizzes.NewLiveServiceImpl(quizzesUserService, usersService, time.Duration(s.config.InteractionConfig.LiveQuestionTime) * time.Second)
+	challengesService := appchallenges.NewServiceImpl(transactions, quizzesUserService, usersService, pointsService, challengesRepository)
+
+	//the first admin comes from the configuration, the rest are created by admins
+	if s.config.AuthConfig.AdminEmail != ""{
+		if err := authAdminService.BootstrapAdmin(context.Background(), s.config.AuthConfig.AdminEmail, s.config.AuthConfig.AdminPassword); err != nil{
+			return err
+		}
+	}
+
+	//background jobs
+	s.sessionsSweeper = appauth.NewSessionsSweeper(authRepository, time.Duration(s.config.AuthConfig.SessionsSweepInterval) * time.Second)
+	s.sessionsSweeper.Start()
+	s.rateLimitCleaner = appratelimit.NewStaleKeysCleaner(rateLimitStore, time.Duration(s.config.RateLimitConfig.CleanInterval) * time.Second)
+	s.rateLimitCleaner.Start()
+	invitationsConfig := s.config.QuizInvitationsConfig
+	s.invitationsReminder = appquizzes.NewInvitationsReminder(quizzesRepository, eventsHub, time.Duration(invitationsConfig.RemindAfter) * time.Second, time.Duration(invitationsConfig.CheckInterval) * time.Second)
+	s.invitationsReminder.Start()
 
 	//middlewares
-	middlewares := middlewares.NewMiddlewares(authService, authAdminService, quizzesUserService)
+	middlewares := middlewares.NewMiddlewares(authService, authAdminService, quizzesUserService, limiter)
 	//create handlers
 	authHandler := handlers.NewAuthHandler(authService, authAdminService, middlewares)
-	usersHandler := handlers.NewUsersHandler(usersService, middlewares)
-	quizzesHandler := handlers.NewQuizzesHandler(quizzesAdminService,quizzesUserService, middlewares)
+	usersHandler := handlers.NewUsersHandler(usersService, pointsService, middlewares)
+	quizzesHandler := handlers.NewQuizzesHandler(quizzesAdminService,quizzesUserService, liveService, middlewares)
 	filesHandler := handlers.NewFilesHandler(filesService)
+	challengesHandler := handlers.NewChallengesHandler(challengesService, middlewares)
+	wellKnownHandler := handlers.NewWellKnownHandler(keyring)
+	eventsHandler := handlers.NewEventsHandler(eventsHub, middlewares)
 
 	//registering routes
 	authHandler.RegisterRoutes(router)
 	usersHandler.RegisterRoutes(router)
 	quizzesHandler.RegisterRoutes(router)
 	filesHandler.RegisterRoutes(router)
-}
\ No newline at end of file
+	challengesHandler.RegisterRoutes(router)
+	eventsHandler.RegisterRoutes(streams)
+	//well known documents go in the root, outside the API version
+	wellKnownHandler.RegisterRoutes(root)
+	return nil
+}
+
+func (s *APIServer) createMailSender() mail.Sender{
+	mailConfig := s.config.MailConfig
+	switch mailConfig.Sender{
+	case mail.SMTP_SENDER:
+		return repomail.NewSmtpSender(mailConfig.SmtpHost, mailConfig.SmtpPort, mailConfig.SmtpUsername, mailConfig.SmtpPassword, mailConfig.From)
+	case mail.MEMORY_SENDER:
+		return repomail.NewMemorySender()
+	default:
+		return repomail.NewFileSender(mailConfig.FilesFolder)
+	}
+}
+
+
+func (s *APIServer) createRateLimitStore() ratelimit.Store{
+	switch s.config.RateLimitConfig.Store{
+	case ratelimit.POSTGRES_STORE:
+		return reporatelimit.NewPostgresStore(s.db)
+	default:
+		return reporatelimit.NewMemoryStore()
+	}
+}
+
+
+func (s *APIServer) createEventBus() events.Bus{
+	switch s.config.EventsConfig.Bus{
+	case events.POSTGRES_BUS:
+		return repoevents.NewPostgresBus(s.db, s.config.PostgresConfig.Address)
+	default:
+		return repoevents.NewMemoryBus()
+	}
+}
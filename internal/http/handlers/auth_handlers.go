@@ -13,6 +13,10 @@ import (
 	"github.com/google/uuid"
 )
 
+const OAUTH_PROVIDER_URL_PARAM = "provider"
+const SESSION_ID_URL_PARAM = "sessionId"
+const ADMIN_ID_URL_PARAM = "adminId"
+
 type AuthHandler struct {
 	authService 	auth.AuthService
 	adminService 	auth.AuthAdminService
@@ -32,19 +36,36 @@ func (h *AuthHandler) RegisterRoutes(r *chi.Mux){
 	r.Mount("/auth", router)
 
 	router.Post("/register", h.registerEndpoint)
-	router.Post("/login", h.LoginEndpoint)
+	router.With(h.middlewares.RateLimit(auth.LOGIN_IP_POLICY, nil)).Post("/login", h.LoginEndpoint)
+	router.Post(fmt.Sprintf("/oauth/{%s}", OAUTH_PROVIDER_URL_PARAM), h.oauthLoginEndpoint)
 	router.Post("/users", h.createUserEndpoint)
+	router.Post("/email/verification", h.postEmailVerificationEndpoint)
+	router.Post("/email/verify", h.verifyEmailEndpoint)
+	router.Post("/password/forgot", h.forgotPasswordEndpoint)
+	router.Post("/password/reset", h.resetPasswordEndpoint)
 	router.Get("/users/status", h.checkExistanceEndpoint)
 	router.Delete("/users/logout", h.userLogoutEndpoint)
 	router.Post("/couples/temporal", h.postTempCoupleCodeEndpoint)
 	router.Get("/couples/temporal", h.getTempCoupleCodeEndpoint)
 	router.Get("/couples/temporal/notification", h.suscribeTempCoupleNotifications)
-	router.Post("/couples/connect", h.connectWithCoupleEndpoint)
-	router.Post("/accessToken", h.postAccessTokenEndpoint)
+	router.With(h.middlewares.RateLimit(auth.COUPLE_CODE_IP_POLICY, nil)).Post("/couples/connect", h.connectWithCoupleEndpoint)
+	router.Post("/couples/invite", h.connectWithInviteEndpoint)
+	router.With(h.middlewares.RateLimit(auth.REFRESH_IP_POLICY, nil)).Post("/accessToken", h.postAccessTokenEndpoint)
 	router.With(h.middlewares.CheckAccessToken).Delete("/logout", h.logoutEndpoint)
-
-
-	router.Post("/admin/accessToken", h.postAdminAccessTokenEndpoint)
+	router.With(h.middlewares.CheckAccessToken).Delete("/couples", h.disconnectCoupleEndpoint)
+	router.With(h.middlewares.CheckAccessToken).Get("/couples/notification", h.suscribeCoupleNotifications)
+	router.With(h.middlewares.CheckAccessToken).Get("/sessions", h.getSessionsEndpoint)
+	router.With(h.middlewares.CheckAccessToken).Delete("/sessions", h.revokeOtherSessionsEndpoint)
+	router.With(h.middlewares.CheckAccessToken).Delete(fmt.Sprintf("/sessions/{%s}", SESSION_ID_URL_PARAM), h.revokeSessionEndpoint)
+
+
+	router.With(h.middlewares.RateLimit(auth.ADMIN_LOGIN_IP_POLICY, nil)).Post("/admin/login", h.adminLoginEndpoint)
+	router.With(h.middlewares.RateLimit(auth.REFRESH_IP_POLICY, nil)).Post("/admin/accessToken", h.postAdminAccessTokenEndpoint)
+	router.With(h.middlewares.CheckAdminAccessToken).Delete("/admin/logout", h.adminLogoutEndpoint)
+	router.With(h.middlewares.CheckAdminAccessToken, h.middlewares.RequireAdminPermission(auth.PERMISSION_ADMINS_MANAGE)).Post("/admin/admins", h.postAdminEndpoint)
+	router.With(h.middlewares.CheckAdminAccessToken, h.middlewares.RequireAdminPermission(auth.PERMISSION_ADMINS_MANAGE)).Patch(fmt.Sprintf("/admin/admins/{%s}/role", ADMIN_ID_URL_PARAM), h.patchAdminRoleEndpoint)
+	router.With(h.middlewares.CheckAdminAccessToken).Post("/admin/totp", h.postAdminTotpEndpoint)
+	router.With(h.middlewares.CheckAdminAccessToken).Post("/admin/totp/confirm", h.confirmAdminTotpEndpoint)
 }
 
 ///////////////////////////////////////////////////////////////////////////////////
@@ -68,6 +89,35 @@ type loginDTO struct{
 	Os			string	`json:"os" validate:"required"`
 }
 
+type oauthLoginDTO struct{
+	IdToken 	string 	`json:"idToken" validate:"required"`
+	Device 		string 	`json:"device" validate:"required"`
+	Os			string	`json:"os" validate:"required"`
+}
+
+type resetPasswordDTO struct{
+	Token 		string 	`json:"token" validate:"required"`
+	Password 	string 	`json:"password" validate:"required"`
+}
+
+type adminLoginDTO struct{
+	Email 		string	`json:"email" validate:"email"`
+	Password 	string 	`json:"password" validate:"required"`
+	TotpCode 	string 	`json:"totpCode"`
+}
+
+type createAdminDTO struct{
+	Email 		string	`json:"email" validate:"email"`
+	Password 	string 	`json:"password" validate:"required"`
+	Role 		string 	`json:"role" validate:"required"`
+	Permissions []string `json:"permissions"`
+}
+
+type adminRoleDTO struct{
+	Role 		string 	`json:"role" validate:"required"`
+	Permissions []string `json:"permissions"`
+}
+
 type createUserDTO struct{
 	FirstName 		string 	`json:"firstName" validate:"required"`
 	LastName 		string 	`json:"lastName" validate:"required"`
@@ -80,6 +130,9 @@ type createUserDTO struct{
 type tempCoupleDTO struct{
 	Code 		int 	`json:"code"`
 	StartDate 	int64 	`json:"startDate"`
+	ExpiresAt 	int64 	`json:"expiresAt"`
+	QrUrl 		*string `json:"qrUrl"`
+	InviteLink 	*string `json:"inviteLink,omitempty"`
 }
 
 /////////////////////////////////// ERRORS CODES
@@ -115,6 +168,39 @@ var authErrorCodes = map[error] int{
 	auth.ErrNoCodeToSuscribe : http.StatusNotFound,
 	auth.ErrNonExistingCode : http.StatusBadRequest,
 	auth.ErrCantConnectWithYourself : http.StatusBadRequest,
+	auth.ErrorCoupleEnded : http.StatusUnauthorized,
+	auth.ErrorCheckingCouple : http.StatusInternalServerError,
+	auth.ErrorDisconnectingCouple : http.StatusInternalServerError,
+	auth.ErrUnsupportedOAuthProvider : http.StatusNotFound,
+	auth.ErrInvalidOAuthToken : http.StatusUnauthorized,
+	auth.ErrorGettingOAuthKeys : http.StatusBadGateway,
+	auth.ErrorWithOAuthLogin : http.StatusInternalServerError,
+	auth.ErrorSendingEmail : http.StatusInternalServerError,
+	auth.ErrAccountWithoutEmail : http.StatusBadRequest,
+	auth.ErrEmailAlreadyVerified : http.StatusConflict,
+	auth.ErrInvalidMailToken : http.StatusBadRequest,
+	auth.ErrorVerifyingEmail : http.StatusInternalServerError,
+	auth.ErrorResettingPassword : http.StatusInternalServerError,
+	auth.ErrorSessionRevoked : http.StatusUnauthorized,
+	auth.ErrorGettingSessions : http.StatusInternalServerError,
+	auth.ErrorRevokingSession : http.StatusInternalServerError,
+	auth.ErrorRefreshTokenReused : http.StatusUnauthorized,
+	auth.ErrInvalidAdminCredentials : http.StatusUnauthorized,
+	auth.ErrTotpCodeRequired : http.StatusUnauthorized,
+	auth.ErrInvalidTotpCode : http.StatusUnauthorized,
+	auth.ErrTotpNotSetUp : http.StatusBadRequest,
+	auth.ErrTotpAlreadyEnabled : http.StatusConflict,
+	auth.ErrorSettingUpTotp : http.StatusInternalServerError,
+	auth.ErrorAdminLogin : http.StatusInternalServerError,
+	auth.ErrorCreatingAdmin : http.StatusInternalServerError,
+	auth.ErrAdminEmailAlreadyUsed : http.StatusConflict,
+	auth.ErrAdminNotFound : http.StatusNotFound,
+	auth.ErrInvalidAdminRole : http.StatusBadRequest,
+	auth.ErrInvalidAdminPermission : http.StatusBadRequest,
+	auth.ErrCantChangeOwnRole : http.StatusForbidden,
+	auth.ErrorUpdatingAdminRole : http.StatusInternalServerError,
+	auth.ErrTooManyAttempts : http.StatusTooManyRequests,
+	auth.ErrExpiredCode : http.StatusGone,
 }
 
 
@@ -176,6 +262,95 @@ func (h *AuthHandler) LoginEndpoint(w http.ResponseWriter, r *http.Request){
 }
 
 
+func (h *AuthHandler) oauthLoginEndpoint(w http.ResponseWriter, r *http.Request){
+	dto := oauthLoginDTO{}
+	if err := utils.ReadJSON(r, &dto); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	provider := chi.URLParam(r, OAUTH_PROVIDER_URL_PARAM)
+	token := r.Header.Get("token")
+
+	refreshToken, err := h.authService.OAuthLogin(r.Context(), provider, dto.IdToken, dto.Device, dto.Os, token)
+	if err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(
+		w, 
+		http.StatusOK, 
+		map[string]any{
+			"refreshToken" : refreshToken,
+		},
+	)
+}
+
+
+func (h *AuthHandler) postEmailVerificationEndpoint(w http.ResponseWriter, r *http.Request){
+	token := r.Header.Get("token")
+	if token == ""{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrNoTokenProvided)
+		return 
+	}
+	if err := h.authService.SendEmailVerification(r.Context(), token); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+
+func (h *AuthHandler) verifyEmailEndpoint(w http.ResponseWriter, r *http.Request){
+	payload := struct{
+		Token 	string 	`json:"token" validate:"required"`
+	}{}
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	if err := h.authService.VerifyEmail(r.Context(), payload.Token); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+
+func (h *AuthHandler) forgotPasswordEndpoint(w http.ResponseWriter, r *http.Request){
+	payload := struct{
+		Email 	string 	`json:"email" validate:"email"`
+	}{}
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	if err := h.authService.RequestPasswordReset(r.Context(), payload.Email); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+
+func (h *AuthHandler) resetPasswordEndpoint(w http.ResponseWriter, r *http.Request){
+	payload := resetPasswordDTO{}
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	if err := h.authService.ResetPassword(r.Context(), payload.Token, payload.Password); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+
 func (h *AuthHandler) createUserEndpoint(w http.ResponseWriter, r *http.Request){
 	payload := createUserDTO{}
 	if err := utils.ReadJSON(r, &payload); err != nil{
@@ -252,6 +427,8 @@ func (h *AuthHandler) getTempCoupleCodeEndpoint(w http.ResponseWriter, r *http.R
 	dto := tempCoupleDTO{
 		Code: tempCouple.Code,
 		StartDate: tempCouple.StartDate.Unix(),
+		ExpiresAt: tempCouple.ExpiresAt.Unix(),
+		QrUrl: tempCouple.QrUrl,
 	}
 	utils.WriteJSON(
 		w, http.StatusOK, dto,
@@ -273,7 +450,7 @@ func (h *AuthHandler) postTempCoupleCodeEndpoint(w http.ResponseWriter, r *http.
 		return 
 	}
 
-	code, err := h.authService.CreateTempCouple(r.Context(), token, payload.StartDate)
+	tempCouple, err := h.authService.CreateTempCouple(r.Context(), token, payload.StartDate)
 	if err != nil{
 		code := utils.GetErrorCode(err, authErrorCodes, 500)
 		utils.WriteError(w, code, err)
@@ -281,8 +458,12 @@ func (h *AuthHandler) postTempCoupleCodeEndpoint(w http.ResponseWriter, r *http.
 	}
 	utils.WriteJSON(
 		w, http.StatusCreated,
-		map[string]int{
-			"code" : code,
+		tempCoupleDTO{
+			Code: tempCouple.Code,
+			StartDate: tempCouple.StartDate.Unix(),
+			ExpiresAt: tempCouple.ExpiresAt.Unix(),
+			QrUrl: tempCouple.QrUrl,
+			InviteLink: tempCouple.InviteLink,
 		},
 	)
 }
@@ -312,6 +493,31 @@ func (h *AuthHandler) connectWithCoupleEndpoint(w http.ResponseWriter, r *http.R
 	utils.WriteJSON(w, http.StatusCreated, map[string]string{"accessToken" : accessToken})
 }
 
+// the token is the one of the invite link shared by the partner
+func (h *AuthHandler) connectWithInviteEndpoint(w http.ResponseWriter, r *http.Request){
+	token := r.Header.Get("token")
+	if token == ""{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrNoTokenProvided)
+		return 
+	}
+
+	payload := struct{
+		InviteToken 	string 	`json:"inviteToken" validate:"required"`
+	}{}
+	if err := utils.ReadJSON(r,&payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+
+	accessToken, err := h.authService.ConnectCoupleByInvite(r.Context(), token, payload.InviteToken)
+	if err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]string{"accessToken" : accessToken})
+}
+
 func (h *AuthHandler) postAccessTokenEndpoint(w http.ResponseWriter, r *http.Request){
 	payload := struct{
 		RefreshToken 	string 	`json:"refreshToken" validate:"required"`
@@ -363,6 +569,107 @@ func (h *AuthHandler) postAdminAccessTokenEndpoint(w http.ResponseWriter, r *htt
 }
 
 
+func (h *AuthHandler) adminLoginEndpoint(w http.ResponseWriter, r *http.Request){
+	dto := adminLoginDTO{}
+	if err := utils.ReadJSON(r, &dto); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	refreshToken, err := h.adminService.LoginAdmin(r.Context(), dto.Email, dto.Password, dto.TotpCode)
+	if err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"refreshToken" : refreshToken,
+	})
+}
+
+
+func (h *AuthHandler) adminLogoutEndpoint(w http.ResponseWriter, r *http.Request){
+	sessionId := r.Context().Value(middlewares.SessionIdKey{}).(uuid.UUID)
+	if err := h.adminService.LogoutAdmin(r.Context(), sessionId); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+
+func (h *AuthHandler) postAdminEndpoint(w http.ResponseWriter, r *http.Request){
+	dto := createAdminDTO{}
+	if err := utils.ReadJSON(r, &dto); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	adminId, err := h.adminService.CreateAdmin(r.Context(), dto.Email, dto.Password, dto.Role, dto.Permissions)
+	if err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"adminId" : adminId,
+	})
+}
+
+
+func (h *AuthHandler) patchAdminRoleEndpoint(w http.ResponseWriter, r *http.Request){
+	currentAdminId := r.Context().Value(middlewares.AdminIdKey{}).(uuid.UUID)
+	adminId, err := uuid.Parse(chi.URLParam(r, ADMIN_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return 
+	}
+	dto := adminRoleDTO{}
+	if err := utils.ReadJSON(r, &dto); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	if err := h.adminService.UpdateAdminRole(r.Context(), currentAdminId, adminId, dto.Role, dto.Permissions); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+
+func (h *AuthHandler) postAdminTotpEndpoint(w http.ResponseWriter, r *http.Request){
+	adminId := r.Context().Value(middlewares.AdminIdKey{}).(uuid.UUID)
+	secret, url, err := h.adminService.SetupTotp(r.Context(), adminId)
+	if err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"secret" : secret,
+		"url" : url,
+	})
+}
+
+
+func (h *AuthHandler) confirmAdminTotpEndpoint(w http.ResponseWriter, r *http.Request){
+	adminId := r.Context().Value(middlewares.AdminIdKey{}).(uuid.UUID)
+	payload := struct{
+		Code 	string 	`json:"code" validate:"required"`
+	}{}
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	if err := h.adminService.EnableTotp(r.Context(), adminId, payload.Code); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+
 func (h *AuthHandler) logoutEndpoint(w http.ResponseWriter, r *http.Request){
 	sessionId := r.Context().Value(middlewares.SessionIdKey{}).(uuid.UUID)
 	if err := h.authService.LogoutSession(r.Context(), sessionId); err != nil{
@@ -373,6 +680,44 @@ func (h *AuthHandler) logoutEndpoint(w http.ResponseWriter, r *http.Request){
 	utils.WriteJSON(w, http.StatusNoContent, nil)
 }
 
+func (h *AuthHandler) getSessionsEndpoint(w http.ResponseWriter, r *http.Request){
+	sessionId := r.Context().Value(middlewares.SessionIdKey{}).(uuid.UUID)
+	sessions, err := h.authService.GetAccountSessions(r.Context(), sessionId)
+	if err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"sessions" : sessions,
+	})
+}
+
+func (h *AuthHandler) revokeSessionEndpoint(w http.ResponseWriter, r *http.Request){
+	currentSessionId := r.Context().Value(middlewares.SessionIdKey{}).(uuid.UUID)
+	sessionId, err := uuid.Parse(chi.URLParam(r, SESSION_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return 
+	}
+	if err := h.authService.RevokeSession(r.Context(), currentSessionId, sessionId); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *AuthHandler) revokeOtherSessionsEndpoint(w http.ResponseWriter, r *http.Request){
+	sessionId := r.Context().Value(middlewares.SessionIdKey{}).(uuid.UUID)
+	if err := h.authService.RevokeOtherSessions(r.Context(), sessionId); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
 func (h *AuthHandler) suscribeTempCoupleNotifications(w http.ResponseWriter, r *http.Request){
 	token := r.Header.Get("token")
 	if token == ""{
@@ -408,6 +753,8 @@ func (h *AuthHandler) suscribeTempCoupleNotifications(w http.ResponseWriter, r *
 	case received, ok :=<- channel:
 		if received == auth.StatusVinculated{
 			w.Write([]byte(fmt.Sprintf("data: %s\n\n", received)))
+		}else if received == auth.StatusCodeExpired{
+			w.Write([]byte(fmt.Sprintf("event: expired\ndata: %s\n\n", received)))
 		}else{
 			w.Write([]byte("data: ERROR\n\n"))
 		}
@@ -422,6 +769,53 @@ func (h *AuthHandler) suscribeTempCoupleNotifications(w http.ResponseWriter, r *
 		//here it closes the connection but is not because of the message but because the handler function ends
 	}
 }
+
+func (h *AuthHandler) disconnectCoupleEndpoint(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	if err := h.authService.DisconnectCouple(r.Context(), userId); err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *AuthHandler) suscribeCoupleNotifications(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	channel, err := h.authService.SuscribeCoupleNot(r.Context(), userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, authErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return
+	}
+	// SETTING SSE
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte("data: CONNECTED\n\n"))
+	flusher.Flush()
+
+	select{
+	case <- r.Context().Done():
+		h.authService.RemoveCoupleSuscriber(userId)
+	case received, ok :=<- channel:
+		if !ok{
+			//another connection of the same user replaced this one
+			return
+		}
+		w.Write([]byte(fmt.Sprintf("data: %s\n\n", received)))
+		flusher.Flush()
+		w.Write([]byte("event:close\ndata: Connection closing\n\n"))
+		flusher.Flush()
+		h.authService.RemoveCoupleSuscriber(userId)
+	}
+}
 ////////////////////////////////////////////////////////////////////////////////////
 /////////////////////////////////////////////////////////////////////////////////////
 ///////////////////////////////////////////////////////////////////////////////////
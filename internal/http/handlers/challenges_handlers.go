@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/diegobermudez03/couples-backend/internal/http/middlewares"
+	"github.com/diegobermudez03/couples-backend/internal/utils"
+	"github.com/diegobermudez03/couples-backend/pkg/challenges"
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const CHALLENGE_ID_URL_PARAM = "challengeId"
+
+type ChallengesHandler struct {
+	service     challenges.Service
+	middlewares *middlewares.Middlewares
+}
+
+func NewChallengesHandler(service challenges.Service, middlewares *middlewares.Middlewares) *ChallengesHandler {
+	return &ChallengesHandler{
+		service:     service,
+		middlewares: middlewares,
+	}
+}
+
+func (h *ChallengesHandler) RegisterRoutes(r *chi.Mux) {
+	router := chi.NewMux()
+	router.Use(h.middlewares.CheckAccessToken)
+
+	r.Mount("/challenges", router)
+
+	//	challenge handlers
+	router.Get("/", h.getChallenges)
+	router.Post("/", h.postChallenge)
+	router.Patch(fmt.Sprintf("/{%s}/publish", CHALLENGE_ID_URL_PARAM), h.patchPublishChallenge)
+	// 	question handlers
+	router.Post(fmt.Sprintf("/{%s}/questions", CHALLENGE_ID_URL_PARAM), h.postQuestion)
+	router.Delete(fmt.Sprintf("/questions/{%s}", QUESTION_ID_URL_PARAM), h.deleteQuestion)
+	//	play handlers
+	router.Post(fmt.Sprintf("/{%s}/plays", CHALLENGE_ID_URL_PARAM), h.postChallengePlay)
+	router.Get(fmt.Sprintf("/plays/{%s}/questions", PLAY_ID_URL_PARAM), h.getPlayQuestions)
+	router.Post(fmt.Sprintf("/plays/{%s}/questions/{%s}/answers", PLAY_ID_URL_PARAM, QUESTION_ID_URL_PARAM), h.postQuestionAnswer)
+	router.Patch(fmt.Sprintf("/plays/{%s}/complete", PLAY_ID_URL_PARAM), h.patchCompletePlay)
+}
+
+
+///////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////////////////
+//////////////////			ENDPOINTS						///////////////////////
+///////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////////////////
+
+///// DTOS
+
+type postChallengeDTO struct{
+	Name 		string 	`json:"name" validate:"required"`
+	Description string 	`json:"description"`
+}
+
+type postChallengeQuestionDTO struct{
+	Question 			string 		`json:"question" validate:"required"`
+	QuestionType		string 		`json:"questionType" validate:"required"`
+	OptionsJson			map[string]any 	`json:"optionsJson"`
+}
+
+type postChallengePlayDTO struct{
+	Shared 		bool 	`json:"shared"`
+}
+
+/////////////////////////////////// ERRORS CODES
+
+var challengesErrorCodes = map[error]int{
+	challenges.ErrEmptyChallengeName : http.StatusBadRequest,
+	challenges.ErrCreatingChallenge : http.StatusInternalServerError,
+	challenges.ErrRetrievingChallenges : http.StatusInternalServerError,
+	challenges.ErrChallengeNotFound : http.StatusNotFound,
+	challenges.ErrUnathorizedToEditChallenge : http.StatusUnauthorized,
+	challenges.ErrChallengeAlreadyPublished : http.StatusNotModified,
+	challenges.ErrUnableToPublish : http.StatusInternalServerError,
+	challenges.ErrEmptyQuestion : http.StatusBadRequest,
+	challenges.ErrCreatingQuestion : http.StatusInternalServerError,
+	challenges.ErrQuestionNotFound : http.StatusNotFound,
+	challenges.ErrDeletingQuestion : http.StatusInternalServerError,
+	challenges.ErrCantEditPublishedChallenge : http.StatusConflict,
+	challenges.ErrStartingChallenge : http.StatusInternalServerError,
+	challenges.ErrChallengeNotPublished : http.StatusBadRequest,
+	challenges.ErrChallengeAlreadyPlayed : http.StatusConflict,
+	challenges.ErrChallengePlayNotFound : http.StatusNotFound,
+	challenges.ErrChallengePlayAlreadyCompleted : http.StatusConflict,
+	challenges.ErrRetrievingQuestions : http.StatusInternalServerError,
+	challenges.ErrQuestionNotInChallenge : http.StatusBadRequest,
+	challenges.ErrAnsweringQuestion : http.StatusInternalServerError,
+	challenges.ErrMissingAnswers : http.StatusBadRequest,
+	challenges.ErrCompletingChallenge : http.StatusInternalServerError,
+	quizzes.ErrInvalidQuestionType : http.StatusBadRequest,
+	quizzes.ErrInvalidQuestionOptions : http.StatusBadRequest,
+	quizzes.ErrInvalidPlaceholder : http.StatusBadRequest,
+	quizzes.ErrInvalidAnswerKey : http.StatusBadRequest,
+	quizzes.ErrInvalidAnswer : http.StatusBadRequest,
+	users.ErrorNoCoupleFound : http.StatusBadRequest,
+	users.ErrorCantGetCouple : http.StatusInternalServerError,
+}
+
+
+func (h *ChallengesHandler) getChallenges(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	models, err := h.service.GetChallenges(r.Context(), userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, models)
+}
+
+func (h *ChallengesHandler) postChallenge(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	var payload postChallengeDTO
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	challengeId, err := h.service.CreateChallenge(r.Context(), userId, payload.Name, payload.Description)
+	if err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"challengeId" : challengeId,
+	})
+}
+
+func (h *ChallengesHandler) patchPublishChallenge(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	challengeId, err := uuid.Parse(chi.URLParam(r, CHALLENGE_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	if err := h.service.PublishChallenge(r.Context(), challengeId, userId); err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *ChallengesHandler) postQuestion(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	challengeId, err := uuid.Parse(chi.URLParam(r, CHALLENGE_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+
+	const maxUploadSize = 20 << 20
+	var payload postChallengeQuestionDTO
+	if err := utils.ParseAndReadMultiPartForm(w, r, maxUploadSize, &payload, "question"); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	//reading all images passed
+	images := map[string]io.Reader{}
+	files := r.MultipartForm.File["images"]
+	for _, header := range files{
+		file, err := header.Open()
+		if err == nil{
+			defer file.Close()
+			images[header.Filename] = file
+		}
+	}
+
+	questionId, err := h.service.CreateQuestion(r.Context(), challengeId, userId,
+		challenges.CreateQuestionRequest{
+			Question: payload.Question,
+			QType: payload.QuestionType,
+			OptionsJson: payload.OptionsJson,
+		},
+		images,
+	)
+	if err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"questionId" : questionId,
+	})
+}
+
+func (h *ChallengesHandler) deleteQuestion(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	questionId, err := uuid.Parse(chi.URLParam(r, QUESTION_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrEmptyQuestionId)
+		return
+	}
+	if err := h.service.DeleteQuestion(r.Context(), questionId, userId); err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *ChallengesHandler) postChallengePlay(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	challengeId, err := uuid.Parse(chi.URLParam(r, CHALLENGE_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	var payload postChallengePlayDTO
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	playId, err := h.service.StartChallenge(r.Context(), challengeId, userId, payload.Shared)
+	if err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"playId" : playId,
+	})
+}
+
+func (h *ChallengesHandler) getPlayQuestions(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	playId, err := uuid.Parse(chi.URLParam(r, PLAY_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	questions, err := h.service.GetPlayQuestions(r.Context(), playId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, questions)
+}
+
+func (h *ChallengesHandler) postQuestionAnswer(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	playId, err := uuid.Parse(chi.URLParam(r, PLAY_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	questionId, err := uuid.Parse(chi.URLParam(r, QUESTION_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrEmptyQuestionId)
+		return
+	}
+	var payload postAnswerDTO
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	err = h.service.AnswerQuestion(r.Context(), playId, questionId, userId, quizzes.AnswerRequest{
+		OwnAnswer: payload.OwnAnswer,
+		GuessedPartner: payload.GuessedPartner,
+	})
+	if err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, nil)
+}
+
+func (h *ChallengesHandler) patchCompletePlay(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	playId, err := uuid.Parse(chi.URLParam(r, PLAY_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	play, err := h.service.CompleteChallenge(r.Context(), playId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, challengesErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, play)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/internal/http/middlewares"
+	"github.com/diegobermudez03/couples-backend/internal/utils"
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const LAST_EVENT_ID_HEADER = "Last-Event-ID"
+const LAST_EVENT_ID_QUERY = "lastEventId"
+const EVENTS_HEARTBEAT_INTERVAL = 20 * time.Second
+
+type EventsHandler struct{
+	hub 			events.Hub
+	middlewares 	*middlewares.Middlewares
+}
+
+func NewEventsHandler(hub events.Hub, middlewares *middlewares.Middlewares) *EventsHandler{
+	return &EventsHandler{
+		hub: hub,
+		middlewares: middlewares,
+	}
+}
+
+func (h *EventsHandler) RegisterRoutes(r *chi.Mux){
+	router := chi.NewMux()
+	router.Use(h.middlewares.CheckAccessToken)
+
+	r.Mount("/events", router)
+
+	router.Get("/", h.suscribeEventsEndpoint)
+}
+
+/////////////////////////////////// ERRORS CODES
+
+var eventsErrorCodes = map[error] int{
+	events.ErrNoCoupleToSuscribe : http.StatusNotFound,
+	events.ErrInvalidLastEventId : http.StatusBadRequest,
+}
+
+///////////////////////////////// HANDLERS
+
+// stream of the events of the couple, the events of the user itself aren't sent.
+// Reconnecting with Last-Event-ID (or the lastEventId query, for clients that can't set headers) resends the missed events
+func (h *EventsHandler) suscribeEventsEndpoint(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	coupleId := r.Context().Value(middlewares.CoupleIdKey{}).(uuid.UUID)
+	if coupleId == uuid.Nil{
+		utils.WriteError(w, http.StatusNotFound, events.ErrNoCoupleToSuscribe)
+		return
+	}
+	lastEventId, err := getLastEventId(r)
+	if err != nil{
+		code := utils.GetErrorCode(err, eventsErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+	suscription, missed := h.hub.Suscribe(coupleId, lastEventId)
+	defer h.hub.Unsuscribe(suscription)
+
+	// SETTING SSE
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range missed{
+		writeEvent(w, userId, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(EVENTS_HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+	for{
+		select{
+		case <- r.Context().Done():
+			return
+		case <- heartbeat.C:
+			w.Write([]byte(fmt.Sprintf("event: ping\ndata: %d\n\n", time.Now().Unix())))
+			flusher.Flush()
+		case event, ok := <- suscription.Events:
+			if !ok{
+				//the hub dropped the suscription, the client resumes from its last event
+				return
+			}
+			writeEvent(w, userId, event)
+			flusher.Flush()
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////// PRIVATE FUNCTIONS
+
+func getLastEventId(r *http.Request) (int64, error){
+	value := r.Header.Get(LAST_EVENT_ID_HEADER)
+	if value == ""{
+		value = r.URL.Query().Get(LAST_EVENT_ID_QUERY)
+	}
+	if value == ""{
+		return 0, nil
+	}
+	lastEventId, err := strconv.ParseInt(value, 10, 64)
+	if err != nil{
+		return 0, events.ErrInvalidLastEventId
+	}
+	return lastEventId, nil
+}
+
+func writeEvent(w http.ResponseWriter, userId uuid.UUID, event events.EventModel){
+	if event.UserId == userId{
+		return
+	}
+	data, err := json.Marshal(event)
+	if err != nil{
+		return
+	}
+	w.Write([]byte(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)))
+}
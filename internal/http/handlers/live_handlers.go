@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/internal/http/middlewares"
+	"github.com/diegobermudez03/couples-backend/internal/utils"
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const LIVE_WRITE_TIMEOUT = 10 * time.Second
+const LIVE_PONG_TIMEOUT = 60 * time.Second
+const LIVE_PING_INTERVAL = 25 * time.Second
+const LIVE_MAX_MESSAGE_SIZE = 64 << 10 //64KB
+
+//types of the messages sent by the clients
+const (
+	LIVE_CLIENT_ANSWERING = "ANSWERING"
+	LIVE_CLIENT_ANSWER = "ANSWER"
+)
+
+// the apps don't send an origin, and the API already accepts any origin
+var liveUpgrader = websocket.Upgrader{
+	ReadBufferSize: 1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type liveClientMessageDTO struct{
+	Type 			string 			`json:"type"`
+	QuestionId 		uuid.UUID 		`json:"questionId"`
+	OwnAnswer 		json.RawMessage	`json:"ownAnswer"`
+	GuessedPartner 	json.RawMessage	`json:"guessedPartner"`
+}
+
+// websocket of the live session of the couple in the quiz, the errors before joining are
+// returned as normal responses, the ones after are sent as ERROR messages
+func (h *QuizzesHandler) getLiveSession(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	quizId, err := uuid.Parse(chi.URLParam(r, QUIZ_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	//the session outlives the request timeout
+	ctx := context.WithoutCancel(r.Context())
+	connection, err := h.liveService.JoinLiveSession(ctx, quizId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return
+	}
+	socket, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil{
+		h.liveService.LeaveLiveSession(connection)
+		return
+	}
+	defer socket.Close()
+
+	replies := make(chan quizzes.LiveMessageModel, quizzes.LIVE_MESSAGES_BUFFER_SIZE)
+	writerDone := make(chan struct{})
+	go writeLiveMessages(socket, connection, replies, writerDone)
+
+	socket.SetReadLimit(LIVE_MAX_MESSAGE_SIZE)
+	socket.SetReadDeadline(time.Now().Add(LIVE_PONG_TIMEOUT))
+	socket.SetPongHandler(func(string) error {
+		return socket.SetReadDeadline(time.Now().Add(LIVE_PONG_TIMEOUT))
+	})
+	for{
+		var message liveClientMessageDTO
+		if err := socket.ReadJSON(&message); err != nil{
+			break
+		}
+		switch message.Type{
+		case LIVE_CLIENT_ANSWERING:
+			h.liveService.SetAnswering(connection, message.QuestionId)
+		case LIVE_CLIENT_ANSWER:
+			err = h.liveService.AnswerLiveQuestion(ctx, connection, message.QuestionId, quizzes.AnswerRequest{
+				OwnAnswer: message.OwnAnswer,
+				GuessedPartner: message.GuessedPartner,
+			})
+		default:
+			err = quizzes.ErrInvalidLiveMessage
+		}
+		if err != nil{
+			select{
+			case replies <- quizzes.LiveMessageModel{Type: quizzes.LIVE_ERROR, QuestionId: &message.QuestionId, Error: err.Error()}:
+			default:
+			}
+		}
+	}
+	h.liveService.LeaveLiveSession(connection)
+	<- writerDone
+}
+
+/////////////////////////////////////////////////////////////////////////////////////
+/////////////////////// PRIVATE FUNCTIONS
+
+// the only writer of the socket, it ends when the service closes the connection
+func writeLiveMessages(socket *websocket.Conn, connection *quizzes.LiveConnectionModel, replies chan quizzes.LiveMessageModel, done chan struct{}){
+	defer close(done)
+	ping := time.NewTicker(LIVE_PING_INTERVAL)
+	defer ping.Stop()
+	for{
+		select{
+		case message, ok := <- connection.Messages:
+			socket.SetWriteDeadline(time.Now().Add(LIVE_WRITE_TIMEOUT))
+			if !ok{
+				socket.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				socket.Close()
+				return
+			}
+			if err := socket.WriteJSON(message); err != nil{
+				socket.Close()
+			}
+		case message := <- replies:
+			socket.SetWriteDeadline(time.Now().Add(LIVE_WRITE_TIMEOUT))
+			if err := socket.WriteJSON(message); err != nil{
+				socket.Close()
+			}
+		case <- ping.C:
+			socket.SetWriteDeadline(time.Now().Add(LIVE_WRITE_TIMEOUT))
+			if err := socket.WriteMessage(websocket.PingMessage, nil); err != nil{
+				socket.Close()
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/diegobermudez03/couples-backend/internal/http/middlewares"
 	"github.com/diegobermudez03/couples-backend/internal/utils"
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
 	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -16,21 +18,29 @@ import (
 const CAT_ID_URL_PARAM = "catId"
 const QUIZ_ID_URL_PARAM = "quizId"
 const QUESTION_ID_URL_PARAM = "questionId"
+const PLAY_ID_URL_PARAM = "playId"
+const INVITATION_ID_URL_PARAM = "invitationId"
+const STRATEGIC_ID_URL_PARAM = "strategicId"
 
 const ORDER_BY_FILTER = "orderBy"
 const CATEGORY_FILTER = "categoryId"
 const TEXT_FILTER = "text"
+const STATUS_FILTER = "status"
+const MODE_FILTER = "mode"
+const ACTIVE_FILTER = "active"
 
 type QuizzesHandler struct {
 	service     quizzes.UserService
 	middlewares *middlewares.Middlewares
 	adminService quizzes.AdminService
+	liveService quizzes.LiveService
 }
 
-func NewQuizzesHandler(adminService quizzes.AdminService, service quizzes.UserService, middlewares *middlewares.Middlewares) *QuizzesHandler {
+func NewQuizzesHandler(adminService quizzes.AdminService, service quizzes.UserService, liveService quizzes.LiveService, middlewares *middlewares.Middlewares) *QuizzesHandler {
 	return &QuizzesHandler{
 		adminService: adminService,
 		service:     service,
+		liveService: liveService,
 		middlewares: middlewares,
 	}
 }
@@ -39,7 +49,7 @@ func (h *QuizzesHandler) RegisterRoutes(r *chi.Mux) {
 	routerUsers := chi.NewMux()
 	routerUsers.Use(h.middlewares.CheckAccessToken)
 	routerAdmin := chi.NewMux()
-	//routerAdmin.Use(h.middlewares.CheckAdminAccessToken)
+	routerAdmin.Use(h.middlewares.CheckAdminAccessToken)
 
 	r.Mount("/quizzes", routerUsers)
 
@@ -57,25 +67,46 @@ func (h *QuizzesHandler) RegisterRoutes(r *chi.Mux) {
 	routerUsers.With(h.middlewares.CheckUserQuizPermissions).Delete(fmt.Sprintf("/questions/{%s}", QUESTION_ID_URL_PARAM), h.deleteQuestion)
 	// categories handlers
 	routerUsers.Get("/categories", h.getCategories)
+	//	play handlers
+	routerUsers.Post(fmt.Sprintf("/quizes/{%s}/plays", QUIZ_ID_URL_PARAM), h.postQuizPlay)
+	routerUsers.Get(fmt.Sprintf("/plays/{%s}/questions", PLAY_ID_URL_PARAM), h.getPlayQuestions)
+	routerUsers.Post(fmt.Sprintf("/plays/{%s}/questions/{%s}/answers", PLAY_ID_URL_PARAM, QUESTION_ID_URL_PARAM), h.postQuestionAnswer)
+	routerUsers.Patch(fmt.Sprintf("/plays/{%s}/complete", PLAY_ID_URL_PARAM), h.patchCompletePlay)
+	routerUsers.Get(fmt.Sprintf("/quizes/{%s}/comparison", QUIZ_ID_URL_PARAM), h.getQuizComparison)
+	routerUsers.Get(fmt.Sprintf("/quizes/{%s}/knowledge", QUIZ_ID_URL_PARAM), h.getQuizKnowledge)
+	routerUsers.Get("/knowledge", h.getCoupleKnowledge)
+	routerUsers.Get("/profile", h.getStrategicProfile)
+	routerUsers.Get("/compatibility", h.getCoupleCompatibility)
+	routerUsers.Get(fmt.Sprintf("/quizes/{%s}/live", QUIZ_ID_URL_PARAM), h.getLiveSession)
+	//	invitations handlers
+	routerUsers.Post(fmt.Sprintf("/quizes/{%s}/invitations", QUIZ_ID_URL_PARAM), h.postQuizInvitation)
+	routerUsers.Get("/invitations", h.getQuizInvitations)
+	routerUsers.Post(fmt.Sprintf("/invitations/{%s}/accept", INVITATION_ID_URL_PARAM), h.postAcceptInvitation)
 
 	///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 	r.Mount("/admin/quizzes", routerAdmin)
 
 	//	categories handlers
-	routerAdmin.Post("/categories", h.postAdminQuizCategory)
-	routerAdmin.Patch(fmt.Sprintf("/categories/{%s}", CAT_ID_URL_PARAM), h.patchAdminQuizCategory)
-	routerAdmin.Delete(fmt.Sprintf("/categories/{%s}", CAT_ID_URL_PARAM), h.deleteCategory)
-	routerAdmin.Get("/categories", h.getCategories)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_CATEGORIES_WRITE)).Post("/categories", h.postAdminQuizCategory)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_CATEGORIES_WRITE)).Patch(fmt.Sprintf("/categories/{%s}", CAT_ID_URL_PARAM), h.patchAdminQuizCategory)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_CATEGORIES_DELETE)).Delete(fmt.Sprintf("/categories/{%s}", CAT_ID_URL_PARAM), h.deleteCategory)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_CONTENT_READ)).Get("/categories", h.getCategories)
 	//	quiz handlers
-	routerAdmin.Post(fmt.Sprintf("/categories/{%s}/quizes", CAT_ID_URL_PARAM), h.postQuiz)
-	routerAdmin.Patch(fmt.Sprintf("/quizes/{%s}", QUIZ_ID_URL_PARAM), h.patchQuizHandler)
-	routerAdmin.Delete(fmt.Sprintf("/quizes/{%s}", QUIZ_ID_URL_PARAM), h.deleteQuiz)
-	routerAdmin.Get("/quizes", h.getQuizes)
-	routerAdmin.Patch(fmt.Sprintf("/quizes/{%s}/publish", QUIZ_ID_URL_PARAM), h.patchPublishQuiz)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_WRITE)).Post(fmt.Sprintf("/categories/{%s}/quizes", CAT_ID_URL_PARAM), h.postQuiz)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_WRITE)).Patch(fmt.Sprintf("/quizes/{%s}", QUIZ_ID_URL_PARAM), h.patchQuizHandler)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_MODERATE)).Delete(fmt.Sprintf("/quizes/{%s}", QUIZ_ID_URL_PARAM), h.deleteQuiz)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_CONTENT_READ)).Get("/quizes", h.getQuizes)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_MODERATE)).Patch(fmt.Sprintf("/quizes/{%s}/publish", QUIZ_ID_URL_PARAM), h.patchPublishQuiz)
 	//question handlers
-	routerAdmin.Post(fmt.Sprintf("/quizes/{%s}/questions", QUIZ_ID_URL_PARAM), h.postQuestionHandler)
-	routerAdmin.Patch(fmt.Sprintf("/questions/{%s}", QUESTION_ID_URL_PARAM), h.patchQuestion)
-	routerAdmin.Delete(fmt.Sprintf("/questions/{%s}", QUESTION_ID_URL_PARAM), h.deleteQuestion)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_WRITE)).Post(fmt.Sprintf("/quizes/{%s}/questions", QUIZ_ID_URL_PARAM), h.postQuestionHandler)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_WRITE)).Patch(fmt.Sprintf("/questions/{%s}", QUESTION_ID_URL_PARAM), h.patchQuestion)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_WRITE)).Delete(fmt.Sprintf("/questions/{%s}", QUESTION_ID_URL_PARAM), h.deleteQuestion)
+	//strategic types handlers
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_CONTENT_READ)).Get("/strategic-types", h.getStrategicTypes)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_WRITE)).Post("/strategic-types", h.postStrategicType)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_WRITE)).Patch(fmt.Sprintf("/strategic-types/{%s}", STRATEGIC_ID_URL_PARAM), h.patchStrategicType)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_MODERATE)).Post(fmt.Sprintf("/strategic-types/{%s}/merge", STRATEGIC_ID_URL_PARAM), h.postMergeStrategicType)
+	routerAdmin.With(h.middlewares.RequireAdminPermission(auth.PERMISSION_QUIZZES_MODERATE)).Delete(fmt.Sprintf("/strategic-types/{%s}", STRATEGIC_ID_URL_PARAM), h.deleteStrategicType)
 }
 
 
@@ -127,6 +158,34 @@ type patchQuizDTO struct{
 	CategoryId 	*uuid.UUID	`json:"categoryId"`
 }
 
+type postAnswerDTO struct{
+	OwnAnswer 		json.RawMessage	`json:"ownAnswer" validate:"required"`
+	GuessedPartner 	json.RawMessage	`json:"guessedPartner"`
+}
+
+type strategicTranslationDTO struct{
+	LanguageCode 	string 	`json:"languageCode" validate:"required"`
+	Name 			string 	`json:"name" validate:"required"`
+	Description 	string 	`json:"description" validate:"required"`
+}
+
+type postStrategicTypeDTO struct{
+	Name 			string 	`json:"name" validate:"required"`
+	Description 	string 	`json:"description" validate:"required"`
+	Translations 	[]strategicTranslationDTO 	`json:"translations" validate:"dive"`
+}
+
+type patchStrategicTypeDTO struct{
+	Name 			*string 	`json:"name"`
+	Description 	*string 	`json:"description"`
+	Active 			*bool 		`json:"active"`
+	Translations 	[]strategicTranslationDTO 	`json:"translations" validate:"dive"`
+}
+
+type postMergeStrategicTypeDTO struct{
+	TargetId 	uuid.UUID 	`json:"targetId" validate:"required"`
+}
+
 /////////////////////////////////// ERRORS CODES
 
 var quizzessErrorCodes = map[error] int{
@@ -156,6 +215,50 @@ var quizzessErrorCodes = map[error] int{
 	quizzes.ErrRetrievingQuizzes : http.StatusInternalServerError,
 	quizzes.ErrUnableToPublish : http.StatusInternalServerError,
 	quizzes.ErrQuizAlreadyPublished : http.StatusNotModified,
+	quizzes.ErrStartingQuiz : http.StatusInternalServerError,
+	quizzes.ErrQuizNotPublished : http.StatusBadRequest,
+	quizzes.ErrQuizAlreadyPlayed : http.StatusConflict,
+	quizzes.ErrQuizPlayNotFound : http.StatusNotFound,
+	quizzes.ErrQuizPlayAlreadyCompleted : http.StatusConflict,
+	quizzes.ErrRetrievingQuestions : http.StatusInternalServerError,
+	quizzes.ErrQuestionNotInQuiz : http.StatusBadRequest,
+	quizzes.ErrInvalidAnswer : http.StatusBadRequest,
+	quizzes.ErrAnsweringQuestion : http.StatusInternalServerError,
+	quizzes.ErrMissingAnswers : http.StatusBadRequest,
+	quizzes.ErrCompletingQuiz : http.StatusInternalServerError,
+	quizzes.ErrQuizNotCompleted : http.StatusForbidden,
+	quizzes.ErrPartnerHasntCompleted : http.StatusForbidden,
+	quizzes.ErrComparingAnswers : http.StatusInternalServerError,
+	quizzes.ErrUserWithoutCouple : http.StatusBadRequest,
+	quizzes.ErrInvalidPlaceholder : http.StatusBadRequest,
+	quizzes.ErrInvalidAnswerKey : http.StatusBadRequest,
+	quizzes.ErrInvalidPlayMode : http.StatusBadRequest,
+	quizzes.ErrMissingGuess : http.StatusBadRequest,
+	quizzes.ErrScoringGuesses : http.StatusInternalServerError,
+	quizzes.ErrRetrievingKnowledge : http.StatusInternalServerError,
+	quizzes.ErrRetrievingProfile : http.StatusInternalServerError,
+	quizzes.ErrMissingStrategicAttributes : http.StatusBadRequest,
+	quizzes.ErrStrategicTypeAlreadyExists : http.StatusConflict,
+	quizzes.ErrStrategicTypeNotFound : http.StatusNotFound,
+	quizzes.ErrStrategicTypeRetired : http.StatusBadRequest,
+	quizzes.ErrStrategicTypeNotInCatalog : http.StatusBadRequest,
+	quizzes.ErrMergingStrategicTypeIntoItself : http.StatusBadRequest,
+	quizzes.ErrCreatingStrategicType : http.StatusInternalServerError,
+	quizzes.ErrUpdatingStrategicType : http.StatusInternalServerError,
+	quizzes.ErrMergingStrategicTypes : http.StatusInternalServerError,
+	quizzes.ErrRetrievingStrategicTypes : http.StatusInternalServerError,
+	quizzes.ErrJoiningLiveSession : http.StatusInternalServerError,
+	quizzes.ErrLiveSessionNotStarted : http.StatusConflict,
+	quizzes.ErrQuestionNotCurrent : http.StatusConflict,
+	quizzes.ErrInvalidLiveMessage : http.StatusBadRequest,
+	quizzes.ErrInvitingPartner : http.StatusInternalServerError,
+	quizzes.ErrPartnerAlreadyPlayed : http.StatusConflict,
+	quizzes.ErrQuizInvitationAlreadyExists : http.StatusConflict,
+	quizzes.ErrQuizInvitationNotFound : http.StatusNotFound,
+	quizzes.ErrQuizInvitationExpired : http.StatusGone,
+	quizzes.ErrQuizInvitationAlreadyAccepted : http.StatusConflict,
+	quizzes.ErrRetrievingInvitations : http.StatusInternalServerError,
+	quizzes.ErrInvalidInvitationStatus : http.StatusBadRequest,
 }
 
 
@@ -503,6 +606,296 @@ func (h *QuizzesHandler) patchPublishQuiz(w http.ResponseWriter, r *http.Request
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
 
+func (h *QuizzesHandler) postQuizPlay(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	quizId, err := uuid.Parse(chi.URLParam(r, QUIZ_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrEmptyQuizId)
+		return
+	}
+	mode := quizzes.PLAY_MODE_NORMAL
+	if value := r.URL.Query().Get(MODE_FILTER); value != ""{
+		mode = value
+	}
+	playId, err := h.service.StartQuiz(r.Context(), quizId, userId, mode)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"playId" : playId,
+	})
+}
+
+func (h *QuizzesHandler) getPlayQuestions(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	playId, err := uuid.Parse(chi.URLParam(r, PLAY_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	questions, err := h.service.GetPlayQuestions(r.Context(), playId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, questions)
+}
+
+func (h *QuizzesHandler) postQuestionAnswer(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	playId, err := uuid.Parse(chi.URLParam(r, PLAY_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	questionId, err := uuid.Parse(chi.URLParam(r, QUESTION_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrEmptyQuestionId)
+		return
+	}
+	var payload postAnswerDTO
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return 
+	}
+	err = h.service.AnswerQuestion(r.Context(), playId, questionId, userId, quizzes.AnswerRequest{
+		OwnAnswer: payload.OwnAnswer,
+		GuessedPartner: payload.GuessedPartner,
+	})
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, nil)
+}
+
+func (h *QuizzesHandler) patchCompletePlay(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	playId, err := uuid.Parse(chi.URLParam(r, PLAY_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	play, err := h.service.CompleteQuiz(r.Context(), playId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, play)
+}
+
+func (h *QuizzesHandler) getQuizComparison(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	quizId, err := uuid.Parse(chi.URLParam(r, QUIZ_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrEmptyQuizId)
+		return
+	}
+	comparison, err := h.service.GetQuizComparison(r.Context(), quizId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, comparison)
+}
+
+func (h *QuizzesHandler) getQuizKnowledge(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	quizId, err := uuid.Parse(chi.URLParam(r, QUIZ_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrEmptyQuizId)
+		return
+	}
+	knowledge, err := h.service.GetQuizKnowledge(r.Context(), quizId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, knowledge)
+}
+
+func (h *QuizzesHandler) getCoupleKnowledge(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	knowledge, err := h.service.GetCoupleKnowledge(r.Context(), userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, knowledge)
+}
+
+func (h *QuizzesHandler) getStrategicProfile(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	profile, err := h.service.GetStrategicProfile(r.Context(), userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, profile)
+}
+
+func (h *QuizzesHandler) getCoupleCompatibility(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	compatibility, err := h.service.GetCoupleCompatibility(r.Context(), userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, compatibility)
+}
+
+func (h *QuizzesHandler) postQuizInvitation(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	quizId, err := uuid.Parse(chi.URLParam(r, QUIZ_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrEmptyQuizId)
+		return
+	}
+	invitation, err := h.service.InvitePartnerToQuiz(r.Context(), quizId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, invitation)
+}
+
+func (h *QuizzesHandler) getQuizInvitations(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	var status *string
+	if value := r.URL.Query().Get(STATUS_FILTER); value != ""{
+		status = &value
+	}
+	invitations, err := h.service.GetQuizInvitations(r.Context(), userId, status)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, invitations)
+}
+
+func (h *QuizzesHandler) postAcceptInvitation(w http.ResponseWriter, r *http.Request){
+	userId := r.Context().Value(middlewares.UserIdKey{}).(uuid.UUID)
+	invitationId, err := uuid.Parse(chi.URLParam(r, INVITATION_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	playId, err := h.service.AcceptQuizInvitation(r.Context(), invitationId, userId)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"playId" : playId,
+	})
+}
+
+func (h *QuizzesHandler) getStrategicTypes(w http.ResponseWriter, r *http.Request){
+	var active *bool
+	if parsed, err := strconv.ParseBool(r.URL.Query().Get(ACTIVE_FILTER)); err == nil{
+		active = &parsed
+	}
+	types, err := h.adminService.GetStrategicTypes(r.Context(), active)
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, types)
+}
+
+func (h *QuizzesHandler) postStrategicType(w http.ResponseWriter, r *http.Request){
+	var payload postStrategicTypeDTO
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	id, err := h.adminService.CreateStrategicType(r.Context(), quizzes.StrategicTypeRequest{
+		Name: &payload.Name,
+		Description: &payload.Description,
+		Translations: strategicTranslationsFromDTO(payload.Translations),
+	})
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]any{
+		"strategicAnswerId" : id,
+	})
+}
+
+func (h *QuizzesHandler) patchStrategicType(w http.ResponseWriter, r *http.Request){
+	id, err := uuid.Parse(chi.URLParam(r, STRATEGIC_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	var payload patchStrategicTypeDTO
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	err = h.adminService.UpdateStrategicType(r.Context(), id, quizzes.StrategicTypeRequest{
+		Name: payload.Name,
+		Description: payload.Description,
+		Active: payload.Active,
+		Translations: strategicTranslationsFromDTO(payload.Translations),
+	})
+	if err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *QuizzesHandler) postMergeStrategicType(w http.ResponseWriter, r *http.Request){
+	sourceId, err := uuid.Parse(chi.URLParam(r, STRATEGIC_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	var payload postMergeStrategicTypeDTO
+	if err := utils.ReadJSON(r, &payload); err != nil{
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.adminService.MergeStrategicTypes(r.Context(), sourceId, payload.TargetId); err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
+func (h *QuizzesHandler) deleteStrategicType(w http.ResponseWriter, r *http.Request){
+	id, err := uuid.Parse(chi.URLParam(r, STRATEGIC_ID_URL_PARAM))
+	if err != nil{
+		utils.WriteError(w, http.StatusBadRequest, utils.ErrInvalidId)
+		return
+	}
+	if err := h.adminService.RetireStrategicType(r.Context(), id); err != nil{
+		code := utils.GetErrorCode(err, quizzessErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, nil)
+}
+
 //////////////////////////////////////////////////////////////////////////
 /////////////////////////////////////////////////////////////////////////
 func (h *QuizzesHandler) getUserId(r *http.Request) *uuid.UUID{
@@ -515,6 +908,19 @@ func (h *QuizzesHandler) getUserId(r *http.Request) *uuid.UUID{
 }
 
 
+func strategicTranslationsFromDTO(translations []strategicTranslationDTO) []quizzes.StrategicTranslationModel{
+	models := make([]quizzes.StrategicTranslationModel, len(translations))
+	for i, translation := range translations{
+		models[i] = quizzes.StrategicTranslationModel{
+			LanguageCode: translation.LanguageCode,
+			Name: translation.Name,
+			Description: translation.Description,
+		}
+	}
+	return models
+}
+
+
 func getIntPointer(text string) (*int){
 	if text != ""{
 		num, err := strconv.Atoi(text)
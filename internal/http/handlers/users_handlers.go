@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/diegobermudez03/couples-backend/internal/http/middlewares"
 	"github.com/diegobermudez03/couples-backend/internal/utils"
@@ -10,15 +11,19 @@ import (
 	"github.com/google/uuid"
 )
 
+const DAYS_FILTER = "days"
+
 type UsersHandler struct {
 	service users.UsersService
+	pointsService users.PointsService
 	middlewares *middlewares.Middlewares
 }
 
 
-func NewUsersHandler(service users.UsersService, middlewares *middlewares.Middlewares) *UsersHandler{
+func NewUsersHandler(service users.UsersService, pointsService users.PointsService, middlewares *middlewares.Middlewares) *UsersHandler{
 	return &UsersHandler{
 		service: service,
+		pointsService: pointsService,
 		middlewares: middlewares,
 	}
 }
@@ -30,6 +35,8 @@ func (h *UsersHandler) RegisterRoutes(r *chi.Mux){
 	r.Mount("/users", router)
 	
 	router.Patch("/partners/nickname", h.PatchPartnersNickNameEndpoint)
+	router.Get("/couples/points", h.GetCouplePointsEndpoint)
+	router.Get("/couples/points/history", h.GetCouplePointsHistoryEndpoint)
 }
 
 
@@ -65,6 +72,8 @@ var usersErrorCodes = map[error] int{
 	users.ErrorUnableToGetTempCouple : http.StatusInternalServerError,
 	users.ErrorNoTempCoupleFound : http.StatusInternalServerError,
 	users.ErrorCantGetCouple: http.StatusInternalServerError,
+	users.ErrorInvalidPointsReason : http.StatusInternalServerError,
+	users.ErrorGettingPoints : http.StatusInternalServerError,
 }
 
 
@@ -87,3 +96,32 @@ func (h *UsersHandler) PatchPartnersNickNameEndpoint(w http.ResponseWriter, r *h
 
 	utils.WriteJSON(w, http.StatusOK, nil)
 }
+
+
+func (h *UsersHandler) GetCouplePointsEndpoint(w http.ResponseWriter, r *http.Request){
+	coupleId := r.Context().Value(middlewares.CoupleIdKey{}).(uuid.UUID)
+
+	points, err := h.pointsService.GetCouplePoints(r.Context(), coupleId)
+	if err != nil{
+		code := utils.GetErrorCode(err, usersErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"points" : points,
+	})
+}
+
+
+func (h *UsersHandler) GetCouplePointsHistoryEndpoint(w http.ResponseWriter, r *http.Request){
+	coupleId := r.Context().Value(middlewares.CoupleIdKey{}).(uuid.UUID)
+	days, _ := strconv.Atoi(r.URL.Query().Get(DAYS_FILTER))
+
+	history, err := h.pointsService.GetCouplePointsHistory(r.Context(), coupleId, days)
+	if err != nil{
+		code := utils.GetErrorCode(err, usersErrorCodes, 500)
+		utils.WriteError(w, code, err)
+		return 
+	}
+	utils.WriteJSON(w, http.StatusOK, history)
+}
\ No newline at end of file
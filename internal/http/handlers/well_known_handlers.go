@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/diegobermudez03/couples-backend/internal/utils"
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
+	"github.com/go-chi/chi/v5"
+)
+
+type WellKnownHandler struct{
+	signer 	auth.TokenSigner
+}
+
+func NewWellKnownHandler(signer auth.TokenSigner) *WellKnownHandler{
+	return &WellKnownHandler{
+		signer: signer,
+	}
+}
+
+func (h *WellKnownHandler) RegisterRoutes(r *chi.Mux){
+	router := chi.NewMux()
+	r.Mount("/.well-known", router)
+
+	router.Get("/jwks.json", h.getJwksEndpoint)
+}
+
+/////////////////////////////////////////////////////////////////////////////////// HANDLERS
+
+// public keys so other services can verify our access tokens
+func (h *WellKnownHandler) getJwksEndpoint(w http.ResponseWriter, r *http.Request){
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.WriteJSON(w, http.StatusOK, h.signer.GetPublicKeys())
+}
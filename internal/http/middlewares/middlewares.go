@@ -5,11 +5,13 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/diegobermudez03/couples-backend/internal/utils"
 	"github.com/diegobermudez03/couples-backend/pkg/auth"
 	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -26,6 +28,8 @@ const QUESTION_ID_URL_PARAM = "questionId"
 type UserIdKey struct{}
 type CoupleIdKey struct{}
 type SessionIdKey struct{}
+type AdminIdKey struct{}
+type AdminPermissionsKey struct{}
 
 
 
@@ -33,13 +37,15 @@ type Middlewares struct {
 	authService auth.AuthService
 	adminService auth.AuthAdminService
 	quizzService quizzes.UserService
+	limiter 	ratelimit.Service
 }
 
-func NewMiddlewares(authService auth.AuthService, adminService auth.AuthAdminService, quizzService quizzes.UserService) *Middlewares{
+func NewMiddlewares(authService auth.AuthService, adminService auth.AuthAdminService, quizzService quizzes.UserService, limiter ratelimit.Service) *Middlewares{
 	return &Middlewares{
 		authService: authService,
 		adminService: adminService,
 		quizzService: quizzService,
+		limiter: limiter,
 	}
 }
 
@@ -90,6 +96,8 @@ func (m *Middlewares) CheckAdminAccessToken(handler http.Handler) http.Handler{
 			}
 			
 			ctx := context.WithValue(r.Context(), SessionIdKey{}, claims.SessionId)
+			ctx = context.WithValue(ctx, AdminIdKey{}, claims.AdminId)
+			ctx = context.WithValue(ctx, AdminPermissionsKey{}, claims.Permissions)
 			r = r.WithContext(ctx)
 			log.Printf("Succesfully validated %s", claims.SessionId)
 			handler.ServeHTTP(w, r)
@@ -97,6 +105,22 @@ func (m *Middlewares) CheckAdminAccessToken(handler http.Handler) http.Handler{
 	)
 }
 
+// must be used after CheckAdminAccessToken
+func (m *Middlewares) RequireAdminPermission(permission string) func(http.Handler) http.Handler{
+	return func(handler http.Handler) http.Handler{
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request){
+				permissions, ok := r.Context().Value(AdminPermissionsKey{}).([]string)
+				if !ok || !slices.Contains(permissions, permission){
+					utils.WriteError(w, http.StatusForbidden, ErrUnathorized)
+					return
+				}
+				handler.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
 
 func (m *Middlewares) CheckUserQuizPermissions(handler http.Handler) http.Handler{
 	return http.HandlerFunc(
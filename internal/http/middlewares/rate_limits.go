@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/diegobermudez03/couples-backend/internal/utils"
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
+)
+
+// limits the requests of each key (the client IP by default) with the given policy,
+// if the limits can't be checked the request is allowed
+func (m *Middlewares) RateLimit(policy ratelimit.PolicyModel, keyFunc func(r *http.Request) string) func(http.Handler) http.Handler{
+	if keyFunc == nil{
+		keyFunc = ClientIp
+	}
+	return func(handler http.Handler) http.Handler{
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request){
+				retryAfter, err := m.limiter.Allow(r.Context(), policy, keyFunc(r))
+				if errors.Is(err, ratelimit.ErrTooManyAttempts){
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+					utils.WriteError(w, http.StatusTooManyRequests, err)
+					return
+				}else if err != nil{
+					log.Print("unable to check rate limit: ", err.Error())
+				}
+				handler.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// the RealIP middleware already replaced the remote address with the forwarded one
+func ClientIp(r *http.Request) string{
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil{
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,32 +3,41 @@ package appauth
 import (
 	"context"
 	"errors"
+	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/diegobermudez03/couples-backend/pkg/auth"
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type AdminAuthServiceImpl struct {
 	repo auth.AuthRepository
-	jwtSecret 	string
+	signer 		auth.TokenSigner
 	accessTokenLife  int64
+	sessionLife 	int64
+	totpIssuer 		string
+	limiter 		ratelimit.Service
 }
 
-func NewAdminAuthService(repo auth.AuthRepository, jwtSecret string, accessTokenLife  int64) auth.AuthAdminService{
+func NewAdminAuthService(repo auth.AuthRepository, signer auth.TokenSigner, accessTokenLife  int64, sessionLife int64, totpIssuer string, limiter ratelimit.Service) auth.AuthAdminService{
 	return &AdminAuthServiceImpl{
 		repo: repo,
-		jwtSecret: jwtSecret,
+		signer: signer,
 		accessTokenLife: accessTokenLife,
+		sessionLife: sessionLife,
+		totpIssuer: totpIssuer,
+		limiter: limiter,
 	}	
 }
 
 
 func (s *AdminAuthServiceImpl) ValidateAccessToken(ctx context.Context, accessTokenString string) (*auth.AdminAccessClaims, error){
-	accessToken, err := jwt.ParseWithClaims(accessTokenString, &auth.AdminAccessClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
-	})
+	accessToken, err := s.signer.Parse(accessTokenString, &auth.AdminAccessClaims{})
 	if err != nil{
 		if errors.Is(err, jwt.ErrTokenExpired){
 			return nil, auth.ErrorExpiredAccessToken
@@ -39,30 +48,242 @@ func (s *AdminAuthServiceImpl) ValidateAccessToken(ctx context.Context, accessTo
 		return nil, auth.ErrorExpiredAccessToken
 	}
 	claims := accessToken.Claims.(*auth.AdminAccessClaims)
+
+	//the session could have been closed
+	session, err := s.repo.GetAdminSessionById(ctx, claims.SessionId)
+	if err != nil{
+		return nil, auth.ErrorGettingSessions
+	}else if session == nil{
+		return nil, auth.ErrorSessionRevoked
+	}
 	return claims, nil
 }
 
 
 func (s *AdminAuthServiceImpl) CreateAccessToken(ctx context.Context, token string)(string, error){
-	session, err := s.repo.GetAdminSessionByToken(ctx, token)
+	session, err := s.repo.GetAdminSessionByToken(ctx, hashToken(token))
 	if err != nil || session == nil{
 		return "", auth.ErrorNonExistingSession 
 	}
-	accessToken, err := s.createAccessToken(session.Id)
+	if session.ExpiresAt.Before(time.Now()){
+		return "", auth.ErrorExpiredRefreshToken
+	}
+	//the role is read again so the changes apply on the next access token
+	admin, err := s.repo.GetAdminById(ctx, session.AdminId)
+	if err != nil || admin == nil{
+		return "", auth.ErrorCreatingAccessToken
+	}
+	accessToken, err := s.createAccessToken(session.Id, admin)
 	return accessToken, err
 }
 
-func (s *AdminAuthServiceImpl) createAccessToken(sessionId uuid.UUID)(string, error){
+
+// the TOTP code is only required if the admin enabled it, wrong TOTP codes also count as failed attempts
+func (s *AdminAuthServiceImpl) LoginAdmin(ctx context.Context, email, password, totpCode string) (string, error){
+	email = strings.ToLower(email)
+	if err := checkAttempt(ctx, s.limiter, auth.ADMIN_LOGIN_ACCOUNT_POLICY, email); err != nil{
+		return "", err
+	}
+	admin, err := s.repo.GetAdminByEmail(ctx, email)
+	if err != nil{
+		return "", auth.ErrorAdminLogin
+	}else if admin == nil{
+		return "", auth.ErrInvalidAdminCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(admin.Hash), []byte(password)); err != nil{
+		return "", auth.ErrInvalidAdminCredentials
+	}
+	if admin.TotpEnabled{
+		if totpCode == ""{
+			return "", auth.ErrTotpCodeRequired
+		}
+		if admin.TotpSecret == nil || !validateTotpCode(*admin.TotpSecret, totpCode, time.Now()){
+			return "", auth.ErrInvalidTotpCode
+		}
+	}
+	s.limiter.Reset(ctx, auth.ADMIN_LOGIN_ACCOUNT_POLICY, email)
+
+	token, err := generateRandomToken()
+	if err != nil{
+		return "", auth.ErrorCreatingSession
+	}
+	session := auth.AdminSessionModel{
+		Id: uuid.New(),
+		Token: hashToken(token),
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Duration(s.sessionLife*int64(time.Second))),
+		AdminId: admin.Id,
+	}
+	if num, err := s.repo.CreateAdminSession(ctx, &session); err != nil || num == 0{
+		return "", auth.ErrorCreatingSession
+	}
+	return token, nil
+}
+
+
+func (s *AdminAuthServiceImpl) LogoutAdmin(ctx context.Context, sessionId uuid.UUID) error{
+	if num, err := s.repo.DeleteAdminSessionById(ctx, sessionId); err != nil{
+		return auth.ErrorWithLogout
+	}else if num == 0{
+		return auth.ErrorNonExistingSession
+	}
+	return nil
+}
+
+
+func (s *AdminAuthServiceImpl) CreateAdmin(ctx context.Context, email, password, role string, permissions []string) (*uuid.UUID, error){
+	if err := validatePassword(password); err != nil{
+		return nil, err
+	}
+	if err := validateAdminRole(role, permissions); err != nil{
+		return nil, err
+	}
+	if permissions == nil{
+		permissions = []string{}
+	}
+	email = strings.ToLower(email)
+	if admin, err := s.repo.GetAdminByEmail(ctx, email); err != nil{
+		return nil, auth.ErrorCreatingAdmin
+	}else if admin != nil{
+		return nil, auth.ErrAdminEmailAlreadyUsed
+	}
+	hashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil{
+		return nil, auth.ErrorCreatingAdmin
+	}
+	admin := auth.AdminModel{
+		Id: uuid.New(),
+		Email: email,
+		Hash: string(hashBytes),
+		TotpEnabled: false,
+		CreatedAt: time.Now(),
+		Role: role,
+		Permissions: permissions,
+	}
+	if num, err := s.repo.CreateAdmin(ctx, &admin); err != nil || num == 0{
+		return nil, auth.ErrorCreatingAdmin
+	}
+	return &admin.Id, nil
+}
+
+
+// creates the first admin from the configuration, if it already exists nothing is done
+func (s *AdminAuthServiceImpl) BootstrapAdmin(ctx context.Context, email, password string) error{
+	_, err := s.CreateAdmin(ctx, email, password, auth.ROLE_SUPER_ADMIN, nil)
+	if errors.Is(err, auth.ErrAdminEmailAlreadyUsed){
+		return nil
+	}else if err != nil{
+		return err
+	}
+	log.Printf("Created bootstrap admin %s", email)
+	return nil
+}
+
+
+// admins can't change their own role, so there's always someone able to manage the admins
+func (s *AdminAuthServiceImpl) UpdateAdminRole(ctx context.Context, currentAdminId uuid.UUID, adminId uuid.UUID, role string, permissions []string) error{
+	if currentAdminId == adminId{
+		return auth.ErrCantChangeOwnRole
+	}
+	if err := validateAdminRole(role, permissions); err != nil{
+		return err
+	}
+	if permissions == nil{
+		permissions = []string{}
+	}
+	if num, err := s.repo.UpdateAdminRole(ctx, adminId, role, permissions); err != nil{
+		return auth.ErrorUpdatingAdminRole
+	}else if num == 0{
+		return auth.ErrAdminNotFound
+	}
+	return nil
+}
+
+
+// generates a new secret, it's only enabled after a code generated with it is confirmed
+func (s *AdminAuthServiceImpl) SetupTotp(ctx context.Context, adminId uuid.UUID) (string, string, error){
+	admin, err := s.repo.GetAdminById(ctx, adminId)
+	if err != nil{
+		return "", "", auth.ErrorSettingUpTotp
+	}else if admin == nil{
+		return "", "", auth.ErrAdminNotFound
+	}
+	if admin.TotpEnabled{
+		return "", "", auth.ErrTotpAlreadyEnabled
+	}
+	secret, err := generateTotpSecret()
+	if err != nil{
+		return "", "", auth.ErrorSettingUpTotp
+	}
+	if num, err := s.repo.UpdateAdminTotp(ctx, adminId, &secret, false); err != nil || num == 0{
+		return "", "", auth.ErrorSettingUpTotp
+	}
+	return secret, getTotpUrl(s.totpIssuer, admin.Email, secret), nil
+}
+
+
+func (s *AdminAuthServiceImpl) EnableTotp(ctx context.Context, adminId uuid.UUID, code string) error{
+	admin, err := s.repo.GetAdminById(ctx, adminId)
+	if err != nil{
+		return auth.ErrorSettingUpTotp
+	}else if admin == nil{
+		return auth.ErrAdminNotFound
+	}
+	if admin.TotpEnabled{
+		return auth.ErrTotpAlreadyEnabled
+	}
+	if admin.TotpSecret == nil{
+		return auth.ErrTotpNotSetUp
+	}
+	if !validateTotpCode(*admin.TotpSecret, code, time.Now()){
+		return auth.ErrInvalidTotpCode
+	}
+	if num, err := s.repo.UpdateAdminTotp(ctx, adminId, admin.TotpSecret, true); err != nil || num == 0{
+		return auth.ErrorSettingUpTotp
+	}
+	return nil
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////
+//								private functions
+
+func (s *AdminAuthServiceImpl) createAccessToken(sessionId uuid.UUID, admin *auth.AdminModel)(string, error){
 	claims := auth.AdminAccessClaims{
 		SessionId: sessionId,
+		AdminId: admin.Id,
+		Role: admin.Role,
+		Permissions: getAdminPermissions(admin),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(s.accessTokenLife*int64(time.Second)))),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	tokenString, err := s.signer.Sign(claims)
 	if err != nil{
 		return "", auth.ErrorCreatingAccessToken
 	}
 	return tokenString, nil
 }
+
+func validateAdminRole(role string, permissions []string) error{
+	if _, ok := auth.RolesPermissions[role]; !ok{
+		return auth.ErrInvalidAdminRole
+	}
+	allPermissions := auth.RolesPermissions[auth.ROLE_SUPER_ADMIN]
+	for _, permission := range permissions{
+		if !slices.Contains(allPermissions, permission){
+			return auth.ErrInvalidAdminPermission
+		}
+	}
+	return nil
+}
+
+// the permissions of the role plus the extra ones of the admin
+func getAdminPermissions(admin *auth.AdminModel) []string{
+	permissions := slices.Clone(auth.RolesPermissions[admin.Role])
+	for _, permission := range admin.Permissions{
+		if !slices.Contains(permissions, permission){
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
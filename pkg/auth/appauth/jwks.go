@@ -0,0 +1,183 @@
+package appauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
+)
+
+const JWKS_CACHE_TIME = time.Hour
+// minimum time between reloads caused by unknown kids, so random tokens can't make us hammer the provider
+const JWKS_MIN_RELOAD_TIME = time.Minute
+
+type jwk struct{
+	Kty 	string 	`json:"kty"`
+	Kid 	string 	`json:"kid"`
+	N 		string 	`json:"n"`
+	E 		string 	`json:"e"`
+	Crv 	string 	`json:"crv"`
+	X 		string 	`json:"x"`
+	Y 		string 	`json:"y"`
+}
+
+type jwksDocument struct{
+	Keys 	[]jwk 	`json:"keys"`
+}
+
+// public keys of a JWKS, the source can be an http(s) url or a local file path
+type jwksKeySet struct{
+	source 		string
+	client 		*http.Client
+	keys 		map[string]crypto.PublicKey
+	loadedAt 	time.Time
+	mutex 		sync.RWMutex
+}
+
+func newJwksKeySet(source string) *jwksKeySet{
+	return &jwksKeySet{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys: make(map[string]crypto.PublicKey),
+	}
+}
+
+func (k *jwksKeySet) getKey(ctx context.Context, kid string) (crypto.PublicKey, error){
+	k.mutex.RLock()
+	key, ok := k.keys[kid]
+	sinceLoad := time.Since(k.loadedAt)
+	k.mutex.RUnlock()
+
+	if ok && sinceLoad < JWKS_CACHE_TIME{
+		return key, nil
+	}
+	//unknown kid, the provider may have rotated its keys
+	if !ok && sinceLoad < JWKS_MIN_RELOAD_TIME{
+		return nil, auth.ErrInvalidOAuthToken
+	}
+	if err := k.reload(ctx); err != nil{
+		return nil, err
+	}
+	k.mutex.RLock()
+	key, ok = k.keys[kid]
+	k.mutex.RUnlock()
+	if !ok{
+		return nil, auth.ErrInvalidOAuthToken
+	}
+	return key, nil
+}
+
+func (k *jwksKeySet) reload(ctx context.Context) error{
+	content, err := k.read(ctx)
+	if err != nil{
+		log.Print("error reading jwks: ", err.Error())
+		return auth.ErrorGettingOAuthKeys
+	}
+	var document jwksDocument
+	if err := json.Unmarshal(content, &document); err != nil{
+		log.Print("error parsing jwks: ", err.Error())
+		return auth.ErrorGettingOAuthKeys
+	}
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, key := range document.Keys{
+		publicKey, err := parseJwk(key)
+		if err != nil{
+			//keys we don't support are skipped, not the whole set
+			continue
+		}
+		keys[key.Kid] = publicKey
+	}
+	k.mutex.Lock()
+	k.keys = keys
+	k.loadedAt = time.Now()
+	k.mutex.Unlock()
+	return nil
+}
+
+func (k *jwksKeySet) read(ctx context.Context) ([]byte, error){
+	if !strings.HasPrefix(k.source, "http://") && !strings.HasPrefix(k.source, "https://"){
+		return os.ReadFile(k.source)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil{
+		return nil, err
+	}
+	response, err := k.client.Do(request)
+	if err != nil{
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK{
+		return nil, errors.New("unexpected jwks status " + response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
+
+func parseJwk(key jwk) (crypto.PublicKey, error){
+	switch key.Kty{
+	case "RSA":
+		n, err := decodeJwkNumber(key.N)
+		if err != nil{
+			return nil, err
+		}
+		e, err := decodeJwkNumber(key.E)
+		if err != nil{
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Crv{
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("unsupported curve " + key.Crv)
+		}
+		x, err := decodeJwkNumber(key.X)
+		if err != nil{
+			return nil, err
+		}
+		y, err := decodeJwkNumber(key.Y)
+		if err != nil{
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if key.Crv != "Ed25519"{
+			return nil, errors.New("unsupported curve " + key.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil || len(x) != ed25519.PublicKeySize{
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, errors.New("unsupported key type " + key.Kty)
+}
+
+func decodeJwkNumber(value string) (*big.Int, error){
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil{
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
@@ -0,0 +1,225 @@
+package appauth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const KEY_FILE_EXTENSION = ".pem"
+const GENERATED_KEY_BITS = 2048
+
+var ErrUnsupportedKey = errors.New("unsupported key")
+
+// keys used to sign and verify our JWTs, the kid of each key is its file name.
+// Files with a private key can sign, files with only a public key (retired keys)
+// keep verifying the tokens they signed until they expire
+type Keyring struct{
+	activeKid 		string
+	signingKey 		crypto.Signer
+	publicKeys 		map[string]crypto.PublicKey
+}
+
+// if the directory has no private key a new RSA key is generated in it
+func LoadKeyring(dir string, activeKid string) (*Keyring, error){
+	if err := os.MkdirAll(dir, 0700); err != nil{
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil{
+		return nil, err
+	}
+	privateKeys := make(map[string]crypto.Signer)
+	publicKeys := make(map[string]crypto.PublicKey)
+	for _, entry := range entries{
+		if entry.IsDir() || filepath.Ext(entry.Name()) != KEY_FILE_EXTENSION{
+			continue
+		}
+		kid := strings.TrimSuffix(entry.Name(), KEY_FILE_EXTENSION)
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil{
+			return nil, err
+		}
+		private, public, err := parsePemKey(content)
+		if err != nil{
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		if private != nil{
+			privateKeys[kid] = private
+		}
+		publicKeys[kid] = public
+	}
+
+	if len(privateKeys) == 0{
+		kid, key, err := generateKeyFile(dir)
+		if err != nil{
+			return nil, err
+		}
+		privateKeys[kid] = key
+		publicKeys[kid] = key.Public()
+	}
+
+	//if no key is configured as active, the newest is used
+	if activeKid == ""{
+		kids := make([]string, 0, len(privateKeys))
+		for kid := range privateKeys{
+			kids = append(kids, kid)
+		}
+		sort.Strings(kids)
+		activeKid = kids[len(kids)-1]
+	}
+	signingKey, ok := privateKeys[activeKid]
+	if !ok{
+		return nil, fmt.Errorf("no private key for active kid %s", activeKid)
+	}
+	return &Keyring{
+		activeKid: activeKid,
+		signingKey: signingKey,
+		publicKeys: publicKeys,
+	}, nil
+}
+
+func (k *Keyring) Sign(claims jwt.Claims) (string, error){
+	var method jwt.SigningMethod
+	switch k.signingKey.(type){
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return "", ErrUnsupportedKey
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = k.activeKid
+	return token.SignedString(k.signingKey)
+}
+
+func (k *Keyring) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error){
+	return jwt.ParseWithClaims(
+		tokenString,
+		claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			key, ok := k.publicKeys[kid]
+			if !ok{
+				return nil, jwt.ErrTokenUnverifiable
+			}
+			return key, nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+	)
+}
+
+func (k *Keyring) GetPublicKeys() auth.JWKSModel{
+	kids := make([]string, 0, len(k.publicKeys))
+	for kid := range k.publicKeys{
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := auth.JWKSModel{Keys: make([]auth.JWKModel, 0, len(kids))}
+	for _, kid := range kids{
+		switch key := k.publicKeys[kid].(type){
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, auth.JWKModel{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwt.SigningMethodRS256.Alg(),
+				N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, auth.JWKModel{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: jwt.SigningMethodEdDSA.Alg(),
+				Crv: "Ed25519",
+				X: base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+	return jwks
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////
+//								private functions
+
+// returns the private key (nil for public key files) and the public key
+func parsePemKey(content []byte) (crypto.Signer, crypto.PublicKey, error){
+	block, _ := pem.Decode(content)
+	if block == nil{
+		return nil, nil, ErrUnsupportedKey
+	}
+	switch block.Type{
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil{
+			return nil, nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok{
+			return nil, nil, ErrUnsupportedKey
+		}
+		return checkSupportedKey(signer)
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil{
+			return nil, nil, err
+		}
+		return key, key.Public(), nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil{
+			return nil, nil, err
+		}
+		switch key.(type){
+		case *rsa.PublicKey, ed25519.PublicKey:
+			return nil, key, nil
+		}
+	}
+	return nil, nil, ErrUnsupportedKey
+}
+
+func checkSupportedKey(signer crypto.Signer) (crypto.Signer, crypto.PublicKey, error){
+	switch signer.(type){
+	case *rsa.PrivateKey, ed25519.PrivateKey:
+		return signer, signer.Public(), nil
+	}
+	return nil, nil, ErrUnsupportedKey
+}
+
+func generateKeyFile(dir string) (string, crypto.Signer, error){
+	key, err := rsa.GenerateKey(rand.Reader, GENERATED_KEY_BITS)
+	if err != nil{
+		return "", nil, err
+	}
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil{
+		return "", nil, err
+	}
+	kid := time.Now().UTC().Format("20060102150405")
+	content := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+	if err := os.WriteFile(filepath.Join(dir, kid+KEY_FILE_EXTENSION), content, 0600); err != nil{
+		return "", nil, err
+	}
+	log.Printf("Generated signing key %s", kid)
+	return kid, key, nil
+}
@@ -0,0 +1,183 @@
+package appauth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
+	"github.com/diegobermudez03/couples-backend/pkg/mail"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (s *AuthServiceImpl) SendEmailVerification(ctx context.Context, token string) error{
+	session, err := s.authRepo.GetSessionByToken(ctx, hashToken(token))
+	if err != nil{
+		return auth.ErrorSendingEmail
+	}else if session == nil{
+		return auth.ErrorNonExistingSession
+	}
+	userAuth, err := s.authRepo.GetUserById(ctx, session.UserAuthId)
+	if err != nil || userAuth == nil{
+		return auth.ErrorSendingEmail
+	}
+	return s.sendEmailVerification(ctx, userAuth)
+}
+
+
+func (s *AuthServiceImpl) VerifyEmail(ctx context.Context, verificationToken string) error{
+	return s.transactions.Do(ctx, func(ctx context.Context) error {
+		mailToken, err := s.useMailToken(ctx, verificationToken, auth.TOKEN_PURPOSE_EMAIL_VERIFICATION)
+		if err != nil{
+			return err
+		}
+		if num, err := s.authRepo.UpdateEmailVerifiedAt(ctx, mailToken.UserAuthId, time.Now()); err != nil || num == 0{
+			return auth.ErrorVerifyingEmail
+		}
+		return nil
+	})
+}
+
+
+// if there's no account with the email we don't say it, so the endpoint can't be used to find registered emails
+func (s *AuthServiceImpl) RequestPasswordReset(ctx context.Context, email string) error{
+	email = strings.ToLower(email)
+	userAuth, err := s.authRepo.GetUserByEmail(ctx, email)
+	if err != nil{
+		return auth.ErrorSendingEmail
+	}else if userAuth == nil{
+		return nil
+	}
+	token, err := s.createMailToken(ctx, userAuth.Id, auth.TOKEN_PURPOSE_PASSWORD_RESET, auth.PASSWORD_RESET_TOKEN_LIFE)
+	if err != nil{
+		return auth.ErrorSendingEmail
+	}
+	message := mail.MessageModel{
+		To: email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"We received a request to reset your password, open the following link to choose a new one:\n\n%s\n\nThe link expires in %d minutes. If you didn't ask for it you can ignore this email.",
+			s.getMailLink("reset-password", token), int(auth.PASSWORD_RESET_TOKEN_LIFE.Minutes()),
+		),
+	}
+	if err := s.mailSender.Send(ctx, &message); err != nil{
+		return auth.ErrorSendingEmail
+	}
+	return nil
+}
+
+
+// after the reset all the sessions of the account are closed
+func (s *AuthServiceImpl) ResetPassword(ctx context.Context, resetToken string, password string) error{
+	if err := validatePassword(password); err != nil{
+		return err
+	}
+	hashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil{
+		return auth.ErrorResettingPassword
+	}
+	hashString := string(hashBytes)
+
+	return s.transactions.Do(ctx, func(ctx context.Context) error {
+		mailToken, err := s.useMailToken(ctx, resetToken, auth.TOKEN_PURPOSE_PASSWORD_RESET)
+		if err != nil{
+			return err
+		}
+		userAuth, err := s.authRepo.GetUserById(ctx, mailToken.UserAuthId)
+		if err != nil || userAuth == nil{
+			return auth.ErrorResettingPassword
+		}
+		userAuth.Hash = &hashString
+		if num, err := s.authRepo.UpdateAuthUserById(ctx, userAuth.Id, userAuth); err != nil || num == 0{
+			return auth.ErrorResettingPassword
+		}
+		//the mail was received, so the email is verified too
+		if userAuth.EmailVerifiedAt == nil{
+			if _, err := s.authRepo.UpdateEmailVerifiedAt(ctx, userAuth.Id, time.Now()); err != nil{
+				return auth.ErrorResettingPassword
+			}
+		}
+		if _, err := s.authRepo.DeleteSessionsByAuthId(ctx, userAuth.Id); err != nil{
+			return auth.ErrorResettingPassword
+		}
+		return nil
+	})
+}
+
+/////////////////////////////////////////////////////////////////////////////////////////////////
+//								private functions
+
+func (s *AuthServiceImpl) sendEmailVerification(ctx context.Context, userAuth *auth.UserAuthModel) error{
+	if userAuth.Email == nil{
+		return auth.ErrAccountWithoutEmail
+	}
+	if userAuth.EmailVerifiedAt != nil{
+		return auth.ErrEmailAlreadyVerified
+	}
+	token, err := s.createMailToken(ctx, userAuth.Id, auth.TOKEN_PURPOSE_EMAIL_VERIFICATION, auth.EMAIL_VERIFICATION_TOKEN_LIFE)
+	if err != nil{
+		return auth.ErrorSendingEmail
+	}
+	message := mail.MessageModel{
+		To: *userAuth.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Open the following link to verify your email:\n\n%s\n\nThe link expires in %d hours.",
+			s.getMailLink("verify-email", token), int(auth.EMAIL_VERIFICATION_TOKEN_LIFE.Hours()),
+		),
+	}
+	if err := s.mailSender.Send(ctx, &message); err != nil{
+		return auth.ErrorSendingEmail
+	}
+	return nil
+}
+
+// creates a new token for the purpose, the previous ones of the same purpose stop being valid
+func (s *AuthServiceImpl) createMailToken(ctx context.Context, authId uuid.UUID, purpose string, life time.Duration) (string, error){
+	token, err := generateRandomToken()
+	if err != nil{
+		return "", err
+	}
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.authRepo.InvalidateAuthTokens(ctx, authId, purpose); err != nil{
+			return err
+		}
+		model := auth.AuthTokenModel{
+			Id: uuid.New(),
+			TokenHash: hashToken(token),
+			Purpose: purpose,
+			ExpiresAt: time.Now().Add(life),
+			CreatedAt: time.Now(),
+			UserAuthId: authId,
+		}
+		if num, err := s.authRepo.CreateAuthToken(ctx, &model); err != nil || num == 0{
+			return auth.ErrorSendingEmail
+		}
+		return nil
+	})
+	return token, err
+}
+
+// returns the token marking it as used, it fails if it was already used or it expired
+func (s *AuthServiceImpl) useMailToken(ctx context.Context, token string, purpose string) (*auth.AuthTokenModel, error){
+	mailToken, err := s.authRepo.GetAuthTokenByHash(ctx, hashToken(token), purpose)
+	if err != nil{
+		return nil, auth.ErrorVerifyingEmail
+	}
+	if mailToken == nil || mailToken.UsedAt != nil || mailToken.ExpiresAt.Before(time.Now()){
+		return nil, auth.ErrInvalidMailToken
+	}
+	if num, err := s.authRepo.UseAuthToken(ctx, mailToken.Id, time.Now()); err != nil{
+		return nil, auth.ErrorVerifyingEmail
+	}else if num == 0{
+		return nil, auth.ErrInvalidMailToken
+	}
+	return mailToken, nil
+}
+
+func (s *AuthServiceImpl) getMailLink(path string, token string) string{
+	return fmt.Sprintf("%s/%s?token=%s", strings.TrimSuffix(s.mailLinksUrl, "/"), path, url.QueryEscape(token))
+}
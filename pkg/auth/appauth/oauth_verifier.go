@@ -0,0 +1,59 @@
+package appauth
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type idTokenClaims struct{
+	Email 	string 	`json:"email"`
+	jwt.RegisteredClaims
+}
+
+type JwksOAuthVerifier struct{
+	keys 		*jwksKeySet
+	audience 	string
+	issuers 	[]string
+}
+
+func NewJwksOAuthVerifier(jwksSource string, audience string, issuers []string) auth.OAuthVerifier{
+	return &JwksOAuthVerifier{
+		keys: newJwksKeySet(jwksSource),
+		audience: audience,
+		issuers: issuers,
+	}
+}
+
+func (v *JwksOAuthVerifier) VerifyIdToken(ctx context.Context, idToken string) (*auth.OAuthIdentity, error){
+	claims := new(idTokenClaims)
+	token, err := jwt.ParseWithClaims(
+		idToken,
+		claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return v.keys.getKey(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+	)
+	if errors.Is(err, auth.ErrorGettingOAuthKeys){
+		return nil, auth.ErrorGettingOAuthKeys
+	}else if err != nil || !token.Valid{
+		return nil, auth.ErrInvalidOAuthToken
+	}
+	if !slices.Contains(v.issuers, claims.Issuer) || claims.Subject == ""{
+		return nil, auth.ErrInvalidOAuthToken
+	}
+	identity := &auth.OAuthIdentity{
+		Subject: claims.Subject,
+	}
+	if claims.Email != ""{
+		identity.Email = &claims.Email
+	}
+	return identity, nil
+}
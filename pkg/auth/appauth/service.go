@@ -3,15 +3,22 @@ package appauth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"log"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/diegobermudez03/couples-backend/pkg/auth"
+	"github.com/diegobermudez03/couples-backend/pkg/events"
 	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
+	"github.com/diegobermudez03/couples-backend/pkg/mail"
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
 	"github.com/diegobermudez03/couples-backend/pkg/users"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -25,33 +32,48 @@ type AuthServiceImpl struct {
 	usersService 		users.UsersService
 	accessTokenLife 	int64
 	refreshTokenLife 	int64
-	jwtSecret 			string
+	signer 				auth.TokenSigner
 	codeSuscribers 		map[uuid.UUID] chan string
 	suscribersMutex 	sync.RWMutex
+	coupleSuscribers 	map[uuid.UUID] chan string
+	coupleMutex 		sync.RWMutex
+	oauthVerifiers 		map[string]auth.OAuthVerifier
+	mailSender 			mail.Sender
+	mailLinksUrl 		string
+	limiter 			ratelimit.Service
+	bus 				events.Bus
 }
 
 
-func NewAuthService(transactions infraestructure.Transaction, authRepo auth.AuthRepository, usersService users.UsersService, accessTokenLife int64, refreshTokenLife int64, jwtSecret string) auth.AuthService{
-	return &AuthServiceImpl{
+func NewAuthService(transactions infraestructure.Transaction, authRepo auth.AuthRepository, usersService users.UsersService, accessTokenLife int64, refreshTokenLife int64, signer auth.TokenSigner, oauthVerifiers map[string]auth.OAuthVerifier, mailSender mail.Sender, mailLinksUrl string, limiter ratelimit.Service, bus events.Bus) (auth.AuthService, error){
+	service := &AuthServiceImpl{
 		transactions : transactions,
 		authRepo: authRepo,
 		usersService: usersService,
 		accessTokenLife: accessTokenLife,
 		refreshTokenLife : refreshTokenLife,
-		jwtSecret: jwtSecret,
+		signer: signer,
 		codeSuscribers: make(map[uuid.UUID] chan string),
 		suscribersMutex : sync.RWMutex{},
-	}
+		coupleSuscribers: make(map[uuid.UUID] chan string),
+		coupleMutex: sync.RWMutex{},
+		oauthVerifiers: oauthVerifiers,
+		mailSender: mailSender,
+		mailLinksUrl: mailLinksUrl,
+		limiter: limiter,
+		bus: bus,
+	}
+	if err := bus.Suscribe(auth.NOTIFICATIONS_CHANNEL, service.deliverNotification); err != nil{
+		return nil, err
+	}
+	return service, nil
 }
 
 
 func(s *AuthServiceImpl) RegisterUserAuth(ctx context.Context, email, password, device, os, token string) (string, error){
 	// data verifications
-	if num := len(password); num < 6 {
-		return "", auth.ErrorInsecurePassword
-	}
-	if match, err := regexp.MatchString(`\d`, password); !match || err != nil {
-		return "", auth.ErrorInsecurePassword
+	if err := validatePassword(password); err != nil{
+		return "", err
 	}
 	email = strings.ToLower(email)
 	// confirm email uniqueness
@@ -71,7 +93,7 @@ func(s *AuthServiceImpl) RegisterUserAuth(ctx context.Context, email, password,
 	
 	// check token 
 	if token != ""{
-		session, _ := s.authRepo.GetSessionByToken(ctx, token)
+		session, _ := s.authRepo.GetSessionByToken(ctx, hashToken(token))
 		//if the token do have a session associated
 		if session != nil{
 			userAuth, _ := s.authRepo.GetUserById(ctx, session.UserAuthId)
@@ -87,6 +109,7 @@ func(s *AuthServiceImpl) RegisterUserAuth(ctx context.Context, email, password,
 				); err != nil || num == 0{
 					return "", auth.ErrorVinculatingAccount 
 				}
+				go s.sendRegistrationVerification(userAuth)
 				return token, nil
 			}
 		}
@@ -102,14 +125,19 @@ func(s *AuthServiceImpl) RegisterUserAuth(ctx context.Context, email, password,
 	if err != nil || num == 0{
 		return "",  auth.ErrorCreatingAccount
 	}
+	go s.sendRegistrationVerification(&auth.UserAuthModel{Id: userId, Email: &email})
 
 	//create the session
 	return s.createSession(ctx, userId, &device, &os)
 }
 
 
+// the attempts are limited per account, a successful login resets them
 func (s *AuthServiceImpl) LoginUserAuth(ctx context.Context, email string, password string, device string, os string) (string, error){
 	email = strings.ToLower(email)
+	if err := checkAttempt(ctx, s.limiter, auth.LOGIN_ACCOUNT_POLICY, email); err != nil{
+		return "", err
+	}
 	user, err := s.authRepo.GetUserByEmail(ctx, email)
 	if err != nil{
 		return "", auth.ErrorWithLogin
@@ -121,11 +149,12 @@ func (s *AuthServiceImpl) LoginUserAuth(ctx context.Context, email string, passw
 		return "", auth.ErrorIncorrectPassword
 	}
 
+	s.limiter.Reset(ctx, auth.LOGIN_ACCOUNT_POLICY, email)
 	return s.createSession(ctx, user.Id, &device, &os)
 }
 
 func (s *AuthServiceImpl) CloseUsersSession(ctx context.Context, token string) error{
-	session, err := s.authRepo.GetSessionByToken(ctx, token)
+	session, err := s.authRepo.GetSessionByToken(ctx, hashToken(token))
 	if err != nil{
 		return auth.ErrorWithLogout
 	} else if session == nil{
@@ -154,24 +183,32 @@ func (s *AuthServiceImpl) CloseUsersSession(ctx context.Context, token string) e
 	})
 }
 
-func (s *AuthServiceImpl) CreateTempCouple(ctx context.Context, token string, startDate int) (int, error){
+func (s *AuthServiceImpl) CreateTempCouple(ctx context.Context, token string, startDate int) (*auth.TempCoupleModel, error){
 	userId, err := s.getUserIdFromSession(ctx, token)
 	if err != nil{
-		return 0, auth.ErrorcreatingTempCouple  
+		return nil, auth.ErrorcreatingTempCouple  
 	}
 	if userId == nil{
-		return 0, auth.ErrorNoActiveUser
+		return nil, auth.ErrorNoActiveUser
 	}
-	code, err := s.usersService.CreateTempCouple(ctx, *userId, startDate)
+	tempCouple, inviteLink, err := s.usersService.CreateTempCouple(ctx, *userId, startDate)
 	if errors.Is(err, users.ErrorUserHasActiveCouple){
-		return 0, auth.ErrCantCreateNewCouple
+		return nil, auth.ErrCantCreateNewCouple
+	}else if err != nil{
+		return nil, auth.ErrorcreatingTempCouple
 	}
-	return code, err
+	return &auth.TempCoupleModel{
+		Code: tempCouple.Code,
+		StartDate: tempCouple.StartDate,
+		ExpiresAt: tempCouple.ExpiresAt,
+		QrUrl: tempCouple.QrUrl,
+		InviteLink: &inviteLink,
+	}, nil
 }
 
 func (s *AuthServiceImpl) CreateUser(ctx context.Context, token, firstName, lastName, gender, countryCode, languageCode string,birthDate int,) (string, error){
 	//check token if its validate
-	session, _ := s.authRepo.GetSessionByToken(ctx, token)
+	session, _ := s.authRepo.GetSessionByToken(ctx, hashToken(token))
 	if session != nil{
 		userAuth, _ := s.authRepo.GetUserById(ctx, session.UserAuthId)
 		if userAuth != nil && userAuth.UserId != nil{
@@ -239,57 +276,40 @@ func (s *AuthServiceImpl) CheckUserAuthStatus(ctx context.Context, token string)
 
 }
 
+// the codes are short, so the attempts of each session are limited to avoid guessing them
 func (s *AuthServiceImpl) ConnectCouple(ctx context.Context, token string, code int) (string, error) {
-	session, err := s.authRepo.GetSessionByToken(ctx, token)
-	if err != nil{
-		return "", auth.ErrorUnableToConnectCouple 
-	}else if session == nil{
-		return "", auth.ErrorNonExistingSession
-	}
-	authUser, err := s.authRepo.GetUserById(ctx, session.UserAuthId)
-	if err != nil || authUser == nil{
-		return "", auth.ErrorUnableToConnectCouple  
-	}
-	coupleId, partnerId, err := s.usersService.ConnectCouple(ctx, *authUser.UserId, code)
-	if errors.Is(err, users.ErrorCantConnectWithYourself){
-		return "", auth.ErrCantConnectWithYourself
-	}else if errors.Is(err, users.ErrorUserHasActiveCouple){
-		return "", auth.ErrCantCreateNewCouple
-	}else if errors.Is(err, users.ErrorInvalidCode){
-		return "", auth.ErrNonExistingCode
-	}else if err != nil{
-		return "",  auth.ErrorUnableToConnectCouple  
-	}
+	return s.connectCouple(ctx, token, func(ctx context.Context, sessionId uuid.UUID, userId uuid.UUID) (*uuid.UUID, *uuid.UUID, error){
+		if err := checkAttempt(ctx, s.limiter, auth.COUPLE_CODE_SESSION_POLICY, sessionId.String()); err != nil{
+			return nil, nil, err
+		}
+		coupleId, partnerId, err := s.usersService.ConnectCouple(ctx, userId, code)
+		if err == nil{
+			s.limiter.Reset(ctx, auth.COUPLE_CODE_SESSION_POLICY, sessionId.String())
+		}
+		return coupleId, partnerId, err
+	})
+}
 
-	s.suscribersMutex.RLock()
-	channel, ok := s.codeSuscribers[*partnerId]
-	s.suscribersMutex.RUnlock()
-	
-	if ok{
-		channel <- auth.StatusVinculated
-	}
-	return s.createAccessToken(*authUser.UserId, *coupleId, session.Id)
+func (s *AuthServiceImpl) ConnectCoupleByInvite(ctx context.Context, token string, inviteToken string) (string, error){
+	return s.connectCouple(ctx, token, func(ctx context.Context, sessionId uuid.UUID, userId uuid.UUID) (*uuid.UUID, *uuid.UUID, error){
+		return s.usersService.ConnectCoupleByInvite(ctx, userId, inviteToken)
+	})
 }
 
 
+// every refresh rotates the refresh token, presenting a rotated token again is treated as
+// a stolen token and the whole session is revoked
 func (s *AuthServiceImpl) CreateAccessToken(ctx context.Context, token string)(string, *string, error){
-	session, err := s.authRepo.GetSessionByToken(ctx, token)
+	tokenHash := hashToken(token)
+	session, err := s.authRepo.GetSessionByToken(ctx, tokenHash)
 	if err != nil{
 		return "", nil, auth.ErrorNonExistingSession 
+	}else if session == nil{
+		return "", nil, s.checkRotatedToken(ctx, tokenHash)
 	}
-	var newRefreshToken *string = nil
-	//if expired, we create a new one
-	if(session.ExpiresAt.Before(time.Now())){
-		rToken, err := s.createSession(ctx, session.UserAuthId, session.Device, session.Os)
-		if err != nil{
-			go func(){s.authRepo.DeleteSessionById(ctx, session.Id)}()
-			newRefreshToken = &rToken
-		}
-	}else{
-		//in a separated go routine we update the last time used of the session
-		go func(){
-			s.authRepo.UpdateSessionLastUsed(context.Background(), session.Id, time.Now())
-		}()
+	if session.ExpiresAt.Before(time.Now()){
+		go func(){s.authRepo.DeleteSessionById(context.Background(), session.Id)}()
+		return "", nil, auth.ErrorExpiredRefreshToken
 	}
 	user, err := s.authRepo.GetUserById(ctx, session.UserAuthId)
 	if err != nil{
@@ -299,20 +319,24 @@ func (s *AuthServiceImpl) CreateAccessToken(ctx context.Context, token string)(s
 		return "", nil, auth.ErrorNoActiveUser
 	}
 	couple, err := s.usersService.GetCoupleFromUser(ctx, *user.UserId)
-	if err != nil{
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return "", nil, auth.ErrorNoActiveCoupleFromUser
+	}else if err != nil{
 		return "", nil, auth.ErrorCreatingAccessToken 
 	}
 	if couple == nil {
 		return "", nil, auth.ErrorNoActiveCoupleFromUser
 	}
+	newRefreshToken, err := s.rotateSessionToken(ctx, session, tokenHash)
+	if err != nil{
+		return "", nil, err
+	}
 	accessToken, err := s.createAccessToken(*user.UserId, couple.Id, session.Id)
-	return accessToken, newRefreshToken, err
+	return accessToken, &newRefreshToken, err
 }
 
 func (s *AuthServiceImpl) ValidateAccessToken(ctx context.Context, accessTokenString string) (*auth.AccessClaims, error){
-	accessToken, err := jwt.ParseWithClaims(accessTokenString, &auth.AccessClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
-	})
+	accessToken, err := s.signer.Parse(accessTokenString, &auth.AccessClaims{})
 	if err != nil{
 		if errors.Is(err, jwt.ErrTokenExpired){
 			return nil, auth.ErrorExpiredAccessToken
@@ -323,12 +347,31 @@ func (s *AuthServiceImpl) ValidateAccessToken(ctx context.Context, accessTokenSt
 		return nil, auth.ErrorExpiredAccessToken
 	}
 	claims := accessToken.Claims.(*auth.AccessClaims)
+
+	//the session could have been revoked from another device
+	session, err := s.authRepo.GetSessionById(ctx, claims.SessionId)
+	if err != nil{
+		return nil, auth.ErrorGettingSessions
+	}else if session == nil{
+		return nil, auth.ErrorSessionRevoked
+	}
+
+	//the token is no longer valid if the couple in it ended
+	couple, err := s.usersService.GetCoupleFromUser(ctx, claims.UserId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, auth.ErrorCoupleEnded
+	}else if err != nil{
+		return nil, auth.ErrorCheckingCouple
+	}
+	if couple.Id != claims.CoupleId{
+		return nil, auth.ErrorCoupleEnded
+	}
 	return claims, nil
 }
 
 func (s *AuthServiceImpl) LogoutSession(ctx context.Context, sessionId uuid.UUID) error{
 	session, err := s.authRepo.GetSessionById(ctx, sessionId)
-	if err != nil{
+	if err != nil || session == nil{
 		return auth.ErrorWithLogout 
 	}
 	authUser, err := s.authRepo.GetUserById(ctx, session.UserAuthId)
@@ -347,7 +390,7 @@ func (s *AuthServiceImpl) LogoutSession(ctx context.Context, sessionId uuid.UUID
 
 func (s *AuthServiceImpl) GetTempCoupleOfUser(ctx context.Context, token string)(*auth.TempCoupleModel, error){
 	userId, err := s.getUserIdFromSession(ctx, token)
-	if err != nil{
+	if err != nil || userId == nil{
 		return nil, auth.ErrorGettingTempCouple
 	}
 	tempCouple, err:= s.usersService.GetTempCoupleFromUser(ctx, *userId)
@@ -360,6 +403,8 @@ func (s *AuthServiceImpl) GetTempCoupleOfUser(ctx context.Context, token string)
 	*tempCoupleAuth = auth.TempCoupleModel{
 		Code: tempCouple.Code,
 		StartDate: tempCouple.StartDate,
+		ExpiresAt: tempCouple.ExpiresAt,
+		QrUrl: tempCouple.QrUrl,
 	}
 	return tempCoupleAuth, nil
 }
@@ -375,7 +420,7 @@ func (s *AuthServiceImpl) RemoveCodeSuscriber(userId uuid.UUID){
 }
 
 func (s *AuthServiceImpl) SuscribeTempCoupleNot(ctx context.Context, token string)(chan string, *uuid.UUID, error){
-	session, err := s.authRepo.GetSessionByToken(ctx, token)
+	session, err := s.authRepo.GetSessionByToken(ctx, hashToken(token))
 	if err != nil{
 		return nil, nil, auth.ErrUnableToSuscribe 
 	}else if session == nil{
@@ -385,7 +430,7 @@ func (s *AuthServiceImpl) SuscribeTempCoupleNot(ctx context.Context, token strin
 	if err != nil || authUser == nil{
 		return nil, nil, auth.ErrUnableToSuscribe  
 	}
-	_, err = s.usersService.GetTempCoupleFromUser(ctx, *authUser.UserId)
+	tempCouple, err := s.usersService.GetTempCoupleFromUser(ctx, *authUser.UserId)
 	if errors.Is(err, users.ErrorNoTempCoupleFound){
 		return nil, nil, auth.ErrNoCodeToSuscribe
 	}else if err != nil{
@@ -393,13 +438,94 @@ func (s *AuthServiceImpl) SuscribeTempCoupleNot(ctx context.Context, token strin
 	}
 	s.RemoveCodeSuscriber(*authUser.UserId)
 	s.suscribersMutex.Lock()
-	newChannel := make(chan string)
+	//buffered so the notifications never block the sender
+	newChannel := make(chan string, 1)
 	s.codeSuscribers[*authUser.UserId] = newChannel
 	s.suscribersMutex.Unlock()
+	s.scheduleCodeExpiration(*authUser.UserId, newChannel, tempCouple.ExpiresAt)
 	return newChannel, authUser.UserId,nil
 }
 
 
+func (s *AuthServiceImpl) DisconnectCouple(ctx context.Context, userId uuid.UUID) error{
+	partnerId, err := s.usersService.DisconnectCouple(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return auth.ErrorNoActiveCoupleFromUser
+	}else if err != nil{
+		return auth.ErrorDisconnectingCouple
+	}
+
+	s.notify(*partnerId, auth.StatusDisconnected)
+	return nil
+}
+
+func (s *AuthServiceImpl) RemoveCoupleSuscriber(userId uuid.UUID){
+	s.coupleMutex.Lock()
+	channel, ok := s.coupleSuscribers[userId]
+	if ok{
+		delete(s.coupleSuscribers, userId)
+		close(channel)
+	}
+	s.coupleMutex.Unlock()
+}
+
+func (s *AuthServiceImpl) SuscribeCoupleNot(ctx context.Context, userId uuid.UUID)(chan string, error){
+	s.RemoveCoupleSuscriber(userId)
+	s.coupleMutex.Lock()
+	//buffered so the disconnection doesn't wait for the subscriber to read
+	newChannel := make(chan string, 1)
+	s.coupleSuscribers[userId] = newChannel
+	s.coupleMutex.Unlock()
+	return newChannel, nil
+}
+
+
+func (s *AuthServiceImpl) OAuthLogin(ctx context.Context, provider, idToken, device, os, token string) (string, error){
+	verifier, ok := s.oauthVerifiers[provider]
+	if !ok{
+		return "", auth.ErrUnsupportedOAuthProvider
+	}
+	identity, err := verifier.VerifyIdToken(ctx, idToken)
+	if err != nil{
+		return "", err
+	}
+
+	// if the identity already has an account we just login
+	userAuth, err := s.authRepo.GetUserByOAuth(ctx, provider, identity.Subject)
+	if err != nil{
+		return "", auth.ErrorWithOAuthLogin
+	}else if userAuth != nil{
+		return s.createSession(ctx, userAuth.Id, &device, &os)
+	}
+
+	// check token, if it's from an anonymous account we vinculate the identity to it
+	if token != ""{
+		session, _ := s.authRepo.GetSessionByToken(ctx, hashToken(token))
+		if session != nil{
+			userAuth, _ := s.authRepo.GetUserById(ctx, session.UserAuthId)
+			if userAuth != nil && s.checkIfAnonymousAuth(userAuth){
+				userAuth.OauthProvider = &provider
+				userAuth.OauthId = &identity.Subject
+				if num, err := s.authRepo.UpdateAuthUserById(
+					ctx,
+					userAuth.Id,
+					userAuth,
+				); err != nil || num == 0{
+					return "", auth.ErrorVinculatingAccount
+				}
+				return token, nil
+			}
+		}
+	}
+
+	authId := uuid.New()
+	if num, err := s.authRepo.CreateOAuthUserAuth(ctx, authId, provider, identity.Subject); err != nil || num == 0{
+		return "", auth.ErrorCreatingAccount
+	}
+	return s.createSession(ctx, authId, &device, &os)
+}
+
+
 /////////////////////////////////////////////////////////////////////////////////////////////////
 ////////////////////////////////////////////////////////////////////////////////////////////////
 //								private functions
@@ -409,24 +535,23 @@ func (s *AuthServiceImpl) SuscribeTempCoupleNot(ctx context.Context, token strin
 func (s *AuthServiceImpl) createSession(ctx context.Context, authId uuid.UUID, device *string, os *string) (string, error){
 	var token string 
 	for{
-		randomBytes := make([]byte, 32)
-		_, err := rand.Read(randomBytes) 
+		randomToken, err := generateRandomToken()
 		if err != nil{
 			return "", auth.ErrorCreatingSession 
 		}
-		token = base64.URLEncoding.EncodeToString(randomBytes)
-		session, _ := s.authRepo.GetSessionByToken(ctx, token)
+		token = randomToken
+		session, _ := s.authRepo.GetSessionByToken(ctx, hashToken(token))
 		if session == nil{
 			break
 		}
 	}
-	//expires in 1 month
+	//only the hash of the token is stored
 	session := auth.SessionModel{
 		Id: uuid.New(),
-		Token: token,
+		Token: hashToken(token),
 		Device: device,
 		Os: os,
-		ExpiresAt: time.Now().AddDate(0,0,30),
+		ExpiresAt: time.Now().Add(time.Duration(s.refreshTokenLife*int64(time.Second))),
 		CreatedAt: time.Now(),
 		LastUsed: time.Now(),
 		UserAuthId: authId,
@@ -442,6 +567,53 @@ func (s *AuthServiceImpl) createSession(ctx context.Context, authId uuid.UUID, d
 }
 
 
+// replaces the token of the session, keeping the old one to detect if it's used again
+func (s *AuthServiceImpl) rotateSessionToken(ctx context.Context, session *auth.SessionModel, oldTokenHash string) (string, error){
+	newToken, err := generateRandomToken()
+	if err != nil{
+		return "", auth.ErrorCreatingSession
+	}
+	now := time.Now()
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		//if no row was updated someone else rotated the token first
+		num, err := s.authRepo.UpdateSessionToken(ctx, session.Id, oldTokenHash, hashToken(newToken), now.Add(time.Duration(s.refreshTokenLife*int64(time.Second))), now)
+		if err != nil{
+			return auth.ErrorCreatingSession
+		}else if num == 0{
+			return auth.ErrorRefreshTokenReused
+		}
+		rotated := auth.RotatedTokenModel{
+			TokenHash: oldTokenHash,
+			SessionId: session.Id,
+			RotatedAt: now,
+		}
+		if num, err := s.authRepo.CreateRotatedToken(ctx, &rotated); err != nil || num == 0{
+			return auth.ErrorCreatingSession
+		}
+		return nil
+	})
+	if errors.Is(err, auth.ErrorRefreshTokenReused){
+		s.authRepo.DeleteSessionById(ctx, session.Id)
+	}
+	if err != nil{
+		return "", err
+	}
+	return newToken, nil
+}
+
+// if the token was already rotated the session is revoked
+func (s *AuthServiceImpl) checkRotatedToken(ctx context.Context, tokenHash string) error{
+	rotated, err := s.authRepo.GetRotatedToken(ctx, tokenHash)
+	if err != nil || rotated == nil{
+		return auth.ErrorNonExistingSession
+	}
+	if _, err := s.authRepo.DeleteSessionById(ctx, rotated.SessionId); err != nil{
+		return auth.ErrorCreatingAccessToken
+	}
+	log.Printf("Revoked session %s because a rotated refresh token was used", rotated.SessionId)
+	return auth.ErrorRefreshTokenReused
+}
+
 func (s *AuthServiceImpl) createAccessToken(userId uuid.UUID, coupleId uuid.UUID, sessionId uuid.UUID) (string, error){
 	claims := auth.AccessClaims{
 		UserId: userId,
@@ -451,16 +623,111 @@ func (s *AuthServiceImpl) createAccessToken(userId uuid.UUID, coupleId uuid.UUID
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(s.accessTokenLife*int64(time.Second)))),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	tokenString, err := s.signer.Sign(claims)
 	if err != nil{
 		return "", auth.ErrorCreatingAccessToken
 	}
 	return tokenString, nil
 }
 
+// the couple connection is the same with the code or the invite link, only the way of finding the partner changes
+func (s *AuthServiceImpl) connectCouple(ctx context.Context, token string, connect func(ctx context.Context, sessionId uuid.UUID, userId uuid.UUID)(*uuid.UUID, *uuid.UUID, error)) (string, error){
+	session, err := s.authRepo.GetSessionByToken(ctx, hashToken(token))
+	if err != nil{
+		return "", auth.ErrorUnableToConnectCouple 
+	}else if session == nil{
+		return "", auth.ErrorNonExistingSession
+	}
+	authUser, err := s.authRepo.GetUserById(ctx, session.UserAuthId)
+	if err != nil || authUser == nil || authUser.UserId == nil{
+		return "", auth.ErrorUnableToConnectCouple  
+	}
+	coupleId, partnerId, err := connect(ctx, session.Id, *authUser.UserId)
+	if errors.Is(err, auth.ErrTooManyAttempts){
+		return "", err
+	}else if errors.Is(err, users.ErrorCantConnectWithYourself){
+		return "", auth.ErrCantConnectWithYourself
+	}else if errors.Is(err, users.ErrorUserHasActiveCouple){
+		return "", auth.ErrCantCreateNewCouple
+	}else if errors.Is(err, users.ErrorInvalidCode){
+		return "", auth.ErrNonExistingCode
+	}else if errors.Is(err, users.ErrorExpiredCode){
+		return "", auth.ErrExpiredCode
+	}else if err != nil{
+		return "",  auth.ErrorUnableToConnectCouple  
+	}
+
+	s.notify(*partnerId, auth.StatusVinculated)
+	return s.createAccessToken(*authUser.UserId, *coupleId, session.Id)
+}
+
+// the user may be suscribed to another instance, so the notification goes through the bus
+func (s *AuthServiceImpl) notify(userId uuid.UUID, status string){
+	payload, err := json.Marshal(auth.NotificationModel{UserId: userId, Status: status})
+	if err != nil{
+		log.Print("error encoding notification: ", err.Error())
+		return
+	}
+	s.bus.Publish(context.Background(), auth.NOTIFICATIONS_CHANNEL, payload)
+}
+
+// delivers the notifications to the suscribers of this instance, the vinculation goes to the
+// code suscribers and the disconnection to the couple suscribers
+func (s *AuthServiceImpl) deliverNotification(payload []byte){
+	var notification auth.NotificationModel
+	if err := json.Unmarshal(payload, &notification); err != nil{
+		log.Print("error decoding notification: ", err.Error())
+		return
+	}
+	suscribers, mutex := s.codeSuscribers, &s.suscribersMutex
+	if notification.Status == auth.StatusDisconnected{
+		suscribers, mutex = s.coupleSuscribers, &s.coupleMutex
+	}
+	mutex.RLock()
+	defer mutex.RUnlock()
+	channel, ok := suscribers[notification.UserId]
+	if !ok{
+		return
+	}
+	select{
+	case channel <- notification.Status:
+	default:
+	}
+}
+
+// notifies the suscriber when its code expires, if the code was renewed meanwhile it waits for the new expiration
+func (s *AuthServiceImpl) scheduleCodeExpiration(userId uuid.UUID, channel chan string, expiresAt time.Time){
+	time.AfterFunc(time.Until(expiresAt), func(){
+		s.suscribersMutex.RLock()
+		current, ok := s.codeSuscribers[userId]
+		s.suscribersMutex.RUnlock()
+		//the suscriber was replaced or removed
+		if !ok || current != channel{
+			return
+		}
+		tempCouple, err := s.usersService.GetTempCoupleFromUser(context.Background(), userId)
+		if err != nil{
+			//the couple was connected or the code deleted
+			return
+		}
+		if !tempCouple.IsExpired(){
+			s.scheduleCodeExpiration(userId, channel, tempCouple.ExpiresAt)
+			return
+		}
+		s.suscribersMutex.RLock()
+		defer s.suscribersMutex.RUnlock()
+		if current, ok := s.codeSuscribers[userId]; !ok || current != channel{
+			return
+		}
+		select{
+		case channel <- auth.StatusCodeExpired:
+		default:
+		}
+	})
+}
+
 func (s *AuthServiceImpl) getUserIdFromSession(ctx context.Context, token string) (*uuid.UUID, error){
-	session, err := s.authRepo.GetSessionByToken(ctx, token)
+	session, err := s.authRepo.GetSessionByToken(ctx, hashToken(token))
 	if err != nil || session == nil{
 		return nil, auth.ErrorNonExistingSession 
 	}
@@ -471,6 +738,47 @@ func (s *AuthServiceImpl) getUserIdFromSession(ctx context.Context, token string
 	return user.UserId, nil
 }
 
+func generateRandomToken() (string, error){
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil{
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(randomBytes), nil
+}
+
+func validatePassword(password string) error{
+	if num := len(password); num < 6 {
+		return auth.ErrorInsecurePassword
+	}
+	if match, err := regexp.MatchString(`\d`, password); !match || err != nil {
+		return auth.ErrorInsecurePassword
+	}
+	return nil
+}
+
+// the registration doesn't fail if the verification mail couldn't be sent, the user can ask for it again
+func (s *AuthServiceImpl) sendRegistrationVerification(userAuth *auth.UserAuthModel){
+	if err := s.sendEmailVerification(context.Background(), userAuth); err != nil{
+		log.Print("error sending verification email: ", err.Error())
+	}
+}
+
+func hashToken(token string) string{
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// if the limits can't be checked the attempt is allowed, so a store failure doesn't block every login
+func checkAttempt(ctx context.Context, limiter ratelimit.Service, policy ratelimit.PolicyModel, key string) error{
+	_, err := limiter.Allow(ctx, policy, key)
+	if errors.Is(err, ratelimit.ErrTooManyAttempts){
+		return auth.ErrTooManyAttempts
+	}else if err != nil{
+		log.Print("unable to check rate limit: ", err.Error())
+	}
+	return nil
+}
+
 func (s *AuthServiceImpl) checkIfAnonymousAuth(auth *auth.UserAuthModel) bool{
 	return auth.Email == nil && auth.OauthProvider == nil
 }
\ No newline at end of file
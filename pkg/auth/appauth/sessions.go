@@ -0,0 +1,73 @@
+package appauth
+
+import (
+	"context"
+
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
+	"github.com/google/uuid"
+)
+
+func (s *AuthServiceImpl) GetAccountSessions(ctx context.Context, currentSessionId uuid.UUID) ([]auth.DeviceSessionModel, error){
+	current, err := s.authRepo.GetSessionById(ctx, currentSessionId)
+	if err != nil{
+		return nil, auth.ErrorGettingSessions
+	}else if current == nil{
+		return nil, auth.ErrorNonExistingSession
+	}
+	sessions, err := s.authRepo.GetActiveSessionsByAuthId(ctx, current.UserAuthId)
+	if err != nil{
+		return nil, auth.ErrorGettingSessions
+	}
+	models := make([]auth.DeviceSessionModel, 0, len(sessions))
+	for _, session := range sessions{
+		models = append(models, auth.DeviceSessionModel{
+			Id: session.Id,
+			Device: session.Device,
+			Os: session.Os,
+			CreatedAt: session.CreatedAt,
+			LastUsed: session.LastUsed,
+			ExpiresAt: session.ExpiresAt,
+			Current: session.Id == currentSessionId,
+		})
+	}
+	return models, nil
+}
+
+
+// the session must be from the same account, revoking the current one is the same as logging out
+func (s *AuthServiceImpl) RevokeSession(ctx context.Context, currentSessionId uuid.UUID, sessionId uuid.UUID) error{
+	if sessionId == currentSessionId{
+		return s.LogoutSession(ctx, currentSessionId)
+	}
+	current, err := s.authRepo.GetSessionById(ctx, currentSessionId)
+	if err != nil{
+		return auth.ErrorRevokingSession
+	}else if current == nil{
+		return auth.ErrorNonExistingSession
+	}
+	session, err := s.authRepo.GetSessionById(ctx, sessionId)
+	if err != nil{
+		return auth.ErrorRevokingSession
+	}
+	if session == nil || session.UserAuthId != current.UserAuthId{
+		return auth.ErrorNonExistingSession
+	}
+	if num, err := s.authRepo.DeleteSessionById(ctx, session.Id); err != nil || num == 0{
+		return auth.ErrorRevokingSession
+	}
+	return nil
+}
+
+
+func (s *AuthServiceImpl) RevokeOtherSessions(ctx context.Context, currentSessionId uuid.UUID) error{
+	current, err := s.authRepo.GetSessionById(ctx, currentSessionId)
+	if err != nil{
+		return auth.ErrorRevokingSession
+	}else if current == nil{
+		return auth.ErrorNonExistingSession
+	}
+	if _, err := s.authRepo.DeleteSessionsByAuthIdExcept(ctx, current.UserAuthId, current.Id); err != nil{
+		return auth.ErrorRevokingSession
+	}
+	return nil
+}
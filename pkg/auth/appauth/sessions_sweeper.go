@@ -0,0 +1,66 @@
+package appauth
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/auth"
+)
+
+// deletes periodically the expired sessions until it's stopped
+type SessionsSweeper struct{
+	repo 		auth.AuthRepository
+	interval 	time.Duration
+	stop 		chan struct{}
+	done 		chan struct{}
+	stopOnce 	sync.Once
+}
+
+func NewSessionsSweeper(repo auth.AuthRepository, interval time.Duration) *SessionsSweeper{
+	return &SessionsSweeper{
+		repo: repo,
+		interval: interval,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+func (s *SessionsSweeper) Start(){
+	go func(){
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		s.sweep()
+		for{
+			select{
+			case <- s.stop:
+				return
+			case <- ticker.C:
+				s.sweep()
+			}
+		}
+	}()
+}
+
+// waits until the running sweep finishes
+func (s *SessionsSweeper) Stop(){
+	s.stopOnce.Do(func(){
+		close(s.stop)
+		<- s.done
+	})
+}
+
+func (s *SessionsSweeper) sweep(){
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	num, err := s.repo.DeleteExpiredSessions(ctx, time.Now())
+	if err != nil{
+		log.Print("error sweeping expired sessions: ", err.Error())
+		return
+	}
+	if num > 0{
+		log.Printf("Deleted %d expired sessions", num)
+	}
+}
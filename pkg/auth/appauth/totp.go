@@ -0,0 +1,72 @@
+package appauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RFC 6238 codes, the same ones generated by the authenticator apps
+const TOTP_PERIOD = 30
+const TOTP_DIGITS = 6
+const TOTP_SECRET_SIZE = 20
+// accepted periods before and after the current one, for clock drifts
+const TOTP_SKEW = 1
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func generateTotpSecret() (string, error){
+	secret := make([]byte, TOTP_SECRET_SIZE)
+	if _, err := rand.Read(secret); err != nil{
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// url to register the secret in an authenticator app, usually shown as a QR
+func getTotpUrl(issuer string, account string, secret string) string{
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("digits", fmt.Sprint(TOTP_DIGITS))
+	values.Set("period", fmt.Sprint(TOTP_PERIOD))
+	label := url.PathEscape(issuer + ":" + account)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, values.Encode())
+}
+
+func validateTotpCode(secret string, code string, now time.Time) bool{
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != TOTP_DIGITS{
+		return false
+	}
+	counter := now.Unix() / TOTP_PERIOD
+	for i := -TOTP_SKEW; i <= TOTP_SKEW; i++{
+		expected := getTotpCode(key, uint64(counter + int64(i)))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1{
+			return true
+		}
+	}
+	return false
+}
+
+func getTotpCode(key []byte, counter uint64) string{
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < TOTP_DIGITS; i++{
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTP_DIGITS, value % modulo)
+}
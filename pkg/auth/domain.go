@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -17,17 +18,26 @@ type AccessClaims struct{
 
 type AdminAccessClaims struct{
 	SessionId 	uuid.UUID
+	AdminId 	uuid.UUID
+	Role 		string
+	Permissions []string
 	jwt.RegisteredClaims
 }
 
+type OAuthIdentity struct{
+	Subject 	string
+	Email 		*string
+}
+
 
 type AuthService interface {
 	RegisterUserAuth(ctx context.Context, email, password, device, os, token string) (refreshToken string, err error)
 	LoginUserAuth(ctx context.Context, email string, password string, device string, os string) (refreshToken string, err error)
 	CloseUsersSession(ctx context.Context, token string) (error)
-	CreateTempCouple(ctx context.Context, token string, startDate int) (int, error)
+	CreateTempCouple(ctx context.Context, token string, startDate int) (*TempCoupleModel, error)
 	CreateUser(ctx context.Context, token, firstName, lastName, gender, countryCode, languageCode string,birthDate int,) (refrshToken string, err error)
 	ConnectCouple(ctx context.Context, token string, code int) (accessToken string, err error)
+	ConnectCoupleByInvite(ctx context.Context, token string, inviteToken string) (accessToken string, err error)
 	CheckUserAuthStatus(ctx context.Context, token string) (string, error)
 	CreateAccessToken(ctx context.Context, token string)(string, *string, error)
 	ValidateAccessToken(ctx context.Context, accessTokenString string) (*AccessClaims, error)
@@ -35,11 +45,41 @@ type AuthService interface {
 	LogoutSession(ctx context.Context, sessionId uuid.UUID) error
 	SuscribeTempCoupleNot(ctx context.Context, token string)(chan string, *uuid.UUID, error)
 	RemoveCodeSuscriber(userId uuid.UUID)
+	DisconnectCouple(ctx context.Context, userId uuid.UUID) error
+	SuscribeCoupleNot(ctx context.Context, userId uuid.UUID)(chan string, error)
+	RemoveCoupleSuscriber(userId uuid.UUID)
+	OAuthLogin(ctx context.Context, provider, idToken, device, os, token string) (refreshToken string, err error)
+	SendEmailVerification(ctx context.Context, token string) error
+	VerifyEmail(ctx context.Context, verificationToken string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, resetToken string, password string) error
+	GetAccountSessions(ctx context.Context, currentSessionId uuid.UUID) ([]DeviceSessionModel, error)
+	RevokeSession(ctx context.Context, currentSessionId uuid.UUID, sessionId uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, currentSessionId uuid.UUID) error
+}
+
+// signs and verifies our own JWTs
+type TokenSigner interface{
+	Sign(claims jwt.Claims) (string, error)
+	Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error)
+	GetPublicKeys() JWKSModel
+}
+
+// verifies the ID tokens issued by an OAuth provider
+type OAuthVerifier interface{
+	VerifyIdToken(ctx context.Context, idToken string) (*OAuthIdentity, error)
 }
 
 type AuthAdminService interface{
 	ValidateAccessToken(ctx context.Context, accessTokenString string) (*AdminAccessClaims, error)
 	CreateAccessToken(ctx context.Context, token string)(string, error)
+	LoginAdmin(ctx context.Context, email, password, totpCode string) (refreshToken string, err error)
+	LogoutAdmin(ctx context.Context, sessionId uuid.UUID) error
+	CreateAdmin(ctx context.Context, email, password, role string, permissions []string) (*uuid.UUID, error)
+	UpdateAdminRole(ctx context.Context, currentAdminId uuid.UUID, adminId uuid.UUID, role string, permissions []string) error
+	BootstrapAdmin(ctx context.Context, email, password string) error
+	SetupTotp(ctx context.Context, adminId uuid.UUID) (secret string, url string, err error)
+	EnableTotp(ctx context.Context, adminId uuid.UUID, code string) error
 }
 
 type AuthRepository interface {
@@ -48,14 +88,36 @@ type AuthRepository interface {
 	CreateSession(ctx context.Context, sessionModel *SessionModel) (int, error)
 	GetUserByEmail(ctx context.Context, email string) (*UserAuthModel, error)
 	GetUserById(ctx context.Context, id uuid.UUID) (*UserAuthModel, error)
-	GetSessionByToken(ctx context.Context, token string) (*SessionModel, error)
+	GetUserByOAuth(ctx context.Context, provider string, oauthId string) (*UserAuthModel, error)
+	CreateOAuthUserAuth(ctx context.Context, id uuid.UUID, provider string, oauthId string) (int, error)
+	GetSessionByToken(ctx context.Context, tokenHash string) (*SessionModel, error)
 	GetSessionById(ctx context.Context, id uuid.UUID) (*SessionModel, error)
 	UpdateAuthUserId(ctx context.Context, authId uuid.UUID, userId uuid.UUID) (int, error)
 	DeleteSessionById(ctx context.Context, sessionId uuid.UUID) (int, error)
 	DeleteUserAuthById(ctx context.Context, authId uuid.UUID) (int, error)
 	UpdateAuthUserById(ctx context.Context, authId uuid.UUID, authModel *UserAuthModel) (int, error)
 	UpdateSessionLastUsed(ctx context.Context, sessionId uuid.UUID, lastTime time.Time) (int, error)
-	GetAdminSessionByToken(ctx context.Context, token string)(*AdminSessionModel, error)
+	GetAdminSessionByToken(ctx context.Context, tokenHash string)(*AdminSessionModel, error)
+	GetAdminSessionById(ctx context.Context, id uuid.UUID) (*AdminSessionModel, error)
+	CreateAdminSession(ctx context.Context, session *AdminSessionModel) (int, error)
+	DeleteAdminSessionById(ctx context.Context, id uuid.UUID) (int, error)
+	CreateAdmin(ctx context.Context, admin *AdminModel) (int, error)
+	GetAdminByEmail(ctx context.Context, email string) (*AdminModel, error)
+	GetAdminById(ctx context.Context, id uuid.UUID) (*AdminModel, error)
+	UpdateAdminTotp(ctx context.Context, adminId uuid.UUID, secret *string, enabled bool) (int, error)
+	UpdateAdminRole(ctx context.Context, adminId uuid.UUID, role string, permissions []string) (int, error)
+	DeleteSessionsByAuthId(ctx context.Context, authId uuid.UUID) (int, error)
+	GetActiveSessionsByAuthId(ctx context.Context, authId uuid.UUID) ([]SessionModel, error)
+	DeleteSessionsByAuthIdExcept(ctx context.Context, authId uuid.UUID, sessionId uuid.UUID) (int, error)
+	DeleteExpiredSessions(ctx context.Context, before time.Time) (int, error)
+	UpdateSessionToken(ctx context.Context, sessionId uuid.UUID, oldTokenHash string, newTokenHash string, expiresAt time.Time, lastUsed time.Time) (int, error)
+	CreateRotatedToken(ctx context.Context, rotated *RotatedTokenModel) (int, error)
+	GetRotatedToken(ctx context.Context, tokenHash string) (*RotatedTokenModel, error)
+	UpdateEmailVerifiedAt(ctx context.Context, authId uuid.UUID, verifiedAt time.Time) (int, error)
+	CreateAuthToken(ctx context.Context, token *AuthTokenModel) (int, error)
+	GetAuthTokenByHash(ctx context.Context, tokenHash string, purpose string) (*AuthTokenModel, error)
+	UseAuthToken(ctx context.Context, id uuid.UUID, usedAt time.Time) (int, error)
+	InvalidateAuthTokens(ctx context.Context, authId uuid.UUID, purpose string) (int, error)
 }
 
 
@@ -66,4 +128,65 @@ const (
 	StatusCoupleCreated = "COUPLE_CREATED"
 	StatusPartnerWithoutNickname = "PARTNER_WITHOUT_NICKNAME"
 	StatusVinculated = "PARTNER_VINCULATED"
-)
\ No newline at end of file
+	StatusDisconnected = "PARTNER_DISCONNECTED"
+	StatusCodeExpired = "CODE_EXPIRED"
+)
+
+///// oauth providers
+const (
+	OAUTH_GOOGLE = "google"
+	OAUTH_APPLE = "apple"
+)
+
+///// mail tokens purposes
+const (
+	TOKEN_PURPOSE_EMAIL_VERIFICATION = "EMAIL_VERIFICATION"
+	TOKEN_PURPOSE_PASSWORD_RESET = "PASSWORD_RESET"
+)
+
+// bus channel of the code and couple notifications
+const NOTIFICATIONS_CHANNEL = "auth_notifications"
+
+const EMAIL_VERIFICATION_TOKEN_LIFE = 24 * time.Hour
+const PASSWORD_RESET_TOKEN_LIFE = time.Hour
+
+///// admin permissions
+const (
+	PERMISSION_CONTENT_READ = "content:read"
+	PERMISSION_CATEGORIES_WRITE = "categories:write"
+	PERMISSION_CATEGORIES_DELETE = "categories:delete"
+	PERMISSION_QUIZZES_WRITE = "quizzes:write"
+	PERMISSION_QUIZZES_MODERATE = "quizzes:moderate"
+	PERMISSION_USERS_MANAGE = "users:manage"
+	PERMISSION_ADMINS_MANAGE = "admins:manage"
+)
+
+///// admin roles
+const (
+	ROLE_SUPER_ADMIN = "SUPER_ADMIN"
+	ROLE_CONTENT_EDITOR = "CONTENT_EDITOR"
+	ROLE_MODERATOR = "MODERATOR"
+	ROLE_SUPPORT = "SUPPORT"
+)
+
+// permissions granted by each role, an admin can also have extra permissions of its own
+var RolesPermissions = map[string][]string{
+	ROLE_SUPER_ADMIN : {
+		PERMISSION_CONTENT_READ, PERMISSION_CATEGORIES_WRITE, PERMISSION_CATEGORIES_DELETE, PERMISSION_QUIZZES_WRITE,
+		PERMISSION_QUIZZES_MODERATE, PERMISSION_USERS_MANAGE, PERMISSION_ADMINS_MANAGE,
+	},
+	ROLE_CONTENT_EDITOR : {PERMISSION_CONTENT_READ, PERMISSION_CATEGORIES_WRITE, PERMISSION_CATEGORIES_DELETE, PERMISSION_QUIZZES_WRITE},
+	ROLE_MODERATOR : {PERMISSION_CONTENT_READ, PERMISSION_QUIZZES_MODERATE},
+	ROLE_SUPPORT : {PERMISSION_CONTENT_READ, PERMISSION_USERS_MANAGE},
+}
+
+///// rate limit policies, the IP ones are applied by the API before reaching the services
+var (
+	LOGIN_IP_POLICY = ratelimit.PolicyModel{Name: "login_ip", MaxAttempts: 20, Window: 15*time.Minute, Lockout: 15*time.Minute}
+	LOGIN_ACCOUNT_POLICY = ratelimit.PolicyModel{Name: "login_account", MaxAttempts: 5, Window: 15*time.Minute, Lockout: 15*time.Minute}
+	ADMIN_LOGIN_IP_POLICY = ratelimit.PolicyModel{Name: "admin_login_ip", MaxAttempts: 10, Window: 15*time.Minute, Lockout: 30*time.Minute}
+	ADMIN_LOGIN_ACCOUNT_POLICY = ratelimit.PolicyModel{Name: "admin_login_account", MaxAttempts: 5, Window: 15*time.Minute, Lockout: 30*time.Minute}
+	COUPLE_CODE_IP_POLICY = ratelimit.PolicyModel{Name: "couple_code_ip", MaxAttempts: 20, Window: 15*time.Minute, Lockout: 30*time.Minute}
+	COUPLE_CODE_SESSION_POLICY = ratelimit.PolicyModel{Name: "couple_code_session", MaxAttempts: 5, Window: 15*time.Minute, Lockout: 30*time.Minute}
+	REFRESH_IP_POLICY = ratelimit.PolicyModel{Name: "refresh_ip", MaxAttempts: 60, Window: time.Minute, Lockout: 5*time.Minute}
+)
@@ -33,4 +33,37 @@ var (
 	ErrNoCodeToSuscribe = errors.New("USER_HAS_NO_CODE_TO_SUSCRIBE")
 	ErrNonExistingCode = errors.New("NON_EXISTING_VINCULATION_CODE")
 	ErrCantConnectWithYourself = errors.New("CANT_CONNECT_WITH_YOURSELF") 
+	ErrorCoupleEnded = errors.New("COUPLE_ENDED")
+	ErrorCheckingCouple = errors.New("UNABLE_TO_CHECK_COUPLE")
+	ErrorDisconnectingCouple = errors.New("UNABLE_TO_DISCONNECT_COUPLE")
+	ErrUnsupportedOAuthProvider = errors.New("UNSUPPORTED_OAUTH_PROVIDER")
+	ErrInvalidOAuthToken = errors.New("INVALID_OAUTH_TOKEN")
+	ErrorGettingOAuthKeys = errors.New("UNABLE_TO_GET_OAUTH_KEYS")
+	ErrorWithOAuthLogin = errors.New("UNABLE_TO_LOGIN_WITH_OAUTH")
+	ErrorSendingEmail = errors.New("UNABLE_TO_SEND_EMAIL")
+	ErrAccountWithoutEmail = errors.New("ACCOUNT_HAS_NO_EMAIL")
+	ErrEmailAlreadyVerified = errors.New("EMAIL_ALREADY_VERIFIED")
+	ErrInvalidMailToken = errors.New("INVALID_OR_EXPIRED_TOKEN")
+	ErrorVerifyingEmail = errors.New("UNABLE_TO_VERIFY_EMAIL")
+	ErrorResettingPassword = errors.New("UNABLE_TO_RESET_PASSWORD")
+	ErrorSessionRevoked = errors.New("SESSION_REVOKED")
+	ErrorGettingSessions = errors.New("UNABLE_TO_GET_SESSIONS")
+	ErrorRevokingSession = errors.New("UNABLE_TO_REVOKE_SESSION")
+	ErrorRefreshTokenReused = errors.New("REFRESH_TOKEN_REUSED")
+	ErrInvalidAdminCredentials = errors.New("INVALID_ADMIN_CREDENTIALS")
+	ErrTotpCodeRequired = errors.New("TOTP_CODE_REQUIRED")
+	ErrInvalidTotpCode = errors.New("INVALID_TOTP_CODE")
+	ErrTotpNotSetUp = errors.New("TOTP_NOT_SET_UP")
+	ErrTotpAlreadyEnabled = errors.New("TOTP_ALREADY_ENABLED")
+	ErrorSettingUpTotp = errors.New("UNABLE_TO_SET_UP_TOTP")
+	ErrorAdminLogin = errors.New("UNABLE_TO_LOGIN_ADMIN")
+	ErrorCreatingAdmin = errors.New("UNABLE_TO_CREATE_ADMIN")
+	ErrAdminEmailAlreadyUsed = errors.New("ADMIN_EMAIL_ALREADY_USED")
+	ErrAdminNotFound = errors.New("ADMIN_NOT_FOUND")
+	ErrInvalidAdminRole = errors.New("INVALID_ADMIN_ROLE")
+	ErrInvalidAdminPermission = errors.New("INVALID_ADMIN_PERMISSION")
+	ErrCantChangeOwnRole = errors.New("CANT_CHANGE_OWN_ROLE")
+	ErrorUpdatingAdminRole = errors.New("UNABLE_TO_UPDATE_ADMIN_ROLE")
+	ErrTooManyAttempts = errors.New("TOO_MANY_ATTEMPTS")
+	ErrExpiredCode = errors.New("EXPIRED_CODE")
 )
\ No newline at end of file
@@ -14,6 +14,7 @@ type UserAuthModel struct {
 	OauthId			*string
 	CreatedAt		time.Time
 	UserId 			*uuid.UUID
+	EmailVerifiedAt *time.Time
 }
 
 type SessionModel struct {
@@ -27,13 +28,79 @@ type SessionModel struct {
 	UserAuthId 	uuid.UUID
 }
 
+// session as it is shown to the user, without its token
+type DeviceSessionModel struct{
+	Id 			uuid.UUID 	`json:"id"`
+	Device 		*string 	`json:"device"`
+	Os 			*string 	`json:"os"`
+	CreatedAt 	time.Time 	`json:"createdAt"`
+	LastUsed 	time.Time 	`json:"lastUsed"`
+	ExpiresAt 	time.Time 	`json:"expiresAt"`
+	Current 	bool 		`json:"current"`
+}
+
+// single use tokens sent by mail, only the hash of the token is stored
+type AuthTokenModel struct{
+	Id 			uuid.UUID
+	TokenHash 	string
+	Purpose 	string
+	ExpiresAt 	time.Time
+	UsedAt 		*time.Time
+	CreatedAt 	time.Time
+	UserAuthId 	uuid.UUID
+}
+
+// previous tokens of a session, kept to detect when they are used again
+type RotatedTokenModel struct{
+	TokenHash 	string
+	SessionId 	uuid.UUID
+	RotatedAt 	time.Time
+}
+
+type AdminModel struct{
+	Id 				uuid.UUID
+	Email 			string
+	Hash 			string
+	TotpSecret 		*string
+	TotpEnabled 	bool
+	CreatedAt 		time.Time
+	Role 			string
+	Permissions 	[]string
+}
+
 type AdminSessionModel struct{
 	Id			uuid.UUID
 	Token 		string 
 	CreatedAt 	time.Time
+	ExpiresAt 	time.Time
+	AdminId 	uuid.UUID
+}
+
+type JWKModel struct{
+	Kty 	string 	`json:"kty"`
+	Kid 	string 	`json:"kid"`
+	Use 	string 	`json:"use"`
+	Alg 	string 	`json:"alg"`
+	N 		string 	`json:"n,omitempty"`
+	E 		string 	`json:"e,omitempty"`
+	Crv 	string 	`json:"crv,omitempty"`
+	X 		string 	`json:"x,omitempty"`
 }
 
+type JWKSModel struct{
+	Keys 	[]JWKModel 	`json:"keys"`
+}
+
+// the invite link is only known when the code is created, its token is stored hashed
 type TempCoupleModel struct{
 	Code 		int 		`json:"code"`
 	StartDate 	time.Time 	`json:"startDate"`
-}
\ No newline at end of file
+	ExpiresAt 	time.Time 	`json:"expiresAt"`
+	QrUrl 		*string 	`json:"qrUrl"`
+	InviteLink 	*string 	`json:"inviteLink,omitempty"`
+}
+// sent through the event bus, the instance where the user is suscribed delivers the status
+type NotificationModel struct{
+	UserId 		uuid.UUID 	`json:"userId"`
+	Status 		string 		`json:"status"`
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/diegobermudez03/couples-backend/pkg/auth"
 	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type AuthPostgresRepo struct {
@@ -48,19 +49,19 @@ func (r *AuthPostgresRepo) CreateSession(ctx context.Context,  sessionModel *aut
 func (r *AuthPostgresRepo) GetUserByEmail(ctx context.Context, email string) (*auth.UserAuthModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, email, hash, oauth_provider, oauth_id, created_at, user_id
+		`SELECT id, email, hash, oauth_provider, oauth_id, created_at, user_id, email_verified_at
 		FROM users_auth WHERE email = $1`,
 		email,
 	)
 	return r.readUser(row)
 }
 
-func (r *AuthPostgresRepo) GetSessionByToken(ctx context.Context, token string) (*auth.SessionModel, error){
+func (r *AuthPostgresRepo) GetSessionByToken(ctx context.Context, tokenHash string) (*auth.SessionModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
 		`SELECT id, token, device, os, expires_at, created_at, last_used, user_auth_id
 		FROM sessions WHERE token = $1`,
-		token,
+		tokenHash,
 	)
 	model := new(auth.SessionModel)
 
@@ -78,13 +79,33 @@ func (r *AuthPostgresRepo) GetSessionByToken(ctx context.Context, token string)
 func (r *AuthPostgresRepo) GetUserById(ctx context.Context, id uuid.UUID) (*auth.UserAuthModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, email, hash, oauth_provider, oauth_id, created_at, user_id
+		`SELECT id, email, hash, oauth_provider, oauth_id, created_at, user_id, email_verified_at
 		FROM users_auth WHERE id = $1`,
 		id,
 	)
 	return r.readUser(row)
 }
 
+func (r *AuthPostgresRepo) GetUserByOAuth(ctx context.Context, provider string, oauthId string) (*auth.UserAuthModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, email, hash, oauth_provider, oauth_id, created_at, user_id, email_verified_at
+		FROM users_auth WHERE oauth_provider = $1 AND oauth_id = $2`,
+		provider, oauthId,
+	)
+	return r.readUser(row)
+}
+
+func (r *AuthPostgresRepo) CreateOAuthUserAuth(ctx context.Context, id uuid.UUID, provider string, oauthId string) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO users_auth(id, oauth_provider, oauth_id, created_at) VALUES($1, $2, $3, $4)`,
+			id, provider, oauthId, time.Now(),
+		)
+	})
+}
+
 func (r *AuthPostgresRepo) CreateEmptyUser(ctx context.Context, id uuid.UUID, userId uuid.UUID) (int, error) {
 	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
 		return ex.ExecContext(
@@ -166,30 +187,257 @@ func (r *AuthPostgresRepo)  UpdateSessionLastUsed(ctx context.Context, sessionId
 }
 
 
-func (r *AuthPostgresRepo)  GetAdminSessionByToken(ctx context.Context, token string)(*auth.AdminSessionModel, error){
+func (r *AuthPostgresRepo)  GetAdminSessionByToken(ctx context.Context, tokenHash string)(*auth.AdminSessionModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, token, created_at
+		`SELECT id, token, created_at, expires_at, admin_id
 		FROM admin_sessions WHERE token = $1`,
-		token,
+		tokenHash,
 	)
-	model := new(auth.AdminSessionModel)
+	return r.readAdminSession(row)
+}
+
+func (r *AuthPostgresRepo) GetAdminSessionById(ctx context.Context, id uuid.UUID) (*auth.AdminSessionModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, token, created_at, expires_at, admin_id
+		FROM admin_sessions WHERE id = $1`,
+		id,
+	)
+	return r.readAdminSession(row)
+}
+
+func (r *AuthPostgresRepo) CreateAdminSession(ctx context.Context, session *auth.AdminSessionModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO admin_sessions(id, token, created_at, expires_at, admin_id)
+			VALUES($1, $2, $3, $4, $5)`,
+			session.Id, session.Token, session.CreatedAt, session.ExpiresAt, session.AdminId,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) DeleteAdminSessionById(ctx context.Context, id uuid.UUID) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`DELETE FROM admin_sessions WHERE id = $1`,
+			id,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) CreateAdmin(ctx context.Context, admin *auth.AdminModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO admins(id, email, hash, totp_secret, totp_enabled, created_at, role, permissions)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8)`,
+			admin.Id, admin.Email, admin.Hash, admin.TotpSecret, admin.TotpEnabled, admin.CreatedAt, admin.Role, pq.Array(admin.Permissions),
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) GetAdminByEmail(ctx context.Context, email string) (*auth.AdminModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, email, hash, totp_secret, totp_enabled, created_at, role, permissions
+		FROM admins WHERE email = $1`,
+		email,
+	)
+	return r.readAdmin(row)
+}
+
+func (r *AuthPostgresRepo) GetAdminById(ctx context.Context, id uuid.UUID) (*auth.AdminModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, email, hash, totp_secret, totp_enabled, created_at, role, permissions
+		FROM admins WHERE id = $1`,
+		id,
+	)
+	return r.readAdmin(row)
+}
+
+func (r *AuthPostgresRepo) UpdateAdminTotp(ctx context.Context, adminId uuid.UUID, secret *string, enabled bool) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE admins SET totp_secret = $1, totp_enabled = $2 WHERE id = $3`,
+			secret, enabled, adminId,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) UpdateAdminRole(ctx context.Context, adminId uuid.UUID, role string, permissions []string) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE admins SET role = $1, permissions = $2 WHERE id = $3`,
+			role, pq.Array(permissions), adminId,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) DeleteSessionsByAuthId(ctx context.Context, authId uuid.UUID) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`DELETE FROM sessions WHERE user_auth_id = $1`, 
+			authId,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) GetActiveSessionsByAuthId(ctx context.Context, authId uuid.UUID) ([]auth.SessionModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, token, device, os, expires_at, created_at, last_used, user_auth_id
+		FROM sessions WHERE user_auth_id = $1 AND expires_at > $2
+		ORDER BY last_used DESC`,
+		authId, time.Now(),
+	)
+	if err != nil{
+		log.Print("error getting sessions: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+	sessions := []auth.SessionModel{}
+	for rows.Next(){
+		model := auth.SessionModel{}
+		if err := rows.Scan(&model.Id, &model.Token, &model.Device, &model.Os, &model.ExpiresAt, &model.CreatedAt, &model.LastUsed, &model.UserAuthId); err != nil{
+			log.Print("error reading session: ", err.Error())
+			return nil, err
+		}
+		sessions = append(sessions, model)
+	}
+	return sessions, nil
+}
+
+func (r *AuthPostgresRepo) DeleteSessionsByAuthIdExcept(ctx context.Context, authId uuid.UUID, sessionId uuid.UUID) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`DELETE FROM sessions WHERE user_auth_id = $1 AND id <> $2`, 
+			authId, sessionId,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) DeleteExpiredSessions(ctx context.Context, before time.Time) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`DELETE FROM sessions WHERE expires_at < $1`, 
+			before,
+		)
+	})
+}
+
+// only updates if the session still has the old token, so a token can't be rotated twice
+func (r *AuthPostgresRepo) UpdateSessionToken(ctx context.Context, sessionId uuid.UUID, oldTokenHash string, newTokenHash string, expiresAt time.Time, lastUsed time.Time) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE sessions SET token = $1, expires_at = $2, last_used = $3 
+			WHERE id = $4 AND token = $5`,
+			newTokenHash, expiresAt, lastUsed, sessionId, oldTokenHash,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) CreateRotatedToken(ctx context.Context, rotated *auth.RotatedTokenModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO rotated_tokens(token_hash, session_id, rotated_at) VALUES($1, $2, $3)`,
+			rotated.TokenHash, rotated.SessionId, rotated.RotatedAt,
+		)
+	})
+}
 
-	err := row.Scan(&model.Id, &model.Token, &model.CreatedAt)
+func (r *AuthPostgresRepo) GetRotatedToken(ctx context.Context, tokenHash string) (*auth.RotatedTokenModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT token_hash, session_id, rotated_at FROM rotated_tokens WHERE token_hash = $1`,
+		tokenHash,
+	)
+	model := new(auth.RotatedTokenModel)
+	err := row.Scan(&model.TokenHash, &model.SessionId, &model.RotatedAt)
 	if errors.Is(err, sql.ErrNoRows){
 		return nil, nil
 	}else if err != nil{
-		log.Print("error getting session : ", err.Error())
+		log.Print("error getting rotated token: ", err.Error())
 		return nil, err
 	}
 	return model, nil
 }
 
+func (r *AuthPostgresRepo) UpdateEmailVerifiedAt(ctx context.Context, authId uuid.UUID, verifiedAt time.Time) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE users_auth SET email_verified_at = $1 WHERE id = $2`,
+			verifiedAt, authId,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) CreateAuthToken(ctx context.Context, token *auth.AuthTokenModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO auth_tokens(id, token_hash, purpose, expires_at, used_at, created_at, user_auth_id)
+			VALUES($1, $2, $3, $4, $5, $6, $7)`,
+			token.Id, token.TokenHash, token.Purpose, token.ExpiresAt, token.UsedAt, token.CreatedAt, token.UserAuthId,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) GetAuthTokenByHash(ctx context.Context, tokenHash string, purpose string) (*auth.AuthTokenModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, token_hash, purpose, expires_at, used_at, created_at, user_auth_id
+		FROM auth_tokens WHERE token_hash = $1 AND purpose = $2`,
+		tokenHash, purpose,
+	)
+	model := new(auth.AuthTokenModel)
+	err := row.Scan(&model.Id, &model.TokenHash, &model.Purpose, &model.ExpiresAt, &model.UsedAt, &model.CreatedAt, &model.UserAuthId)
+	if errors.Is(err, sql.ErrNoRows){
+		return nil, nil
+	}else if err != nil{
+		log.Print("error getting auth token: ", err.Error())
+		return nil, err
+	}
+	return model, nil
+}
+
+// only marks the token if it wasn't used before, so a token can't be consumed twice
+func (r *AuthPostgresRepo) UseAuthToken(ctx context.Context, id uuid.UUID, usedAt time.Time) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE auth_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`,
+			usedAt, id,
+		)
+	})
+}
+
+func (r *AuthPostgresRepo) InvalidateAuthTokens(ctx context.Context, authId uuid.UUID, purpose string) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE auth_tokens SET used_at = $1 WHERE user_auth_id = $2 AND purpose = $3 AND used_at IS NULL`,
+			time.Now(), authId, purpose,
+		)
+	})
+}
+
 ///////////////////// HELPERS
 
 func (r *AuthPostgresRepo) readUser(row *sql.Row) (*auth.UserAuthModel, error){
 	model := new(auth.UserAuthModel)
-	err := row.Scan(&model.Id, &model.Email, &model.Hash, &model.OauthProvider, &model.OauthId, &model.CreatedAt, &model.UserId)
+	err := row.Scan(&model.Id, &model.Email, &model.Hash, &model.OauthProvider, &model.OauthId, &model.CreatedAt, &model.UserId, &model.EmailVerifiedAt)
 	if err == sql.ErrNoRows{
 		return nil, nil
 	}
@@ -199,4 +447,27 @@ func (r *AuthPostgresRepo) readUser(row *sql.Row) (*auth.UserAuthModel, error){
 	}
 	return model, nil
 }
-	
+
+func (r *AuthPostgresRepo) readAdminSession(row *sql.Row) (*auth.AdminSessionModel, error){
+	model := new(auth.AdminSessionModel)
+	err := row.Scan(&model.Id, &model.Token, &model.CreatedAt, &model.ExpiresAt, &model.AdminId)
+	if errors.Is(err, sql.ErrNoRows){
+		return nil, nil
+	}else if err != nil{
+		log.Print("error getting admin session: ", err.Error())
+		return nil, err
+	}
+	return model, nil
+}
+
+func (r *AuthPostgresRepo) readAdmin(row *sql.Row) (*auth.AdminModel, error){
+	model := new(auth.AdminModel)
+	err := row.Scan(&model.Id, &model.Email, &model.Hash, &model.TotpSecret, &model.TotpEnabled, &model.CreatedAt, &model.Role, pq.Array(&model.Permissions))
+	if errors.Is(err, sql.ErrNoRows){
+		return nil, nil
+	}else if err != nil{
+		log.Print("error getting admin: ", err.Error())
+		return nil, err
+	}
+	return model, nil
+}
@@ -0,0 +1,417 @@
+package appchallenges
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/challenges"
+	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+type ServiceImpl struct{
+	transactions 	infraestructure.Transaction
+	optionsService 	quizzes.QuestionOptionsService
+	usersService 	users.UsersService
+	pointsService 	users.PointsService
+	repo 			challenges.ChallengesRepository
+}
+
+func NewServiceImpl(
+	transactions infraestructure.Transaction,
+	optionsService quizzes.QuestionOptionsService,
+	usersService users.UsersService,
+	pointsService users.PointsService,
+	repo challenges.ChallengesRepository,
+	) challenges.Service{
+	return &ServiceImpl{
+		transactions: transactions,
+		optionsService: optionsService,
+		usersService: usersService,
+		pointsService: pointsService,
+		repo: repo,
+	}
+}
+
+
+// returns the published challenges and the ones created by the user
+func (s *ServiceImpl) GetChallenges(ctx context.Context, userId uuid.UUID) ([]challenges.ChallengeModel, error){
+	published := true
+	publishedChallenges, err := s.repo.GetChallenges(ctx, challenges.ChallengeFilter{Published: &published})
+	if err != nil{
+		return nil, challenges.ErrRetrievingChallenges
+	}
+	ownChallenges, err := s.repo.GetChallenges(ctx, challenges.ChallengeFilter{CreatorId: &userId})
+	if err != nil{
+		return nil, challenges.ErrRetrievingChallenges
+	}
+	added := map[uuid.UUID]bool{}
+	models := make([]challenges.ChallengeModel, 0, len(publishedChallenges) + len(ownChallenges))
+	for _, challenge := range append(ownChallenges, publishedChallenges...){
+		if added[challenge.Id]{
+			continue
+		}
+		added[challenge.Id] = true
+		models = append(models, challenges.ChallengeModel{
+			Id: challenge.Id,
+			Name: challenge.Name,
+			Description: challenge.Description,
+			Published: challenge.Published,
+			CreatedAt: challenge.CreatedAt,
+		})
+	}
+	return models, nil
+}
+
+
+func (s *ServiceImpl) CreateChallenge(ctx context.Context, userId uuid.UUID, name, description string) (*uuid.UUID, error){
+	if name == ""{
+		return nil, challenges.ErrEmptyChallengeName
+	}
+	challengeId := uuid.New()
+	model := challenges.ChallengePlainModel{
+		Id: challengeId,
+		Name: name,
+		Description: description,
+		Published: false,
+		CreatedAt: time.Now(),
+		CreatorId: userId,
+	}
+	if num, err := s.repo.CreateChallenge(ctx, &model); err != nil || num == 0{
+		return nil, challenges.ErrCreatingChallenge
+	}
+	return &challengeId, nil
+}
+
+
+func (s *ServiceImpl) PublishChallenge(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID) error{
+	challenge, err := s.getCreatorChallenge(ctx, challengeId, userId)
+	if err != nil{
+		return err
+	}
+	if challenge.Published{
+		return challenges.ErrChallengeAlreadyPublished
+	}
+	challenge.Published = true
+	if num, err := s.repo.UpdateChallenge(ctx, challenge); err != nil || num == 0{
+		return challenges.ErrUnableToPublish
+	}
+	return nil
+}
+
+
+func (s *ServiceImpl) CreateQuestion(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID, parameters challenges.CreateQuestionRequest, images map[string]io.Reader) (*uuid.UUID, error){
+	challenge, err := s.getCreatorChallenge(ctx, challengeId, userId)
+	if err != nil{
+		return nil, err
+	}
+	if challenge.Published{
+		return nil, challenges.ErrCantEditPublishedChallenge
+	}
+	if parameters.Question == ""{
+		return nil, challenges.ErrEmptyQuestion
+	}
+	if err := s.optionsService.ValidatePlaceholders(parameters.Question); err != nil{
+		return nil, err
+	}
+
+	questionId := uuid.New()
+	optionsJson, err := s.optionsService.CreateQuestionOptions(ctx, parameters.QType, challengeId, questionId, parameters.OptionsJson, images)
+	if err != nil{
+		return nil, err
+	}
+	maxOrder, err := s.repo.GetMaxOrderQuestion(ctx, challengeId)
+	if err != nil{
+		return nil, challenges.ErrCreatingQuestion
+	}
+	question := challenges.QuestionPlainModel{
+		Id: questionId,
+		Ordering: maxOrder + 1,
+		Question: parameters.Question,
+		QuestionType: parameters.QType,
+		OptionsJson: optionsJson,
+		ChallengeId: challengeId,
+	}
+	if num, err := s.repo.CreateQuestion(ctx, &question); err != nil || num == 0{
+		return nil, challenges.ErrCreatingQuestion
+	}
+	return &questionId, nil
+}
+
+
+func (s *ServiceImpl) DeleteQuestion(ctx context.Context, questionId uuid.UUID, userId uuid.UUID) error{
+	question, err := s.repo.GetQuestionById(ctx, questionId)
+	if err != nil{
+		return challenges.ErrDeletingQuestion
+	}else if question == nil{
+		return challenges.ErrQuestionNotFound
+	}
+	challenge, err := s.getCreatorChallenge(ctx, question.ChallengeId, userId)
+	if err != nil{
+		return err
+	}
+	if challenge.Published{
+		return challenges.ErrCantEditPublishedChallenge
+	}
+	return s.transactions.Do(ctx, func(ctx context.Context) error {
+		if err := s.optionsService.DeleteQuestionOptions(ctx, question.QuestionType, question.OptionsJson); err != nil{
+			return challenges.ErrDeletingQuestion
+		}
+		if num, err := s.repo.DeleteQuestion(ctx, questionId); err != nil || num == 0{
+			return challenges.ErrDeletingQuestion
+		}
+		return nil
+	})
+}
+
+
+// shared indicates that the challenge is played as a couple, so the score is added to the couple points
+func (s *ServiceImpl) StartChallenge(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID, shared bool) (*uuid.UUID, error){
+	challenge, err := s.repo.GetChallengeById(ctx, challengeId)
+	if err != nil{
+		return nil, challenges.ErrStartingChallenge
+	}else if challenge == nil{
+		return nil, challenges.ErrChallengeNotFound
+	}
+	if !challenge.Published{
+		return nil, challenges.ErrChallengeNotPublished
+	}
+
+	//if there's already a play from the user, we resume it, unless is completed
+	plays, err := s.repo.GetChallengesPlayed(ctx, challenges.ChallengePlayedFilter{ChallengeId: &challengeId, UserId: &userId})
+	if err != nil{
+		return nil, challenges.ErrStartingChallenge
+	}
+	for _, play := range plays{
+		if play.CompletedAt != nil{
+			return nil, challenges.ErrChallengeAlreadyPlayed
+		}
+		return &play.Id, nil
+	}
+
+	if shared{
+		if _, err := s.usersService.GetCoupleFromUser(ctx, userId); err != nil{
+			return nil, err
+		}
+	}
+	playId := uuid.New()
+	model := challenges.ChallengePlayedPlainModel{
+		Id: playId,
+		ChallengeId: challengeId,
+		UserId: userId,
+		Shared: shared,
+		StartedAt: time.Now(),
+	}
+	if num, err := s.repo.CreateChallengePlayed(ctx, &model); err != nil || num == 0{
+		return nil, challenges.ErrStartingChallenge
+	}
+	return &playId, nil
+}
+
+
+func (s *ServiceImpl) GetPlayQuestions(ctx context.Context, playId uuid.UUID, userId uuid.UUID) ([]quizzes.QuestionModel, error){
+	play, err := s.getUserPlay(ctx, playId, userId)
+	if err != nil{
+		return nil, err
+	}
+	questions, err := s.repo.GetQuestions(ctx, play.ChallengeId)
+	if err != nil{
+		return nil, challenges.ErrRetrievingQuestions
+	}
+	answered, err := s.getAnsweredQuestions(ctx, userId, play.ChallengeId)
+	if err != nil{
+		return nil, challenges.ErrRetrievingQuestions
+	}
+	models := make([]quizzes.QuestionModel, 0, len(questions))
+	for _, q := range questions{
+		models = append(models, quizzes.QuestionModel{
+			Id: q.Id,
+			Ordering: q.Ordering,
+			Question: q.Question,
+			QuestionType: q.QuestionType,
+			Options: json.RawMessage(q.OptionsJson),
+			Answered: answered[q.Id],
+		})
+	}
+	if err := s.optionsService.RenderQuestions(ctx, userId, models); err != nil{
+		return nil, challenges.ErrRetrievingQuestions
+	}
+	return models, nil
+}
+
+
+func (s *ServiceImpl) AnswerQuestion(ctx context.Context, playId uuid.UUID, questionId uuid.UUID, userId uuid.UUID, answer quizzes.AnswerRequest) error{
+	play, err := s.getUserPlay(ctx, playId, userId)
+	if err != nil{
+		return err
+	}
+	if play.CompletedAt != nil{
+		return challenges.ErrChallengePlayAlreadyCompleted
+	}
+	question, err := s.repo.GetQuestionById(ctx, questionId)
+	if err != nil{
+		return challenges.ErrAnsweringQuestion
+	}else if question == nil{
+		return challenges.ErrQuestionNotFound
+	}
+	if question.ChallengeId != play.ChallengeId{
+		return challenges.ErrQuestionNotInChallenge
+	}
+	answerJson, err := s.optionsService.ValidateAnswer(question.QuestionType, question.OptionsJson, answer)
+	if err != nil{
+		return err
+	}
+
+	// if the user already answered the question, the answer is replaced
+	previous, err := s.repo.GetUsersAnswers(ctx, challenges.UserAnswerFilter{QuestionId: &questionId, UserId: &userId})
+	if err != nil{
+		return challenges.ErrAnsweringQuestion
+	}
+	var num int
+	if len(previous) > 0{
+		model := previous[0]
+		model.Answers = answerJson
+		model.AnsweredAt = time.Now()
+		num, err = s.repo.UpdateUserAnswer(ctx, &model)
+	}else{
+		num, err = s.repo.CreateUserAnswer(ctx, &challenges.UserAnswerPlainModel{
+			Id: uuid.New(),
+			UserId: userId,
+			QuestionId: questionId,
+			Answers: answerJson,
+			AnsweredAt: time.Now(),
+		})
+	}
+	if err != nil || num == 0{
+		return challenges.ErrAnsweringQuestion
+	}
+	return nil
+}
+
+
+func (s *ServiceImpl) CompleteChallenge(ctx context.Context, playId uuid.UUID, userId uuid.UUID) (*challenges.ChallengePlayedModel, error){
+	play, err := s.getUserPlay(ctx, playId, userId)
+	if err != nil{
+		return nil, err
+	}
+	if play.CompletedAt != nil{
+		return nil, challenges.ErrChallengePlayAlreadyCompleted
+	}
+	questions, err := s.repo.GetQuestions(ctx, play.ChallengeId)
+	if err != nil{
+		return nil, challenges.ErrCompletingChallenge
+	}
+	answers, err := s.repo.GetUserAnswersFromChallenge(ctx, userId, play.ChallengeId)
+	if err != nil{
+		return nil, challenges.ErrCompletingChallenge
+	}
+	answersByQuestion := make(map[uuid.UUID]string, len(answers))
+	for _, answer := range answers{
+		answersByQuestion[answer.QuestionId] = answer.Answers
+	}
+	for _, q := range questions{
+		if _, ok := answersByQuestion[q.Id]; !ok{
+			return nil, challenges.ErrMissingAnswers
+		}
+	}
+
+	score, won, err := s.scorePlay(questions, answersByQuestion)
+	if err != nil{
+		return nil, err
+	}
+	completedAt := time.Now()
+	play.Score = &score
+	play.CompletedAt = &completedAt
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		if num, err := s.repo.CompleteChallengePlayed(ctx, play); err != nil{
+			return challenges.ErrCompletingChallenge
+		}else if num == 0{
+			return challenges.ErrChallengePlayAlreadyCompleted
+		}
+		if play.Shared && won{
+			if err := s.pointsService.AwardPoints(ctx, userId, score, users.POINTS_REASON_CHALLENGE_WON, &play.Id); err != nil{
+				return challenges.ErrCompletingChallenge
+			}
+		}
+		return nil
+	})
+	if err != nil{
+		return nil, err
+	}
+	return &challenges.ChallengePlayedModel{
+		Id: play.Id,
+		ChallengeId: play.ChallengeId,
+		Shared: play.Shared,
+		Score: play.Score,
+		Won: won,
+		StartedAt: play.StartedAt,
+		CompletedAt: play.CompletedAt,
+	}, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+// returns the challenge only if it was created by the user
+func (s *ServiceImpl) getCreatorChallenge(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID) (*challenges.ChallengePlainModel, error){
+	challenge, err := s.repo.GetChallengeById(ctx, challengeId)
+	if err != nil{
+		return nil, challenges.ErrRetrievingChallenges
+	}else if challenge == nil{
+		return nil, challenges.ErrChallengeNotFound
+	}
+	if challenge.CreatorId != userId{
+		return nil, challenges.ErrUnathorizedToEditChallenge
+	}
+	return challenge, nil
+}
+
+// returns the play only if it belongs to the user
+func (s *ServiceImpl) getUserPlay(ctx context.Context, playId uuid.UUID, userId uuid.UUID) (*challenges.ChallengePlayedPlainModel, error){
+	play, err := s.repo.GetChallengePlayedById(ctx, playId)
+	if err != nil{
+		return nil, challenges.ErrRetrievingChallenges
+	}
+	if play == nil || play.UserId != userId{
+		return nil, challenges.ErrChallengePlayNotFound
+	}
+	return play, nil
+}
+
+// every answer gives points, and the ones with answer key give more the more right they are. The
+// challenge is won when enough of the questions with answer key are right
+func (s *ServiceImpl) scorePlay(questions []challenges.QuestionPlainModel, answers map[uuid.UUID]string) (int, bool, error){
+	score, keyed := 0, 0
+	right := 0.0
+	for _, q := range questions{
+		score += challenges.POINTS_PER_ANSWERED_QUESTION
+		rightness, err := s.optionsService.ScoreAnswer(q.QuestionType, q.OptionsJson, answers[q.Id])
+		if err != nil{
+			return 0, false, challenges.ErrCompletingChallenge
+		}else if rightness == nil{
+			continue
+		}
+		keyed++
+		right += *rightness
+		score += int(math.Round(*rightness * challenges.POINTS_PER_RIGHT_ANSWER))
+	}
+	won := keyed > 0 && right / float64(keyed) >= challenges.MIN_RIGHT_RATIO_TO_WIN
+	return score, won, nil
+}
+
+func (s *ServiceImpl) getAnsweredQuestions(ctx context.Context, userId uuid.UUID, challengeId uuid.UUID) (map[uuid.UUID]bool, error){
+	answers, err := s.repo.GetUserAnswersFromChallenge(ctx, userId, challengeId)
+	if err != nil{
+		return nil, err
+	}
+	answered := make(map[uuid.UUID]bool, len(answers))
+	for _, answer := range answers{
+		answered[answer.QuestionId] = true
+	}
+	return answered, nil
+}
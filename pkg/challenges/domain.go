@@ -0,0 +1,41 @@
+package challenges
+
+import (
+	"context"
+	"io"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/google/uuid"
+)
+
+type Service interface{
+	GetChallenges(ctx context.Context, userId uuid.UUID) ([]ChallengeModel, error)
+	CreateChallenge(ctx context.Context, userId uuid.UUID, name, description string) (*uuid.UUID, error)
+	PublishChallenge(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID) error
+
+	CreateQuestion(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID, parameters CreateQuestionRequest, images map[string]io.Reader) (*uuid.UUID, error)
+	DeleteQuestion(ctx context.Context, questionId uuid.UUID, userId uuid.UUID) error
+
+	StartChallenge(ctx context.Context, challengeId uuid.UUID, userId uuid.UUID, shared bool) (*uuid.UUID, error)
+	GetPlayQuestions(ctx context.Context, playId uuid.UUID, userId uuid.UUID) ([]quizzes.QuestionModel, error)
+	AnswerQuestion(ctx context.Context, playId uuid.UUID, questionId uuid.UUID, userId uuid.UUID, answer quizzes.AnswerRequest) error
+	CompleteChallenge(ctx context.Context, playId uuid.UUID, userId uuid.UUID) (*ChallengePlayedModel, error)
+}
+
+
+type CreateQuestionRequest struct{
+	Question 	string 
+	QType 		string 
+	OptionsJson	map[string]any 
+}
+
+
+const DOMAIN_NAME = "challenges"
+
+//POINTS
+const POINTS_PER_ANSWERED_QUESTION = 15
+
+const POINTS_PER_RIGHT_ANSWER = 15
+
+//the share of the questions with answer key that must be right to win the challenge
+const MIN_RIGHT_RATIO_TO_WIN = 0.5
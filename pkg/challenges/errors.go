@@ -0,0 +1,28 @@
+package challenges
+
+import "errors"
+
+var (
+	ErrEmptyChallengeName = errors.New("EMPTY_CHALLENGE_NAME")
+	ErrCreatingChallenge = errors.New("UNABLE_TO_CREATE_CHALLENGE")
+	ErrRetrievingChallenges = errors.New("UNABLE_TO_RETRIEVE_CHALLENGES")
+	ErrChallengeNotFound = errors.New("CHALLENGE_NOT_FOUND")
+	ErrUnathorizedToEditChallenge = errors.New("UNATHORIZED_TO_EDIT_CHALLENGE")
+	ErrChallengeAlreadyPublished = errors.New("CHALLENGE_ALREADY_PUBLISHED")
+	ErrUnableToPublish = errors.New("UNABLE_TO_PUBLISH_CHALLENGE")
+	ErrEmptyQuestion = errors.New("EMPTY_QUESTION")
+	ErrCreatingQuestion = errors.New("UNABLE_TO_CREATE_QUESTION")
+	ErrQuestionNotFound = errors.New("QUESTION_NOT_FOUND")
+	ErrDeletingQuestion = errors.New("UNABLE_TO_DELETE_QUESTION")
+	ErrCantEditPublishedChallenge = errors.New("CANT_EDIT_PUBLISHED_CHALLENGE")
+	ErrStartingChallenge = errors.New("UNABLE_TO_START_CHALLENGE")
+	ErrChallengeNotPublished = errors.New("CHALLENGE_NOT_PUBLISHED")
+	ErrChallengeAlreadyPlayed = errors.New("CHALLENGE_ALREADY_PLAYED")
+	ErrChallengePlayNotFound = errors.New("CHALLENGE_PLAY_NOT_FOUND")
+	ErrChallengePlayAlreadyCompleted = errors.New("CHALLENGE_PLAY_ALREADY_COMPLETED")
+	ErrRetrievingQuestions = errors.New("UNABLE_TO_RETRIEVE_QUESTIONS")
+	ErrQuestionNotInChallenge = errors.New("QUESTION_NOT_IN_CHALLENGE")
+	ErrAnsweringQuestion = errors.New("UNABLE_TO_ANSWER_QUESTION")
+	ErrMissingAnswers = errors.New("CHALLENGE_HAS_UNANSWERED_QUESTIONS")
+	ErrCompletingChallenge = errors.New("UNABLE_TO_COMPLETE_CHALLENGE")
+)
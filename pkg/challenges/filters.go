@@ -0,0 +1,21 @@
+package challenges
+
+import "github.com/google/uuid"
+
+type ChallengeFilter struct{
+	Id 			*uuid.UUID
+	CreatorId 	*uuid.UUID
+	Published 	*bool
+}
+
+type ChallengePlayedFilter struct{
+	Id 				*uuid.UUID
+	ChallengeId 	*uuid.UUID
+	UserId 			*uuid.UUID
+}
+
+type UserAnswerFilter struct{
+	Id 			*uuid.UUID
+	QuestionId 	*uuid.UUID
+	UserId 		*uuid.UUID
+}
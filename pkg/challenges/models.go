@@ -0,0 +1,61 @@
+package challenges
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChallengePlainModel struct{
+	Id 				uuid.UUID
+	Name 			string 
+	Description 	string 
+	Published 		bool 
+	CreatedAt 		time.Time
+	CreatorId 		uuid.UUID
+}
+
+type ChallengeModel struct{
+	Id 				uuid.UUID	`json:"id"`
+	Name 			string 		`json:"name"`
+	Description 	string 		`json:"description"`
+	Published 		bool 		`json:"published"`
+	CreatedAt 		time.Time	`json:"createdAt"`
+}
+
+type QuestionPlainModel struct{
+	Id 				uuid.UUID
+	Ordering 		int 
+	Question 		string 
+	QuestionType 	string 
+	OptionsJson  	string 
+	ChallengeId 	uuid.UUID
+}
+
+type ChallengePlayedPlainModel struct{
+	Id 				uuid.UUID
+	ChallengeId 	uuid.UUID
+	UserId 			uuid.UUID
+	Shared 			bool 
+	Score 			*int
+	StartedAt 		time.Time
+	CompletedAt 	*time.Time 
+}
+
+type ChallengePlayedModel struct{
+	Id 				uuid.UUID	`json:"id"`
+	ChallengeId 	uuid.UUID	`json:"challengeId"`
+	Shared 			bool 		`json:"shared"`
+	Score 			*int 		`json:"score"`
+	Won 			bool 		`json:"won"`
+	StartedAt 		time.Time	`json:"startedAt"`
+	CompletedAt 	*time.Time	`json:"completedAt"`
+}
+
+type UserAnswerPlainModel struct{
+	Id 			uuid.UUID
+	UserId 		uuid.UUID
+	QuestionId 	uuid.UUID
+	Answers 	string 
+	AnsweredAt 	time.Time
+}
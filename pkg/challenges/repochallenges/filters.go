@@ -0,0 +1,27 @@
+package repochallenges
+
+import "github.com/diegobermudez03/couples-backend/pkg/challenges"
+
+func challengeFilter(filter *challenges.ChallengeFilter) map[string]any{
+	return map[string]any{
+		"id" : filter.Id,
+		"creator_id" : filter.CreatorId,
+		"published" : filter.Published,
+	}
+}
+
+func challengesPlayedFilter(filter *challenges.ChallengePlayedFilter) map[string]any{
+	return map[string]any{
+		"id" : filter.Id,
+		"challenge_id" : filter.ChallengeId,
+		"user_id" : filter.UserId,
+	}
+}
+
+func userAnswerFilter(filter *challenges.UserAnswerFilter) map[string]any{
+	return map[string]any{
+		"id" : filter.Id,
+		"question_id" : filter.QuestionId,
+		"user_id" : filter.UserId,
+	}
+}
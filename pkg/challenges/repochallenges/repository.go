@@ -0,0 +1,299 @@
+package repochallenges
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/diegobermudez03/couples-backend/pkg/challenges"
+	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
+	"github.com/google/uuid"
+)
+
+type ChallengesPostgresRepo struct {
+	db *sql.DB
+}
+
+func NewChallengesPostgresRepo(db *sql.DB) challenges.ChallengesRepository {
+	return &ChallengesPostgresRepo{
+		db: db,
+	}
+}
+
+func (r *ChallengesPostgresRepo) GetChallenges(ctx context.Context, filter challenges.ChallengeFilter) ([]challenges.ChallengePlainModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, name, description, published, created_at, creator_id
+		FROM challenges WHERE 1=1 `,
+		challengeFilter(&filter),
+	)
+	query = query + " ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	models := []challenges.ChallengePlainModel{}
+	for rows.Next(){
+		model, err := r.rowToChallenge(rows)
+		if err != nil{
+			return nil, err 
+		}
+		models = append(models, *model)
+	}
+	return models, nil
+}
+
+func (r *ChallengesPostgresRepo) GetChallengeById(ctx context.Context, id uuid.UUID) (*challenges.ChallengePlainModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, name, description, published, created_at, creator_id
+		FROM challenges WHERE id = $1`,
+		id,
+	)
+	return r.rowToChallenge(row)
+}
+
+func (r *ChallengesPostgresRepo) CreateChallenge(ctx context.Context, challenge *challenges.ChallengePlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO challenges(id, name, description, published, created_at, creator_id)
+			VALUES($1, $2, $3, $4, $5, $6)`,
+			challenge.Id, challenge.Name, challenge.Description, challenge.Published, challenge.CreatedAt, challenge.CreatorId,
+		)
+	})
+}
+
+func (r *ChallengesPostgresRepo) UpdateChallenge(ctx context.Context, challenge *challenges.ChallengePlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE challenges SET name = $1, description = $2, published = $3 WHERE id = $4`,
+			challenge.Name, challenge.Description, challenge.Published, challenge.Id,
+		)
+	})
+}
+
+func (r *ChallengesPostgresRepo) GetQuestions(ctx context.Context, challengeId uuid.UUID) ([]challenges.QuestionPlainModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, ordering, question, question_type, options_json, challenge_id
+		FROM chall_questions WHERE challenge_id = $1 ORDER BY ordering`,
+		challengeId,
+	)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	questions := []challenges.QuestionPlainModel{}
+	for rows.Next(){
+		model, err := r.rowToQuestion(rows)
+		if err != nil{
+			return nil, err 
+		}
+		questions = append(questions, *model)
+	}
+	return questions, nil
+}
+
+func (r *ChallengesPostgresRepo) GetQuestionById(ctx context.Context, id uuid.UUID) (*challenges.QuestionPlainModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, ordering, question, question_type, options_json, challenge_id
+		FROM chall_questions WHERE id = $1`,
+		id,
+	)
+	return r.rowToQuestion(row)
+}
+
+func (r *ChallengesPostgresRepo) GetMaxOrderQuestion(ctx context.Context, challengeId uuid.UUID) (int, error){
+	row := r.db.QueryRowContext(
+		ctx, 
+		`SELECT COALESCE(max(ordering), 0)
+		FROM chall_questions WHERE challenge_id = $1`,
+		challengeId,
+	)
+	var num int 
+	if err := row.Scan(&num); err != nil{
+		return 0, err  
+	}
+	return num, nil
+}
+
+func (r *ChallengesPostgresRepo) CreateQuestion(ctx context.Context, question *challenges.QuestionPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO chall_questions(id, ordering, question, question_type, options_json, challenge_id)
+			VALUES($1, $2, $3, $4, $5, $6)`,
+			question.Id, question.Ordering, question.Question, question.QuestionType, question.OptionsJson, question.ChallengeId,
+		)
+	})
+}
+
+func (r *ChallengesPostgresRepo) DeleteQuestion(ctx context.Context, id uuid.UUID) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`DELETE FROM chall_questions WHERE id = $1`,
+			id,
+		)
+	})
+}
+
+func (r *ChallengesPostgresRepo) GetChallengesPlayed(ctx context.Context, filter challenges.ChallengePlayedFilter) ([]challenges.ChallengePlayedPlainModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, challenge_id, user_id, shared, score, started_at, completed_at
+		FROM challenges_played WHERE 1=1 `,
+		challengesPlayedFilter(&filter),
+	)
+	query = query + " ORDER BY started_at DESC"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	plays := []challenges.ChallengePlayedPlainModel{}
+	for rows.Next(){
+		model, err := r.rowToChallengePlayed(rows)
+		if err != nil{
+			return nil, err 
+		}
+		plays = append(plays, *model)
+	}
+	return plays, nil
+}
+
+func (r *ChallengesPostgresRepo) GetChallengePlayedById(ctx context.Context, id uuid.UUID) (*challenges.ChallengePlayedPlainModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, challenge_id, user_id, shared, score, started_at, completed_at
+		FROM challenges_played WHERE id = $1`,
+		id,
+	)
+	return r.rowToChallengePlayed(row)
+}
+
+func (r *ChallengesPostgresRepo) CreateChallengePlayed(ctx context.Context, model *challenges.ChallengePlayedPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO challenges_played(id, challenge_id, user_id, shared, score, started_at, completed_at)
+			VALUES($1, $2, $3, $4, $5, $6, $7)`,
+			model.Id, model.ChallengeId, model.UserId, model.Shared, model.Score, model.StartedAt, model.CompletedAt,
+		)
+	})
+}
+
+// only completes the play if it wasn't completed yet, so concurrent completions can't both succeed
+func (r *ChallengesPostgresRepo) CompleteChallengePlayed(ctx context.Context, model *challenges.ChallengePlayedPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE challenges_played SET score = $1, completed_at = $2 WHERE id = $3 AND completed_at IS NULL`,
+			model.Score, model.CompletedAt, model.Id,
+		)
+	})
+}
+
+func (r *ChallengesPostgresRepo) GetUsersAnswers(ctx context.Context, filter challenges.UserAnswerFilter) ([]challenges.UserAnswerPlainModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, user_id, question_id, answers, answered_at
+		FROM chall_answers WHERE 1=1 `,
+		userAnswerFilter(&filter),
+	)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	return r.rowsToUserAnswers(rows)
+}
+
+func (r *ChallengesPostgresRepo) GetUserAnswersFromChallenge(ctx context.Context, userId uuid.UUID, challengeId uuid.UUID) ([]challenges.UserAnswerPlainModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT a.id, a.user_id, a.question_id, a.answers, a.answered_at
+		FROM chall_answers a JOIN chall_questions q ON q.id = a.question_id
+		WHERE a.user_id = $1 AND q.challenge_id = $2`,
+		userId, challengeId,
+	)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	return r.rowsToUserAnswers(rows)
+}
+
+func (r *ChallengesPostgresRepo) CreateUserAnswer(ctx context.Context, model *challenges.UserAnswerPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO chall_answers(id, user_id, question_id, answers, answered_at)
+			VALUES($1, $2, $3, $4, $5)`,
+			model.Id, model.UserId, model.QuestionId, model.Answers, model.AnsweredAt,
+		)
+	})
+}
+
+func (r *ChallengesPostgresRepo) UpdateUserAnswer(ctx context.Context, model *challenges.UserAnswerPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE chall_answers SET answers = $1, answered_at = $2 WHERE id = $3`,
+			model.Answers, model.AnsweredAt, model.Id,
+		)
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////////////
+/////////////////		HELPERS
+
+func (r *ChallengesPostgresRepo) rowToChallenge(row infraestructure.Scanable) (*challenges.ChallengePlainModel, error){
+	model := new(challenges.ChallengePlainModel)
+	err := row.Scan(&model.Id, &model.Name, &model.Description, &model.Published, &model.CreatedAt, &model.CreatorId)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, nil 
+		}
+		return nil, err 
+	}
+	return model, nil
+}
+
+func (r *ChallengesPostgresRepo) rowToQuestion(row infraestructure.Scanable) (*challenges.QuestionPlainModel, error){
+	model := new(challenges.QuestionPlainModel)
+	err := row.Scan(&model.Id, &model.Ordering, &model.Question, &model.QuestionType, &model.OptionsJson, &model.ChallengeId)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, nil 
+		}
+		return nil, err 
+	}
+	return model, nil
+}
+
+func (r *ChallengesPostgresRepo) rowToChallengePlayed(row infraestructure.Scanable) (*challenges.ChallengePlayedPlainModel, error){
+	model := new(challenges.ChallengePlayedPlainModel)
+	err := row.Scan(&model.Id, &model.ChallengeId, &model.UserId, &model.Shared, &model.Score, &model.StartedAt, &model.CompletedAt)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, nil 
+		}
+		return nil, err 
+	}
+	return model, nil
+}
+
+func (r *ChallengesPostgresRepo) rowsToUserAnswers(rows *sql.Rows) ([]challenges.UserAnswerPlainModel, error){
+	answers := []challenges.UserAnswerPlainModel{}
+	for rows.Next(){
+		model := challenges.UserAnswerPlainModel{}
+		if err := rows.Scan(&model.Id, &model.UserId, &model.QuestionId, &model.Answers, &model.AnsweredAt); err != nil{
+			return nil, err 
+		}
+		answers = append(answers, model)
+	}
+	return answers, nil
+}
@@ -0,0 +1,30 @@
+package challenges
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ChallengesRepository interface{
+	GetChallenges(ctx context.Context, filter ChallengeFilter) ([]ChallengePlainModel, error)
+	GetChallengeById(ctx context.Context, id uuid.UUID) (*ChallengePlainModel, error)
+	CreateChallenge(ctx context.Context, challenge *ChallengePlainModel) (int, error)
+	UpdateChallenge(ctx context.Context, challenge *ChallengePlainModel) (int, error)
+
+	GetQuestions(ctx context.Context, challengeId uuid.UUID) ([]QuestionPlainModel, error)
+	GetQuestionById(ctx context.Context, id uuid.UUID) (*QuestionPlainModel, error)
+	GetMaxOrderQuestion(ctx context.Context, challengeId uuid.UUID) (int, error)
+	CreateQuestion(ctx context.Context, question *QuestionPlainModel) (int, error)
+	DeleteQuestion(ctx context.Context, id uuid.UUID) (int, error)
+
+	GetChallengesPlayed(ctx context.Context, filter ChallengePlayedFilter) ([]ChallengePlayedPlainModel, error)
+	GetChallengePlayedById(ctx context.Context, id uuid.UUID) (*ChallengePlayedPlainModel, error)
+	CreateChallengePlayed(ctx context.Context, model *ChallengePlayedPlainModel) (int, error)
+	CompleteChallengePlayed(ctx context.Context, model *ChallengePlayedPlainModel) (int, error)
+
+	GetUsersAnswers(ctx context.Context, filter UserAnswerFilter) ([]UserAnswerPlainModel, error)
+	GetUserAnswersFromChallenge(ctx context.Context, userId uuid.UUID, challengeId uuid.UUID) ([]UserAnswerPlainModel, error)
+	CreateUserAnswer(ctx context.Context, model *UserAnswerPlainModel) (int, error)
+	UpdateUserAnswer(ctx context.Context, model *UserAnswerPlainModel) (int, error)
+}
@@ -0,0 +1,129 @@
+package appevents
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/google/uuid"
+)
+
+// keeps the suscribers of this instance, the events go through the bus so the suscribers
+// connected to other instances receive them too
+type HubImpl struct{
+	bus 		events.Bus
+	suscribers 	map[uuid.UUID]map[*events.SuscriptionModel]bool
+	history 	map[uuid.UUID][]events.EventModel
+	lastId 		int64
+	mutex 		sync.Mutex
+}
+
+func NewHubImpl(bus events.Bus) (events.Hub, error){
+	hub := &HubImpl{
+		bus: bus,
+		suscribers: make(map[uuid.UUID]map[*events.SuscriptionModel]bool),
+		history: make(map[uuid.UUID][]events.EventModel),
+	}
+	if err := bus.Suscribe(events.COUPLE_EVENTS_CHANNEL, hub.deliver); err != nil{
+		return nil, err
+	}
+	return hub, nil
+}
+
+// the ids grow with the time, so they keep their order even after a restart
+func (h *HubImpl) Publish(ctx context.Context, event events.EventModel){
+	if event.CoupleId == uuid.Nil{
+		return
+	}
+	h.mutex.Lock()
+	event.CreatedAt = time.Now()
+	event.Id = max(event.CreatedAt.UnixNano(), h.lastId + 1)
+	h.lastId = event.Id
+	h.mutex.Unlock()
+
+	payload, err := json.Marshal(event)
+	if err != nil{
+		log.Print("error encoding event: ", err.Error())
+		return
+	}
+	//the bus logs its own errors, the event is lost for the live suscribers
+	h.bus.Publish(ctx, events.COUPLE_EVENTS_CHANNEL, payload)
+}
+
+func (h *HubImpl) Suscribe(coupleId uuid.UUID, lastEventId int64) (*events.SuscriptionModel, []events.EventModel){
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	suscription := &events.SuscriptionModel{
+		CoupleId: coupleId,
+		Events: make(chan events.EventModel, events.SUSCRIBER_BUFFER_SIZE),
+	}
+	if _, ok := h.suscribers[coupleId]; !ok{
+		h.suscribers[coupleId] = make(map[*events.SuscriptionModel]bool)
+	}
+	h.suscribers[coupleId][suscription] = true
+
+	missed := []events.EventModel{}
+	if lastEventId > 0{
+		for _, event := range h.history[coupleId]{
+			if event.Id > lastEventId{
+				missed = append(missed, event)
+			}
+		}
+	}
+	return suscription, missed
+}
+
+func (h *HubImpl) Unsuscribe(suscription *events.SuscriptionModel){
+	h.mutex.Lock()
+	h.removeSuscription(suscription)
+	h.mutex.Unlock()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+// receives the events of every instance, including this one
+func (h *HubImpl) deliver(payload []byte){
+	var event events.EventModel
+	if err := json.Unmarshal(payload, &event); err != nil{
+		log.Print("error decoding event: ", err.Error())
+		return
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	//the ids of the other instances also move ours forward, so they keep growing
+	h.lastId = max(h.lastId, event.Id)
+
+	history := append(h.history[event.CoupleId], event)
+	if len(history) > events.EVENTS_HISTORY_SIZE{
+		history = history[len(history)-events.EVENTS_HISTORY_SIZE:]
+	}
+	h.history[event.CoupleId] = history
+
+	for suscription := range h.suscribers[event.CoupleId]{
+		select{
+		case suscription.Events <- event:
+		default:
+			//the suscriber is too slow, it will resume from its last event
+			h.removeSuscription(suscription)
+		}
+	}
+}
+
+// must be called with the mutex locked
+func (h *HubImpl) removeSuscription(suscription *events.SuscriptionModel){
+	coupleSuscribers, ok := h.suscribers[suscription.CoupleId]
+	if !ok || !coupleSuscribers[suscription]{
+		return
+	}
+	delete(coupleSuscribers, suscription)
+	close(suscription.Events)
+	if len(coupleSuscribers) == 0{
+		delete(h.suscribers, suscription.CoupleId)
+	}
+}
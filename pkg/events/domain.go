@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// used by the services that generate events
+type Publisher interface{
+	Publish(ctx context.Context, event EventModel)
+}
+
+// delivers the events of each couple to its suscribers
+type Hub interface{
+	Publisher
+	// returns the new suscription and the events after lastEventId that the suscriber missed
+	Suscribe(coupleId uuid.UUID, lastEventId int64) (*SuscriptionModel, []EventModel)
+	Unsuscribe(suscription *SuscriptionModel)
+}
+
+
+// carries the messages between the instances of the API, every instance suscribed to a
+// channel receives the messages published on it (including the ones it published itself)
+type Bus interface{
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Suscribe(channel string, handler func(payload []byte)) error
+	Close() error
+}
+
+
+///// buses
+const (
+	MEMORY_BUS = "memory"
+	POSTGRES_BUS = "postgres"
+)
+
+const COUPLE_EVENTS_CHANNEL = "couple_events"
+
+///// events types
+const (
+	EVENT_QUIZ_STARTED = "QUIZ_STARTED"
+	EVENT_QUIZ_COMPLETED = "QUIZ_COMPLETED"
+	EVENT_QUESTION_ANSWERED = "QUESTION_ANSWERED"
+	EVENT_NICKNAME_CHANGED = "NICKNAME_CHANGED"
+	EVENT_POINTS_EARNED = "POINTS_EARNED"
+	EVENT_QUIZ_INVITATION = "QUIZ_INVITATION"
+	EVENT_QUIZ_INVITATION_ACCEPTED = "QUIZ_INVITATION_ACCEPTED"
+	EVENT_QUIZ_INVITATION_REMINDER = "QUIZ_INVITATION_REMINDER"
+	EVENT_QUIZ_INVITATION_EXPIRED = "QUIZ_INVITATION_EXPIRED"
+	EVENT_QUIZ_RESULTS_UNLOCKED = "QUIZ_RESULTS_UNLOCKED"
+)
+
+// events kept per couple to resume the streams
+const EVENTS_HISTORY_SIZE = 100
+// a suscriber that falls this behind is dropped, and it has to resume from its last event
+const SUSCRIBER_BUFFER_SIZE = 32
@@ -0,0 +1,8 @@
+package events
+
+import "errors"
+
+var (
+	ErrNoCoupleToSuscribe = errors.New("NO_COUPLE_TO_SUSCRIBE")
+	ErrInvalidLastEventId = errors.New("INVALID_LAST_EVENT_ID")
+)
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// the user is the member of the couple that caused the event
+type EventModel struct{
+	Id 			int64 		`json:"id"`
+	Type 		string 		`json:"type"`
+	CoupleId 	uuid.UUID 	`json:"coupleId"`
+	UserId 		uuid.UUID 	`json:"userId"`
+	Data 		any 		`json:"data,omitempty"`
+	CreatedAt 	time.Time 	`json:"createdAt"`
+}
+
+// the channel is closed when the suscription ends
+type SuscriptionModel struct{
+	CoupleId 	uuid.UUID
+	Events 		chan EventModel
+}
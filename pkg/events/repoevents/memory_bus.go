@@ -0,0 +1,42 @@
+package repoevents
+
+import (
+	"context"
+	"sync"
+
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+)
+
+// only for single instance setups, the messages never leave the process
+type MemoryBus struct{
+	handlers 	map[string][]func(payload []byte)
+	mutex 		sync.RWMutex
+}
+
+func NewMemoryBus() events.Bus{
+	return &MemoryBus{
+		handlers: make(map[string][]func(payload []byte)),
+	}
+}
+
+// the handlers are called before returning, like the other instances would receive it right away
+func (b *MemoryBus) Publish(ctx context.Context, channel string, payload []byte) error{
+	b.mutex.RLock()
+	handlers := b.handlers[channel]
+	b.mutex.RUnlock()
+	for _, handler := range handlers{
+		handler(payload)
+	}
+	return nil
+}
+
+func (b *MemoryBus) Suscribe(channel string, handler func(payload []byte)) error{
+	b.mutex.Lock()
+	b.handlers[channel] = append(b.handlers[channel], handler)
+	b.mutex.Unlock()
+	return nil
+}
+
+func (b *MemoryBus) Close() error{
+	return nil
+}
@@ -0,0 +1,96 @@
+package repoevents
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/lib/pq"
+)
+
+const LISTENER_MIN_RECONNECT = 10 * time.Second
+const LISTENER_MAX_RECONNECT = time.Minute
+// the connection is checked when there are no notifications for this time
+const LISTENER_PING_INTERVAL = 90 * time.Second
+
+// uses LISTEN/NOTIFY, so every instance connected to the database receives the messages.
+// The notifications sent while the listener is reconnecting are lost
+type PostgresBus struct{
+	db 			*sql.DB
+	listener 	*pq.Listener
+	handlers 	map[string][]func(payload []byte)
+	mutex 		sync.RWMutex
+	done 		chan struct{}
+}
+
+func NewPostgresBus(db *sql.DB, address string) events.Bus{
+	listener := pq.NewListener(address, LISTENER_MIN_RECONNECT, LISTENER_MAX_RECONNECT, func(event pq.ListenerEventType, err error){
+		if err != nil{
+			log.Print("event bus listener error: ", err.Error())
+		}
+	})
+	bus := &PostgresBus{
+		db: db,
+		listener: listener,
+		handlers: make(map[string][]func(payload []byte)),
+		done: make(chan struct{}),
+	}
+	go bus.listen()
+	return bus
+}
+
+func (b *PostgresBus) Publish(ctx context.Context, channel string, payload []byte) error{
+	if _, err := b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, channel, string(payload)); err != nil{
+		log.Print("error publishing to the event bus: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (b *PostgresBus) Suscribe(channel string, handler func(payload []byte)) error{
+	b.mutex.Lock()
+	_, listening := b.handlers[channel]
+	b.handlers[channel] = append(b.handlers[channel], handler)
+	b.mutex.Unlock()
+	if listening{
+		return nil
+	}
+	return b.listener.Listen(channel)
+}
+
+func (b *PostgresBus) Close() error{
+	err := b.listener.Close()
+	<- b.done
+	return err
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+func (b *PostgresBus) listen(){
+	defer close(b.done)
+	for{
+		select{
+		case notification, ok := <- b.listener.Notify:
+			if !ok{
+				return
+			}
+			//a nil notification means the connection was re-established
+			if notification == nil{
+				log.Print("event bus reconnected, notifications may have been lost")
+				continue
+			}
+			b.mutex.RLock()
+			handlers := b.handlers[notification.Channel]
+			b.mutex.RUnlock()
+			for _, handler := range handlers{
+				handler([]byte(notification.Extra))
+			}
+		case <- time.After(LISTENER_PING_INTERVAL):
+			go b.listener.Ping()
+		}
+	}
+}
@@ -14,9 +14,16 @@ import (
 
 	"github.com/diegobermudez03/couples-backend/pkg/files"
 	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
 	"golang.org/x/image/draw"
 )
 
+// content type of the stored files by their extension
+var extensionTypes = map[string]string{
+	".jpg" : files.JPG_TYPE,
+	".png" : files.PNG_TYPE,
+}
+
 type FilesServiceImpl struct{
 	filesRepo 		files.FileRepository
 	dbRepo 			files.Repository
@@ -48,30 +55,26 @@ func (s *FilesServiceImpl) UploadImage(ctx context.Context, imageReader io.Reade
 		*url =  s.baseURL + "/files/images/" + filepath.Join(path...) + ".jpg"
 	}
 
-	// store image 
-	bucket := path[0]
-	object := path[len(path)-1] + ".jpg"
-	path = path[1:len(path)-1]
-	group := filepath.Join(path...)
-	if err := s.filesRepo.StoreFile(ctx, bucket, group, object, buffer); err != nil{
-		return nil, nil, err
-	}
+	return s.storeFile(ctx, buffer, ".jpg", url, public, path...)
+}
 
-	// add to database
-	id := uuid.New()
-	model := files.FileModel{
-		Id: id,
-		Bucket: bucket,
-		Group: group,
-		ObjectKey: object,
-		Public: public,
-		Url: url,
-		Type : files.JPG_TYPE,
+
+// the QR is stored as PNG, compressing it to JPG would blur the modules
+func (s *FilesServiceImpl) UploadQrCode(ctx context.Context, content string, size int, public bool, path ...string) (*uuid.UUID, *string, error){
+	if len(path) < 3{
+		return nil,nil, files.ErrPathNotLongEnough
 	}
-	if num, err := s.dbRepo.CreateFile(ctx, &model); err != nil || num == 0{
-		return nil, nil, files.ErrUploadingImage
+	pngBytes, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil{
+		return nil, nil, files.ErrGeneratingQrCode
 	}
-	return &id, url, nil
+
+	var url *string 
+	if public{
+		url = new(string)
+		*url =  s.baseURL + "/files/images/" + filepath.Join(path...) + ".png"
+	}
+	return s.storeFile(ctx, bytes.NewReader(pngBytes), ".png", url, public, path...)
 }
 
 
@@ -101,7 +104,11 @@ func (s *FilesServiceImpl) GetImage(ctx context.Context, path string) (*os.File,
 	if err != nil{
 		return nil, "", err
 	}
-	return file, files.JPG_TYPE, nil
+	contentType, ok := extensionTypes[filepath.Ext(path)]
+	if !ok{
+		contentType = files.JPG_TYPE
+	}
+	return file, contentType, nil
 }
 
 func (s *FilesServiceImpl) DeleteImage(ctx context.Context, imageId uuid.UUID) error{
@@ -126,6 +133,32 @@ func (s *FilesServiceImpl) GetBatchUrls(ctx context.Context, imagesIds []uuid.UU
 /////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 //////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+// the first element of the path is the bucket and the last one the object name
+func (s *FilesServiceImpl) storeFile(ctx context.Context, content io.Reader, extension string, url *string, public bool, path ...string) (*uuid.UUID, *string, error){
+	bucket := path[0]
+	object := path[len(path)-1] + extension
+	group := filepath.Join(path[1:len(path)-1]...)
+	if err := s.filesRepo.StoreFile(ctx, bucket, group, object, content); err != nil{
+		return nil, nil, err
+	}
+
+	// add to database
+	id := uuid.New()
+	model := files.FileModel{
+		Id: id,
+		Bucket: bucket,
+		Group: group,
+		ObjectKey: object,
+		Public: public,
+		Url: url,
+		Type : extensionTypes[extension],
+	}
+	if num, err := s.dbRepo.CreateFile(ctx, &model); err != nil || num == 0{
+		return nil, nil, files.ErrUploadingImage
+	}
+	return &id, url, nil
+}
+
 func (s *FilesServiceImpl) compressToJPG(imageReader io.Reader, maxSize int) (io.Reader, error){
 	//detect image type
 	imageBytes := make([]byte, 512)
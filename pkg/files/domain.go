@@ -11,6 +11,7 @@ import (
 type Service interface {
 	UploadImage(ctx context.Context, image io.Reader, maxSize int64, public bool, path ...string) (imId *uuid.UUID, url *string, err error)
 	UpdateImage(ctx context.Context, image io.Reader, maxSize int64, id uuid.UUID) (error)
+	UploadQrCode(ctx context.Context, content string, size int, public bool, path ...string) (imId *uuid.UUID, url *string, err error)
 	GetImage(ctx context.Context, path string) (*os.File, string, error)
 	DeleteImage(ctx context.Context, imageId uuid.UUID) error
 	GetBatchUrls(ctx context.Context, imagesIds []uuid.UUID) (map[uuid.UUID]string, error)
@@ -35,4 +36,5 @@ const PNG_TYPE = "image/png"
 
 
 const MAX_SIZE_PROFILE_PICTURE = 2073600 		//	1920x1080
-const MAX_SIZE_QUESTION_PICTURE = 160000 // 400x400
\ No newline at end of file
+const MAX_SIZE_QUESTION_PICTURE = 160000 // 400x400
+const QR_CODE_SIZE = 512 		// 512x512
\ No newline at end of file
@@ -10,4 +10,5 @@ var (
 	ErrUpdatingImage = errors.New("UNABLE_TO_UPDATE_IMAGE")
 	ErrNonExistingImage = errors.New("NON_EXISTING_IMAGE")
 	ErrDeletingImage = errors.New("UNABLE_TO_DELETE_IMAGE")
+	ErrGeneratingQrCode = errors.New("UNABLE_TO_GENERATE_QR_CODE")
 )
\ No newline at end of file
@@ -25,18 +25,42 @@ func NewTransactions(db *sql.DB) Transaction{
 }
 
 type dbKey struct{}
+type afterCommitKey struct{}
 
+// if the context already carries a transaction, the function is executed inside of it
 func (t *Transactions) Do(ctx context.Context, f func(context.Context)error) error{
+	if ctx.Value(dbKey{}) != nil{
+		return f(ctx)
+	}
 	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil{
 		return err 
 	}
+	hooks := []func(){}
 	c := context.WithValue(ctx, dbKey{}, tx)
+	c = context.WithValue(c, afterCommitKey{}, &hooks)
 	err = f(c)
 	if err != nil{
 		tx.Rollback()
 		return err 
 	}
-	return tx.Commit()
+	if err := tx.Commit(); err != nil{
+		return err
+	}
+	for _, hook := range hooks{
+		hook()
+	}
+	return nil
+}
+
+// runs the function once the transaction of the context is committed, or right away if there's no transaction.
+// Used for side effects (like notifications) that must not happen if the transaction is rolled back
+func AfterCommit(ctx context.Context, f func()){
+	hooks, ok := ctx.Value(afterCommitKey{}).(*[]func())
+	if !ok{
+		f()
+		return
+	}
+	*hooks = append(*hooks, f)
 }
 
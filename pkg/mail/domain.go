@@ -0,0 +1,12 @@
+package mail
+
+import "context"
+
+type Sender interface{
+	Send(ctx context.Context, message *MessageModel) error
+}
+
+
+const SMTP_SENDER = "smtp"
+const FILE_SENDER = "file"
+const MEMORY_SENDER = "memory"
@@ -0,0 +1,7 @@
+package mail
+
+import "errors"
+
+var (
+	ErrSendingMail = errors.New("UNABLE_TO_SEND_MAIL")
+)
@@ -0,0 +1,7 @@
+package mail
+
+type MessageModel struct{
+	To 			string
+	Subject 	string
+	Body 		string
+}
@@ -0,0 +1,38 @@
+package repomail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/mail"
+	"github.com/google/uuid"
+)
+
+// writes every mail as a file inside the folder, meant for development
+type FileSender struct{
+	folder 	string
+}
+
+func NewFileSender(folder string) mail.Sender{
+	return &FileSender{
+		folder: folder,
+	}
+}
+
+func (m *FileSender) Send(ctx context.Context, message *mail.MessageModel) error{
+	if err := os.MkdirAll(m.folder, os.ModePerm); err != nil{
+		log.Print("error creating mails folder: ", err.Error())
+		return mail.ErrSendingMail
+	}
+	fileName := fmt.Sprintf("%d_%s.txt", time.Now().UnixNano(), uuid.NewString())
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Body)
+	if err := os.WriteFile(filepath.Join(m.folder, fileName), []byte(content), 0644); err != nil{
+		log.Print("error writing mail: ", err.Error())
+		return mail.ErrSendingMail
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repomail
+
+import (
+	"context"
+	"sync"
+
+	"github.com/diegobermudez03/couples-backend/pkg/mail"
+)
+
+// keeps the sent mails in memory, meant for tests
+type MemorySender struct{
+	messages 	[]mail.MessageModel
+	mutex 		sync.RWMutex
+}
+
+func NewMemorySender() *MemorySender{
+	return &MemorySender{
+		messages: []mail.MessageModel{},
+	}
+}
+
+func (m *MemorySender) Send(ctx context.Context, message *mail.MessageModel) error{
+	m.mutex.Lock()
+	m.messages = append(m.messages, *message)
+	m.mutex.Unlock()
+	return nil
+}
+
+func (m *MemorySender) GetMessages() []mail.MessageModel{
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	messages := make([]mail.MessageModel, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
+
+// returns the last mail sent to the address, nil if there's none
+func (m *MemorySender) GetLastMessageTo(to string) *mail.MessageModel{
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for i := len(m.messages)-1; i >= 0; i--{
+		if m.messages[i].To == to{
+			message := m.messages[i]
+			return &message
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repomail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/diegobermudez03/couples-backend/pkg/mail"
+)
+
+type SmtpSender struct{
+	host 		string
+	port 		string
+	username 	string
+	password 	string
+	from 		string
+}
+
+func NewSmtpSender(host, port, username, password, from string) mail.Sender{
+	return &SmtpSender{
+		host: host,
+		port: port,
+		username: username,
+		password: password,
+		from: from,
+	}
+}
+
+func (m *SmtpSender) Send(ctx context.Context, message *mail.MessageModel) error{
+	var auth smtp.Auth
+	if m.username != ""{
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("From: %s\r\n", m.from))
+	builder.WriteString(fmt.Sprintf("To: %s\r\n", message.To))
+	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", message.Subject))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	builder.WriteString(message.Body)
+
+	err := smtp.SendMail(net.JoinHostPort(m.host, m.port), auth, m.from, []string{message.To}, []byte(builder.String()))
+	if err != nil{
+		log.Print("error sending mail: ", err.Error())
+		return mail.ErrSendingMail
+	}
+	return nil
+}
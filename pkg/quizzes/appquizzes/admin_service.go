@@ -25,6 +25,7 @@ type AdminServiceImpl struct {
 
 func NewAdminServiceImpl(transactions infraestructure.Transaction, filesService files.Service, loacalizationService localization.LocalizationService,  quizzesRepo quizzes.QuizzesRepository) quizzes.AdminService{
 	return &AdminServiceImpl{
+		transactions: transactions,
 		filesService: filesService,
 		quizzesRepo: quizzesRepo,
 		loacalizationService:loacalizationService,
@@ -0,0 +1,557 @@
+package appquizzes
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/google/uuid"
+)
+
+var errInvalidAnswerValue = errors.New("invalid answer value")
+
+// stored format of every answer in user_answers.answers
+type storedAnswer[T any] struct{
+	OwnAnswer 		T 	`json:"ownAnswer"`
+	GuessedPartner 	*T 	`json:"guessedPartner,omitempty"`
+}
+
+// ORDERING ANSWERS
+type orderingAnswer struct{
+	OptId 	int 	`json:"optId"`
+	Rank 	int 	`json:"rank"`
+}
+
+// MATCHING ANSWERS
+type matchingAnswer struct{
+	LeftId 	int 	`json:"leftId"`
+	RightId int 	`json:"rightId"`
+}
+
+// DRAG AND DROP ANSWERS
+type dragAndDropAnswer struct{
+	BoxId 		int 	`json:"boxId"`
+	OptionsIds 	[]int 	`json:"optionsIds"`
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 			VALIDATORS 			//////
+
+func (s *UserService) trueFalseValidator(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error){
+	return buildAnswer(answer, func(value bool) error{
+		return nil
+	})
+}
+
+func (s *UserService) sliderValidator(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error){
+	return buildAnswer(answer, func(value int) error{
+		if value < quizzes.SLIDER_MIN_VALUE || value > quizzes.SLIDER_MAX_VALUE{
+			return errInvalidAnswerValue
+		}
+		return nil
+	})
+}
+
+func (s *UserService) orderingValidator(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error){
+	var options orderingOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return "", quizzes.ErrAnsweringQuestion
+	}
+	optIds := getOptionsIds(options.Options)
+	return buildAnswer(answer, func(value []orderingAnswer) error{
+		if len(value) != len(optIds){
+			return errInvalidAnswerValue
+		}
+		usedOpts := map[int]bool{}
+		usedRanks := map[int]bool{}
+		for _, rank := range value{
+			if !optIds[rank.OptId] || usedOpts[rank.OptId]{
+				return errInvalidAnswerValue
+			}
+			if rank.Rank < 1 || rank.Rank > len(optIds) || usedRanks[rank.Rank]{
+				return errInvalidAnswerValue
+			}
+			usedOpts[rank.OptId] = true
+			usedRanks[rank.Rank] = true
+		}
+		return nil
+	})
+}
+
+func (s *UserService) openValidator(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error){
+	var options openOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return "", quizzes.ErrAnsweringQuestion
+	}
+	return buildAnswer(answer, func(value []string) error{
+		if len(value) != options.NumAnswers{
+			return errInvalidAnswerValue
+		}
+		for _, text := range value{
+			if strings.TrimSpace(text) == ""{
+				return errInvalidAnswerValue
+			}
+		}
+		return nil
+	})
+}
+
+func (s *UserService) multipleChValidator(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error){
+	var options multipleOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return "", quizzes.ErrAnsweringQuestion
+	}
+	optIds := getOptionsIds(options.Options)
+	return buildAnswer(answer, func(value []int) error{
+		if len(value) == 0 || (!options.MultipleAnswer && len(value) != 1){
+			return errInvalidAnswerValue
+		}
+		used := map[int]bool{}
+		for _, id := range value{
+			if !optIds[id] || used[id]{
+				return errInvalidAnswerValue
+			}
+			used[id] = true
+		}
+		return nil
+	})
+}
+
+func (s *UserService) matchingValidator(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error){
+	var options matchingOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return "", quizzes.ErrAnsweringQuestion
+	}
+	leftIds := getOptionsIds(options.Options1)
+	rightIds := getOptionsIds(options.Options2)
+	return buildAnswer(answer, func(value []matchingAnswer) error{
+		if len(value) != len(leftIds){
+			return errInvalidAnswerValue
+		}
+		usedLeft := map[int]bool{}
+		usedRight := map[int]bool{}
+		for _, pair := range value{
+			if !leftIds[pair.LeftId] || usedLeft[pair.LeftId]{
+				return errInvalidAnswerValue
+			}
+			if !rightIds[pair.RightId] || usedRight[pair.RightId]{
+				return errInvalidAnswerValue
+			}
+			usedLeft[pair.LeftId] = true
+			usedRight[pair.RightId] = true
+		}
+		return nil
+	})
+}
+
+func (s *UserService) dragAndDropValidator(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error){
+	var options dragAndDropOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return "", quizzes.ErrAnsweringQuestion
+	}
+	boxIds := getOptionsIds(options.Boxes)
+	optIds := getOptionsIds(options.Options)
+	return buildAnswer(answer, func(value []dragAndDropAnswer) error{
+		usedBoxes := map[int]bool{}
+		usedOpts := map[int]bool{}
+		for _, box := range value{
+			if !boxIds[box.BoxId] || usedBoxes[box.BoxId]{
+				return errInvalidAnswerValue
+			}
+			usedBoxes[box.BoxId] = true
+			for _, id := range box.OptionsIds{
+				if !optIds[id] || usedOpts[id]{
+					return errInvalidAnswerValue
+				}
+				usedOpts[id] = true
+			}
+		}
+		//every option must be placed in a box
+		if len(usedOpts) != len(optIds){
+			return errInvalidAnswerValue
+		}
+		return nil
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 			COMPARATORS 			//////
+// every comparator returns if both answers match and a similarity between 0 and 1
+
+func (s *UserService) trueFalseComparator(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (bool, float64, error){
+	return compareAnswers(ownAnswer, partnerAnswer, trueFalseSimilarity)
+}
+
+func (s *UserService) sliderComparator(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (bool, float64, error){
+	return compareAnswers(ownAnswer, partnerAnswer, sliderSimilarity)
+}
+
+func (s *UserService) orderingComparator(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (bool, float64, error){
+	return compareAnswers(ownAnswer, partnerAnswer, orderingSimilarity)
+}
+
+func (s *UserService) openComparator(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (bool, float64, error){
+	return compareAnswers(ownAnswer, partnerAnswer, openSimilarity)
+}
+
+func (s *UserService) multipleChComparator(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (bool, float64, error){
+	return compareAnswers(ownAnswer, partnerAnswer, setOverlap[int])
+}
+
+func (s *UserService) matchingComparator(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (bool, float64, error){
+	return compareAnswers(ownAnswer, partnerAnswer, matchingSimilarity)
+}
+
+func (s *UserService) dragAndDropComparator(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (bool, float64, error){
+	return compareAnswers(ownAnswer, partnerAnswer, dragAndDropSimilarity)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 			SCORERS 			//////
+// every scorer returns how right the answer is against the key, between 0 and 1
+
+func (s *UserService) trueFalseScorer(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (float64, error){
+	return scoreAnswer(answer, key, trueFalseSimilarity)
+}
+
+// the slider answer is right or wrong, inside the tolerance it's right
+func (s *UserService) sliderScorer(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (float64, error){
+	return scoreAnswer(answer, key, func(answer, key int) (bool, float64){
+		if match, _ := sliderSimilarity(answer, key); match{
+			return true, 1
+		}
+		return false, 0
+	})
+}
+
+func (s *UserService) orderingScorer(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (float64, error){
+	return scoreAnswer(answer, key, orderingSimilarity)
+}
+
+func (s *UserService) openScorer(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (float64, error){
+	return scoreAnswer(answer, key, openSimilarity)
+}
+
+func (s *UserService) multipleChScorer(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (float64, error){
+	return scoreAnswer(answer, key, setOverlap[int])
+}
+
+func (s *UserService) matchingScorer(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (float64, error){
+	return scoreAnswer(answer, key, matchingSimilarity)
+}
+
+func (s *UserService) dragAndDropScorer(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (float64, error){
+	return scoreAnswer(answer, key, dragAndDropSimilarity)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 			CREDITERS 			//////
+// every crediter returns the weight, between 0 and 1, that the own answer gives to each strategic type,
+// the question type gets it for any answer and the types of the options only when they are picked
+
+// true is the answer that agrees with the strategic type of the question
+func (s *UserService) trueFalseCrediter(question *quizzes.QuestionPlainModel, answer json.RawMessage) (map[uuid.UUID]float64, error){
+	return creditAnswer(question, answer, func(value bool) float64{
+		if value{
+			return 1
+		}
+		return 0
+	}, nil)
+}
+
+func (s *UserService) sliderCrediter(question *quizzes.QuestionPlainModel, answer json.RawMessage) (map[uuid.UUID]float64, error){
+	return creditAnswer(question, answer, func(value int) float64{
+		return float64(value - quizzes.SLIDER_MIN_VALUE) / float64(quizzes.SLIDER_MAX_VALUE - quizzes.SLIDER_MIN_VALUE)
+	}, nil)
+}
+
+// the options ranked as the most get their whole weight, and the least only a part of it
+func (s *UserService) orderingCrediter(question *quizzes.QuestionPlainModel, answer json.RawMessage) (map[uuid.UUID]float64, error){
+	var options orderingOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return nil, quizzes.ErrAnsweringQuestion
+	}
+	byId := getOptionsById(options.Options)
+	return creditAnswer(question, answer, func(value []orderingAnswer) float64{
+		return 1
+	}, func(value []orderingAnswer, credits map[uuid.UUID]float64){
+		for _, rank := range value{
+			position := rank.Rank
+			if options.SortingType == quizzes.MOST_TO_LEAST{
+				position = len(value) - rank.Rank + 1
+			}
+			creditOption(credits, byId[rank.OptId], ratio(position, len(value)))
+		}
+	})
+}
+
+func (s *UserService) openCrediter(question *quizzes.QuestionPlainModel, answer json.RawMessage) (map[uuid.UUID]float64, error){
+	return creditAnswer(question, answer, func(value []string) float64{
+		return 1
+	}, nil)
+}
+
+func (s *UserService) multipleChCrediter(question *quizzes.QuestionPlainModel, answer json.RawMessage) (map[uuid.UUID]float64, error){
+	var options multipleOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return nil, quizzes.ErrAnsweringQuestion
+	}
+	byId := getOptionsById(options.Options)
+	return creditAnswer(question, answer, func(value []int) float64{
+		return 1
+	}, func(value []int, credits map[uuid.UUID]float64){
+		for _, id := range value{
+			creditOption(credits, byId[id], 1)
+		}
+	})
+}
+
+// every side of a pair gets its weight scaled by the weight of the option it was matched to
+func (s *UserService) matchingCrediter(question *quizzes.QuestionPlainModel, answer json.RawMessage) (map[uuid.UUID]float64, error){
+	var options matchingOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return nil, quizzes.ErrAnsweringQuestion
+	}
+	left, right := getOptionsById(options.Options1), getOptionsById(options.Options2)
+	return creditAnswer(question, answer, func(value []matchingAnswer) float64{
+		return 1
+	}, func(value []matchingAnswer, credits map[uuid.UUID]float64){
+		for _, pair := range value{
+			creditOption(credits, left[pair.LeftId], getOptionWeight(right[pair.RightId]))
+			creditOption(credits, right[pair.RightId], getOptionWeight(left[pair.LeftId]))
+		}
+	})
+}
+
+// same as the matching, every option and the box where it was placed work as a pair
+func (s *UserService) dragAndDropCrediter(question *quizzes.QuestionPlainModel, answer json.RawMessage) (map[uuid.UUID]float64, error){
+	var options dragAndDropOptionsFormat
+	if err := json.Unmarshal([]byte(question.OptionsJson), &options); err != nil{
+		return nil, quizzes.ErrAnsweringQuestion
+	}
+	boxes, opts := getOptionsById(options.Boxes), getOptionsById(options.Options)
+	return creditAnswer(question, answer, func(value []dragAndDropAnswer) float64{
+		return 1
+	}, func(value []dragAndDropAnswer, credits map[uuid.UUID]float64){
+		for _, box := range value{
+			for _, id := range box.OptionsIds{
+				creditOption(credits, opts[id], getOptionWeight(boxes[box.BoxId]))
+				creditOption(credits, boxes[box.BoxId], getOptionWeight(opts[id]))
+			}
+		}
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 			SIMILARITIES 			//////
+// shared by the comparators and the scorers, they return if both values match and a similarity between 0 and 1
+
+func trueFalseSimilarity(own, partner bool) (bool, float64){
+	if own == partner{
+		return true, 1
+	}
+	return false, 0
+}
+
+func sliderSimilarity(own, partner int) (bool, float64){
+	distance := math.Abs(float64(own - partner))
+	similarity := 1 - distance / float64(quizzes.SLIDER_MAX_VALUE - quizzes.SLIDER_MIN_VALUE)
+	return distance <= quizzes.SLIDER_MATCH_TOLERANCE, similarity
+}
+
+func orderingSimilarity(own, partner []orderingAnswer) (bool, float64){
+	partnerRanks := make(map[int]int, len(partner))
+	for _, rank := range partner{
+		partnerRanks[rank.OptId] = rank.Rank
+	}
+	equals := 0
+	for _, rank := range own{
+		if r, ok := partnerRanks[rank.OptId]; ok && r == rank.Rank{
+			equals++
+		}
+	}
+	return equals == len(own), ratio(equals, len(own))
+}
+
+func openSimilarity(own, partner []string) (bool, float64){
+	normalize := func(texts []string) []string{
+		output := make([]string, 0, len(texts))
+		for _, text := range texts{
+			output = append(output, strings.ToLower(strings.TrimSpace(text)))
+		}
+		return output
+	}
+	return setOverlap(normalize(own), normalize(partner))
+}
+
+func matchingSimilarity(own, partner []matchingAnswer) (bool, float64){
+	partnerPairs := make(map[int]int, len(partner))
+	for _, pair := range partner{
+		partnerPairs[pair.LeftId] = pair.RightId
+	}
+	equals := 0
+	for _, pair := range own{
+		if right, ok := partnerPairs[pair.LeftId]; ok && right == pair.RightId{
+			equals++
+		}
+	}
+	return equals == len(own), ratio(equals, len(own))
+}
+
+func dragAndDropSimilarity(own, partner []dragAndDropAnswer) (bool, float64){
+	partnerBoxes := map[int]int{}
+	for _, box := range partner{
+		for _, id := range box.OptionsIds{
+			partnerBoxes[id] = box.BoxId
+		}
+	}
+	total, equals := 0, 0
+	for _, box := range own{
+		for _, id := range box.OptionsIds{
+			total++
+			if boxId, ok := partnerBoxes[id]; ok && boxId == box.BoxId{
+				equals++
+			}
+		}
+	}
+	return equals == total, ratio(equals, total)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///// 			HELPERS 			//////
+
+// decodes both answers with the type of the question, checks them and returns the JSON to store
+func buildAnswer[T any](answer quizzes.AnswerRequest, check func(value T) error) (string, error){
+	var stored storedAnswer[T]
+	if err := json.Unmarshal(answer.OwnAnswer, &stored.OwnAnswer); err != nil{
+		return "", quizzes.ErrInvalidAnswer
+	}
+	if err := check(stored.OwnAnswer); err != nil{
+		return "", quizzes.ErrInvalidAnswer
+	}
+	if !isEmptyJson(answer.GuessedPartner){
+		stored.GuessedPartner = new(T)
+		if err := json.Unmarshal(answer.GuessedPartner, stored.GuessedPartner); err != nil{
+			return "", quizzes.ErrInvalidAnswer
+		}
+		if err := check(*stored.GuessedPartner); err != nil{
+			return "", quizzes.ErrInvalidAnswer
+		}
+	}
+	jsonBytes, err := json.Marshal(stored)
+	if err != nil{
+		return "", quizzes.ErrAnsweringQuestion
+	}
+	return string(jsonBytes), nil
+}
+
+func isEmptyJson(value json.RawMessage) bool{
+	return len(value) == 0 || string(value) == "null"
+}
+
+func getOptionsIds(options []questionOption) map[int]bool{
+	ids := make(map[int]bool, len(options))
+	for _, opt := range options{
+		ids[opt.OptId] = true
+	}
+	return ids
+}
+
+func getOptionsById(options []questionOption) map[int]questionOption{
+	byId := make(map[int]questionOption, len(options))
+	for _, opt := range options{
+		byId[opt.OptId] = opt
+	}
+	return byId
+}
+
+// decodes the own answer of both stored answers with the type of the question and compares them
+func compareAnswers[T any](ownAnswer, partnerAnswer string, compare func(own, partner T) (bool, float64)) (bool, float64, error){
+	var own, partner storedAnswer[T]
+	if err := json.Unmarshal([]byte(ownAnswer), &own); err != nil{
+		return false, 0, quizzes.ErrComparingAnswers
+	}
+	if err := json.Unmarshal([]byte(partnerAnswer), &partner); err != nil{
+		return false, 0, quizzes.ErrComparingAnswers
+	}
+	match, similarity := compare(own.OwnAnswer, partner.OwnAnswer)
+	return match, similarity, nil
+}
+
+// decodes the answer and the key with the type of the question and returns the similarity of both
+func scoreAnswer[T any](answer, key json.RawMessage, compare func(answer, key T) (bool, float64)) (float64, error){
+	var answerValue, keyValue T
+	if err := json.Unmarshal(answer, &answerValue); err != nil{
+		return 0, quizzes.ErrInvalidAnswer
+	}
+	if err := json.Unmarshal(key, &keyValue); err != nil{
+		return 0, quizzes.ErrInvalidAnswerKey
+	}
+	_, similarity := compare(answerValue, keyValue)
+	return similarity, nil
+}
+
+// decodes the answer with the type of the question and credits the strategic type of the question
+func creditAnswer[T any](question *quizzes.QuestionPlainModel, answer json.RawMessage, weight func(value T) float64, creditOptions func(value T, credits map[uuid.UUID]float64)) (map[uuid.UUID]float64, error){
+	var value T
+	if err := json.Unmarshal(answer, &value); err != nil{
+		return nil, quizzes.ErrInvalidAnswer
+	}
+	credits := map[uuid.UUID]float64{}
+	if question.StrategicAnswerId != nil{
+		credits[*question.StrategicAnswerId] = weight(value)
+	}
+	if creditOptions != nil{
+		creditOptions(value, credits)
+	}
+	return credits, nil
+}
+
+// adds the weight of the option, scaled by the factor, to its strategic type
+func creditOption(credits map[uuid.UUID]float64, option questionOption, factor float64){
+	if option.StrategicAnswerId == nil{
+		return
+	}
+	credits[*option.StrategicAnswerId] += getOptionWeight(option) * factor
+}
+
+func getOptionWeight(option questionOption) float64{
+	if option.StrategicWeight == nil{
+		return 1
+	}
+	return *option.StrategicWeight
+}
+
+// match if both sets are equal, similarity is the intersection over the union
+func setOverlap[T comparable](own, partner []T) (bool, float64){
+	union := make(map[T]bool, len(own) + len(partner))
+	ownSet := make(map[T]bool, len(own))
+	for _, value := range own{
+		ownSet[value] = true
+		union[value] = true
+	}
+	intersection := 0
+	for _, value := range partner{
+		if ownSet[value]{
+			intersection++
+			delete(ownSet, value)
+		}
+		union[value] = true
+	}
+	return intersection == len(union), ratio(intersection, len(union))
+}
+
+func ratio(part, total int) float64{
+	if total == 0{
+		return 1
+	}
+	return float64(part) / float64(total)
+}
@@ -0,0 +1,106 @@
+package appquizzes
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/google/uuid"
+)
+
+// reminds periodically the invitee of the invitations that are still unanswered after remindAfter,
+// and expires the ones that ran out of time, until it's stopped
+type InvitationsReminder struct{
+	repo 			quizzes.QuizzesRepository
+	publisher 		events.Publisher
+	remindAfter 	time.Duration
+	interval 		time.Duration
+	stop 			chan struct{}
+	done 			chan struct{}
+	stopOnce 		sync.Once
+}
+
+func NewInvitationsReminder(repo quizzes.QuizzesRepository, publisher events.Publisher, remindAfter time.Duration, interval time.Duration) *InvitationsReminder{
+	return &InvitationsReminder{
+		repo: repo,
+		publisher: publisher,
+		remindAfter: remindAfter,
+		interval: interval,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+func (r *InvitationsReminder) Start(){
+	go func(){
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		r.check()
+		for{
+			select{
+			case <- r.stop:
+				return
+			case <- ticker.C:
+				r.check()
+			}
+		}
+	}()
+}
+
+// waits until the running check finishes
+func (r *InvitationsReminder) Stop(){
+	r.stopOnce.Do(func(){
+		close(r.stop)
+		<- r.done
+	})
+}
+
+func (r *InvitationsReminder) check(){
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	now := time.Now()
+
+	expired, err := r.repo.ExpireQuizInvitations(ctx, now)
+	if err != nil{
+		log.Print("error expiring quiz invitations: ", err.Error())
+	}
+	//the inviter is the one told about the expiration
+	for _, invitation := range expired{
+		r.publish(events.EVENT_QUIZ_INVITATION_EXPIRED, &invitation, invitation.InviteeId)
+	}
+
+	toRemind, err := r.repo.GetQuizInvitationsToRemind(ctx, now.Add(-r.remindAfter), now)
+	if err != nil{
+		log.Print("error getting quiz invitations to remind: ", err.Error())
+		return
+	}
+	for _, invitation := range toRemind{
+		invitation.RemindedAt = &now
+		if _, err := r.repo.UpdateQuizInvitation(ctx, &invitation); err != nil{
+			log.Print("error updating reminded quiz invitation: ", err.Error())
+			continue
+		}
+		r.publish(events.EVENT_QUIZ_INVITATION_REMINDER, &invitation, invitation.InviterId)
+	}
+	if len(expired) > 0 || len(toRemind) > 0{
+		log.Printf("Expired %d and reminded %d quiz invitations", len(expired), len(toRemind))
+	}
+}
+
+// the events reach the partner of the given user
+func (r *InvitationsReminder) publish(eventType string, invitation *quizzes.QuizInvitationPlainModel, userId uuid.UUID){
+	r.publisher.Publish(context.Background(), events.EventModel{
+		Type: eventType,
+		CoupleId: invitation.CoupleId,
+		UserId: userId,
+		Data: map[string]any{
+			"quizId" : invitation.QuizId,
+			"invitationId" : invitation.Id,
+			"expiresAt" : invitation.ExpiresAt,
+		},
+	})
+}
@@ -0,0 +1,204 @@
+package appquizzes
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+var invitationsStatus = map[string]bool{
+	quizzes.INVITATION_PENDING : true,
+	quizzes.INVITATION_ACCEPTED : true,
+	quizzes.INVITATION_EXPIRED : true,
+}
+
+// the play of the user is started (or marked) as shared, and the partner can play the same quiz later
+func (s *UserService) InvitePartnerToQuiz(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*quizzes.QuizInvitationModel, error){
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, quizzes.ErrUserWithoutCouple
+	}else if err != nil{
+		return nil, quizzes.ErrInvitingPartner
+	}
+	partnerId := couple.GetPartnerId(userId)
+	if partnerPlay, err := s.repo.GetCoupleQuizPlayed(ctx, quizId, partnerId, &couple.Id); err != nil{
+		return nil, quizzes.ErrInvitingPartner
+	}else if partnerPlay != nil{
+		return nil, quizzes.ErrPartnerAlreadyPlayed
+	}
+	pendingStatus := quizzes.INVITATION_PENDING
+	pending, err := s.repo.GetQuizInvitations(ctx, quizzes.QuizInvitationFilter{CoupleId: &couple.Id, QuizId: &quizId, Status: &pendingStatus})
+	if err != nil{
+		return nil, quizzes.ErrInvitingPartner
+	}
+
+	invitation := quizzes.QuizInvitationPlainModel{
+		Id: uuid.New(),
+		QuizId: quizId,
+		CoupleId: couple.Id,
+		InviterId: userId,
+		InviteeId: partnerId,
+		Status: quizzes.INVITATION_PENDING,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Duration(s.invitationLife) * time.Second),
+	}
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		for _, old := range pending{
+			if time.Now().Before(old.ExpiresAt){
+				return quizzes.ErrQuizInvitationAlreadyExists
+			}
+			//expired but not swept yet
+			old.Status = quizzes.INVITATION_EXPIRED
+			if _, err := s.repo.UpdateQuizInvitation(ctx, &old); err != nil{
+				return quizzes.ErrInvitingPartner
+			}
+		}
+		play, err := s.startPlay(ctx, quizId, userId, true, quizzes.PLAY_MODE_NORMAL)
+		if err != nil{
+			return err
+		}
+		invitation.PlayId = play.Id
+		if num, err := s.repo.CreateQuizInvitation(ctx, &invitation); err != nil || num == 0{
+			return quizzes.ErrInvitingPartner
+		}
+		s.publishCoupleEvent(ctx, userId, events.EVENT_QUIZ_INVITATION, map[string]any{
+			"quizId" : quizId,
+			"invitationId" : invitation.Id,
+			"expiresAt" : invitation.ExpiresAt,
+		})
+		return nil
+	})
+	if err != nil{
+		return nil, err
+	}
+	return s.invitationToModel(ctx, &invitation, userId)
+}
+
+
+// the invitations sent and received in the current couple, optionally filtered by status
+func (s *UserService) GetQuizInvitations(ctx context.Context, userId uuid.UUID, status *string) ([]quizzes.QuizInvitationModel, error){
+	if status != nil && !invitationsStatus[*status]{
+		return nil, quizzes.ErrInvalidInvitationStatus
+	}
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, quizzes.ErrUserWithoutCouple
+	}else if err != nil{
+		return nil, quizzes.ErrRetrievingInvitations
+	}
+	invitations, err := s.repo.GetQuizInvitations(ctx, quizzes.QuizInvitationFilter{CoupleId: &couple.Id, Status: status})
+	if err != nil{
+		return nil, quizzes.ErrRetrievingInvitations
+	}
+	models := make([]quizzes.QuizInvitationModel, 0, len(invitations))
+	for _, invitation := range invitations{
+		model, err := s.invitationToModel(ctx, &invitation, userId)
+		if err != nil{
+			return nil, err
+		}
+		models = append(models, *model)
+	}
+	return models, nil
+}
+
+
+func (s *UserService) AcceptQuizInvitation(ctx context.Context, invitationId uuid.UUID, userId uuid.UUID) (*uuid.UUID, error){
+	invitation, err := s.repo.GetQuizInvitationById(ctx, invitationId)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingInvitations
+	}else if invitation == nil || invitation.InviteeId != userId{
+		return nil, quizzes.ErrQuizInvitationNotFound
+	}
+	switch{
+	case invitation.Status == quizzes.INVITATION_ACCEPTED:
+		return nil, quizzes.ErrQuizInvitationAlreadyAccepted
+	case invitation.Status == quizzes.INVITATION_EXPIRED || time.Now().After(invitation.ExpiresAt):
+		return nil, quizzes.ErrQuizInvitationExpired
+	}
+	//the invitations of a previous couple can't be accepted
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if err != nil || couple.Id != invitation.CoupleId{
+		return nil, quizzes.ErrQuizInvitationNotFound
+	}
+	return s.StartQuiz(ctx, invitation.QuizId, userId, quizzes.PLAY_MODE_NORMAL)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+// returns the invitation of the current partner to the quiz that the user can still accept
+func (s *UserService) getPendingInvitation(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*quizzes.QuizInvitationPlainModel, error){
+	pendingStatus := quizzes.INVITATION_PENDING
+	invitations, err := s.repo.GetQuizInvitations(ctx, quizzes.QuizInvitationFilter{QuizId: &quizId, InviteeId: &userId, Status: &pendingStatus})
+	if err != nil{
+		return nil, err
+	}
+	for _, invitation := range invitations{
+		if time.Now().After(invitation.ExpiresAt){
+			continue
+		}
+		couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+		if errors.Is(err, users.ErrorNoCoupleFound){
+			return nil, nil
+		}else if err != nil{
+			return nil, err
+		}
+		if couple.Id == invitation.CoupleId{
+			return &invitation, nil
+		}
+	}
+	return nil, nil
+}
+
+// only the inviter gets the play of the invitation, the invitee gets its own when accepting
+func (s *UserService) invitationToModel(ctx context.Context, invitation *quizzes.QuizInvitationPlainModel, userId uuid.UUID) (*quizzes.QuizInvitationModel, error){
+	model := &quizzes.QuizInvitationModel{
+		Id: invitation.Id,
+		QuizId: invitation.QuizId,
+		InviterId: invitation.InviterId,
+		InviteeId: invitation.InviteeId,
+		Status: invitation.Status,
+		CreatedAt: invitation.CreatedAt,
+		ExpiresAt: invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+	}
+	if invitation.InviterId == userId{
+		model.PlayId = &invitation.PlayId
+	}
+	if model.Status == quizzes.INVITATION_PENDING && time.Now().After(invitation.ExpiresAt){
+		model.Status = quizzes.INVITATION_EXPIRED
+	}
+	if model.Status != quizzes.INVITATION_ACCEPTED{
+		return model, nil
+	}
+	for _, playerId := range []uuid.UUID{invitation.InviterId, invitation.InviteeId}{
+		completed, err := s.hasCompletedQuiz(ctx, invitation.QuizId, playerId)
+		if err != nil{
+			return nil, quizzes.ErrRetrievingInvitations
+		}else if !completed{
+			return model, nil
+		}
+	}
+	model.ResultsUnlocked = true
+	return model, nil
+}
+
+// tells the couple that the comparison is available once the second partner completes a shared play
+func (s *UserService) notifyResultsUnlocked(ctx context.Context, play *quizzes.QuizPlayedPlainModel){
+	couple, err := s.userService.GetCoupleFromUser(ctx, play.UserId)
+	if err != nil{
+		return
+	}
+	completed, err := s.hasCompletedQuiz(ctx, play.QuizId, couple.GetPartnerId(play.UserId))
+	if err != nil || !completed{
+		return
+	}
+	s.publishCoupleEvent(ctx, play.UserId, events.EVENT_QUIZ_RESULTS_UNLOCKED, map[string]any{
+		"quizId" : play.QuizId,
+	})
+}
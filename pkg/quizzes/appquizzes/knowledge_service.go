@@ -0,0 +1,186 @@
+package appquizzes
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+var playModes = map[string]bool{
+	quizzes.PLAY_MODE_NORMAL : true,
+	quizzes.PLAY_MODE_GUESS : true,
+}
+
+// how well the partners know each other in the quiz, from the guesses scored when both completed it
+func (s *UserService) GetQuizKnowledge(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*quizzes.KnowledgeModel, error){
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, quizzes.ErrUserWithoutCouple
+	}else if err != nil{
+		return nil, quizzes.ErrRetrievingKnowledge
+	}
+	guesses, err := s.repo.GetQuizGuesses(ctx, quizzes.QuizGuessesFilter{CoupleId: &couple.Id, QuizId: &quizId})
+	if err != nil{
+		return nil, quizzes.ErrRetrievingKnowledge
+	}
+	knowledge := guessesToKnowledge(guesses, userId)
+	knowledge.QuizId = &quizId
+	return knowledge, nil
+}
+
+
+// how well the partners know each other across every quiz they played as this couple
+func (s *UserService) GetCoupleKnowledge(ctx context.Context, userId uuid.UUID) (*quizzes.KnowledgeModel, error){
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, quizzes.ErrUserWithoutCouple
+	}else if err != nil{
+		return nil, quizzes.ErrRetrievingKnowledge
+	}
+	guesses, err := s.repo.GetQuizGuesses(ctx, quizzes.QuizGuessesFilter{CoupleId: &couple.Id})
+	if err != nil{
+		return nil, quizzes.ErrRetrievingKnowledge
+	}
+	return guessesToKnowledge(guesses, userId), nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+// once both partners completed the quiz, the guesses of each one are scored against the answers
+// of the other, it runs inside the transaction that completes the play. The couple quiz is locked
+// before reading the partner play, so when both complete at the same time the last one waits for
+// the first to commit and sees its play completed
+func (s *UserService) scoreGuesses(ctx context.Context, play *quizzes.QuizPlayedPlainModel, questions []quizzes.QuestionPlainModel) error{
+	couple, err := s.userService.GetCoupleFromUser(ctx, play.UserId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil
+	}else if err != nil{
+		return quizzes.ErrScoringGuesses
+	}
+	//plays started before the couple aren't guesses about this partner
+	if play.CoupleId == nil || *play.CoupleId != couple.Id{
+		return nil
+	}
+	if err := s.repo.LockCoupleQuiz(ctx, couple.Id, play.QuizId); err != nil{
+		return quizzes.ErrScoringGuesses
+	}
+	partnerId := couple.GetPartnerId(play.UserId)
+	partnerPlay, err := s.repo.GetCoupleQuizPlayed(ctx, play.QuizId, partnerId, &couple.Id)
+	if err != nil{
+		return quizzes.ErrScoringGuesses
+	}else if partnerPlay == nil || partnerPlay.CompletedAt == nil{
+		return nil
+	}
+	answers := make(map[uuid.UUID]map[uuid.UUID]quizzes.UserAnswerPlainModel, 2)
+	for _, p := range []*quizzes.QuizPlayedPlainModel{play, partnerPlay}{
+		answers[p.UserId], err = s.getAnswersByQuestion(ctx, p.Id)
+		if err != nil{
+			return quizzes.ErrScoringGuesses
+		}
+	}
+
+	for _, guesserPlay := range []*quizzes.QuizPlayedPlainModel{play, partnerPlay}{
+		guesserId := guesserPlay.UserId
+		scored, err := s.repo.GetQuizGuesses(ctx, quizzes.QuizGuessesFilter{CoupleId: &couple.Id, QuizId: &play.QuizId, UserId: &guesserId})
+		if err != nil{
+			return quizzes.ErrScoringGuesses
+		}else if len(scored) > 0{
+			continue
+		}
+		right, total, err := s.rateGuesses(questions, answers[guesserId], answers[couple.GetPartnerId(guesserId)])
+		if err != nil{
+			return err
+		}else if total == 0{
+			continue
+		}
+		model := quizzes.QuizGuessesPlainModel{
+			Id: uuid.New(),
+			PlayId: guesserPlay.Id,
+			QuizId: play.QuizId,
+			CoupleId: couple.Id,
+			UserId: guesserId,
+			GuessedRight: right,
+			Guesses: total,
+			ScoredAt: time.Now(),
+		}
+		if num, err := s.repo.CreateQuizGuesses(ctx, &model); err != nil || num == 0{
+			return quizzes.ErrScoringGuesses
+		}
+		points := int(math.Round(right * quizzes.POINTS_PER_RIGHT_GUESS))
+		if points == 0{
+			continue
+		}
+		if err := s.pointsService.AwardPoints(ctx, guesserId, points, users.POINTS_REASON_QUIZ_GUESSED, &guesserPlay.Id); err != nil{
+			return quizzes.ErrScoringGuesses
+		}
+	}
+	return nil
+}
+
+// the own answer of the partner to the same question works as the key of every guess
+func (s *UserService) rateGuesses(questions []quizzes.QuestionPlainModel, guesserAnswers, partnerAnswers map[uuid.UUID]quizzes.UserAnswerPlainModel) (float64, int, error){
+	right, total := 0.0, 0
+	for _, q := range questions{
+		guesser, ok1 := guesserAnswers[q.Id]
+		partner, ok2 := partnerAnswers[q.Id]
+		if !ok1 || !ok2{
+			continue
+		}
+		guess, err := getGuess(guesser.Answers)
+		if err != nil{
+			return 0, 0, quizzes.ErrScoringGuesses
+		}else if guess == nil{
+			continue
+		}
+		actual, err := getOwnAnswer(partner.Answers)
+		if err != nil{
+			return 0, 0, quizzes.ErrScoringGuesses
+		}
+		scorer, ok := s.scorers[q.QuestionType]
+		if !ok{
+			return 0, 0, quizzes.ErrInvalidQuestionType
+		}
+		rightness, err := scorer(&q, guess, actual)
+		if err != nil{
+			return 0, 0, quizzes.ErrScoringGuesses
+		}
+		right += rightness
+		total++
+	}
+	return right, total, nil
+}
+
+func guessesToKnowledge(guesses []quizzes.QuizGuessesPlainModel, userId uuid.UUID) *quizzes.KnowledgeModel{
+	var ownRight, partnerRight float64
+	var ownTotal, partnerTotal int
+	for _, guess := range guesses{
+		if guess.UserId == userId{
+			ownRight += guess.GuessedRight
+			ownTotal += guess.Guesses
+		}else{
+			partnerRight += guess.GuessedRight
+			partnerTotal += guess.Guesses
+		}
+	}
+	return &quizzes.KnowledgeModel{
+		YouKnowPartner: knowledgePercentage(ownRight, ownTotal),
+		PartnerKnowsYou: knowledgePercentage(partnerRight, partnerTotal),
+		Couple: knowledgePercentage(ownRight + partnerRight, ownTotal + partnerTotal),
+		Guesses: ownTotal + partnerTotal,
+	}
+}
+
+// rounded to two decimals
+func knowledgePercentage(right float64, total int) *float64{
+	if total == 0{
+		return nil
+	}
+	percentage := math.Round(right / float64(total) * 10000) / 100
+	return &percentage
+}
@@ -0,0 +1,326 @@
+package appquizzes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+type liveSessionKey struct{
+	coupleId 	uuid.UUID
+	quizId 		uuid.UUID
+}
+
+// the questions are rendered for each partner, so the placeholders show the right names
+type liveSession struct{
+	key 		liveSessionKey
+	order 		[]uuid.UUID
+	questions 	map[uuid.UUID]map[uuid.UUID]quizzes.QuestionModel
+	answered 	map[uuid.UUID]map[uuid.UUID]bool
+	plays 		map[uuid.UUID]uuid.UUID
+	connections map[uuid.UUID]*quizzes.LiveConnectionModel
+	current 	int
+	timer 		*time.Timer
+}
+
+// the sessions are kept in memory, both partners must be connected to the same instance
+type LiveServiceImpl struct{
+	userService 	quizzes.UserService
+	usersService 	users.UsersService
+	questionTime 	time.Duration
+	sessions 		map[liveSessionKey]*liveSession
+	mutex 			sync.Mutex
+}
+
+func NewLiveServiceImpl(userService quizzes.UserService, usersService users.UsersService, questionTime time.Duration) quizzes.LiveService{
+	return &LiveServiceImpl{
+		userService: userService,
+		usersService: usersService,
+		questionTime: questionTime,
+		sessions: make(map[liveSessionKey]*liveSession),
+	}
+}
+
+// the play of the user is started (or resumed) with the normal play flow, the answers of both
+// partners are persisted as they arrive
+func (s *LiveServiceImpl) JoinLiveSession(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*quizzes.LiveConnectionModel, error){
+	couple, err := s.usersService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, quizzes.ErrUserWithoutCouple
+	}else if err != nil{
+		return nil, quizzes.ErrJoiningLiveSession
+	}
+	playId, err := s.userService.StartQuiz(ctx, quizId, userId, quizzes.PLAY_MODE_NORMAL)
+	if err != nil{
+		return nil, err
+	}
+	questions, err := s.userService.GetPlayQuestions(ctx, *playId, userId)
+	if err != nil{
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := liveSessionKey{coupleId: couple.Id, quizId: quizId}
+	session, ok := s.sessions[key]
+	if !ok{
+		session = &liveSession{
+			key: key,
+			questions: make(map[uuid.UUID]map[uuid.UUID]quizzes.QuestionModel),
+			answered: make(map[uuid.UUID]map[uuid.UUID]bool),
+			plays: make(map[uuid.UUID]uuid.UUID),
+			connections: make(map[uuid.UUID]*quizzes.LiveConnectionModel),
+			current: -1,
+		}
+		s.sessions[key] = session
+	}
+	//the order is taken from the quiz_questions ordering, it can only change before the session starts
+	if session.current < 0{
+		session.order = make([]uuid.UUID, 0, len(questions))
+		for _, question := range questions{
+			session.order = append(session.order, question.Id)
+		}
+	}
+	session.questions[userId] = make(map[uuid.UUID]quizzes.QuestionModel, len(questions))
+	for _, question := range questions{
+		session.questions[userId][question.Id] = question
+		if question.Answered{
+			session.setAnswered(question.Id, userId)
+		}
+	}
+	session.plays[userId] = *playId
+
+	//a new connection of the same user replaces the old one
+	if old, ok := session.connections[userId]; ok{
+		delete(session.connections, userId)
+		close(old.Messages)
+	}
+	connection := &quizzes.LiveConnectionModel{
+		QuizId: quizId,
+		CoupleId: couple.Id,
+		UserId: userId,
+		PlayId: *playId,
+		Messages: make(chan quizzes.LiveMessageModel, quizzes.LIVE_MESSAGES_BUFFER_SIZE),
+	}
+	session.connections[userId] = connection
+
+	partnerId := couple.GetPartnerId(userId)
+	if _, ok := session.connections[partnerId]; !ok{
+		s.send(session, userId, quizzes.LiveMessageModel{Type: quizzes.LIVE_WAITING_PARTNER})
+		return connection, nil
+	}
+	s.send(session, partnerId, quizzes.LiveMessageModel{Type: quizzes.LIVE_PARTNER_JOINED})
+	s.send(session, userId, quizzes.LiveMessageModel{Type: quizzes.LIVE_PARTNER_JOINED})
+	if session.current < 0{
+		s.advance(session)
+	}else{
+		//the session was paused, the current question starts again with a new deadline
+		s.sendQuestion(session)
+	}
+	return connection, nil
+}
+
+func (s *LiveServiceImpl) SetAnswering(connection *quizzes.LiveConnectionModel, questionId uuid.UUID){
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, err := s.getCurrentSession(connection, questionId)
+	if err != nil{
+		return
+	}
+	for userId := range session.connections{
+		if userId != connection.UserId{
+			s.send(session, userId, quizzes.LiveMessageModel{Type: quizzes.LIVE_PARTNER_ANSWERING, QuestionId: &questionId})
+		}
+	}
+}
+
+func (s *LiveServiceImpl) AnswerLiveQuestion(ctx context.Context, connection *quizzes.LiveConnectionModel, questionId uuid.UUID, answer quizzes.AnswerRequest) error{
+	s.mutex.Lock()
+	_, err := s.getCurrentSession(connection, questionId)
+	s.mutex.Unlock()
+	if err != nil{
+		return err
+	}
+
+	//the answer is stored without holding the sessions
+	if err := s.userService.AnswerQuestion(ctx, connection.PlayId, questionId, connection.UserId, answer); err != nil{
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, err := s.getCurrentSession(connection, questionId)
+	if err != nil{
+		//the time ran out meanwhile, the answer is kept but the session already moved on
+		return nil
+	}
+	session.setAnswered(questionId, connection.UserId)
+	for userId := range session.connections{
+		if userId != connection.UserId{
+			s.send(session, userId, quizzes.LiveMessageModel{Type: quizzes.LIVE_PARTNER_ANSWERED, QuestionId: &questionId})
+		}
+	}
+	if session.answeredByBoth(questionId){
+		s.advance(session)
+	}
+	return nil
+}
+
+// the session is paused until the user comes back, and removed once both partners left
+func (s *LiveServiceImpl) LeaveLiveSession(connection *quizzes.LiveConnectionModel){
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, ok := s.sessions[liveSessionKey{coupleId: connection.CoupleId, quizId: connection.QuizId}]
+	if !ok || session.connections[connection.UserId] != connection{
+		return
+	}
+	s.removeConnection(session, connection.UserId)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+func (l *liveSession) setAnswered(questionId uuid.UUID, userId uuid.UUID){
+	if _, ok := l.answered[questionId]; !ok{
+		l.answered[questionId] = make(map[uuid.UUID]bool)
+	}
+	l.answered[questionId][userId] = true
+}
+
+func (l *liveSession) answeredByBoth(questionId uuid.UUID) bool{
+	return len(l.answered[questionId]) >= 2
+}
+
+// must be called with the mutex locked, returns the session only if the connection is active,
+// the partner is connected and the question is the one being played
+func (s *LiveServiceImpl) getCurrentSession(connection *quizzes.LiveConnectionModel, questionId uuid.UUID) (*liveSession, error){
+	session, ok := s.sessions[liveSessionKey{coupleId: connection.CoupleId, quizId: connection.QuizId}]
+	if !ok || session.connections[connection.UserId] != connection{
+		return nil, quizzes.ErrLiveSessionNotStarted
+	}
+	if session.current < 0 || len(session.connections) < 2{
+		return nil, quizzes.ErrLiveSessionNotStarted
+	}
+	if session.order[session.current] != questionId{
+		return nil, quizzes.ErrQuestionNotCurrent
+	}
+	return session, nil
+}
+
+// must be called with the mutex locked, the questions both partners already answered are skipped
+func (s *LiveServiceImpl) advance(session *liveSession){
+	session.current++
+	for session.current < len(session.order) && session.answeredByBoth(session.order[session.current]){
+		session.current++
+	}
+	if session.current >= len(session.order){
+		s.finish(session)
+		return
+	}
+	s.sendQuestion(session)
+}
+
+// must be called with the mutex locked
+func (s *LiveServiceImpl) sendQuestion(session *liveSession){
+	if session.timer != nil{
+		session.timer.Stop()
+	}
+	index := session.current
+	questionId := session.order[index]
+	deadline := time.Now().Add(s.questionTime)
+	session.timer = time.AfterFunc(s.questionTime, func(){
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		//the question was already answered, or the session paused or ended
+		if s.sessions[session.key] != session || session.current != index || len(session.connections) < 2{
+			return
+		}
+		s.advance(session)
+	})
+
+	for userId := range session.connections{
+		question, ok := session.questions[userId][questionId]
+		if !ok{
+			continue
+		}
+		question.Answered = session.answered[questionId][userId]
+		s.send(session, userId, quizzes.LiveMessageModel{
+			Type: quizzes.LIVE_QUESTION,
+			Question: &question,
+			Number: index + 1,
+			Total: len(session.order),
+			Deadline: &deadline,
+		})
+		//when resuming, the partner may have answered already
+		for partnerId := range session.answered[questionId]{
+			if partnerId != userId{
+				s.send(session, userId, quizzes.LiveMessageModel{Type: quizzes.LIVE_PARTNER_ANSWERED, QuestionId: &questionId})
+			}
+		}
+	}
+}
+
+// must be called with the mutex locked, the plays are completed outside the lock and
+// each partner receives its result before the connection is closed
+func (s *LiveServiceImpl) finish(session *liveSession){
+	if session.timer != nil{
+		session.timer.Stop()
+	}
+	delete(s.sessions, session.key)
+	connections := session.connections
+	session.connections = make(map[uuid.UUID]*quizzes.LiveConnectionModel)
+	plays := session.plays
+
+	go func(){
+		for userId, playId := range plays{
+			message := quizzes.LiveMessageModel{Type: quizzes.LIVE_FINISHED}
+			//if the time ran out on some question the play stays open to be completed later
+			play, err := s.userService.CompleteQuiz(context.Background(), playId, userId)
+			if err == nil{
+				message.Play = play
+			}
+			if connection, ok := connections[userId]; ok{
+				select{
+				case connection.Messages <- message:
+				default:
+				}
+				close(connection.Messages)
+			}
+		}
+	}()
+}
+
+// must be called with the mutex locked, a connection that can't keep up is dropped
+func (s *LiveServiceImpl) send(session *liveSession, userId uuid.UUID, message quizzes.LiveMessageModel){
+	connection, ok := session.connections[userId]
+	if !ok{
+		return
+	}
+	select{
+	case connection.Messages <- message:
+	default:
+		s.removeConnection(session, userId)
+	}
+}
+
+// must be called with the mutex locked
+func (s *LiveServiceImpl) removeConnection(session *liveSession, userId uuid.UUID){
+	connection := session.connections[userId]
+	delete(session.connections, userId)
+	close(connection.Messages)
+	if session.timer != nil{
+		session.timer.Stop()
+	}
+	if len(session.connections) == 0{
+		delete(s.sessions, session.key)
+		return
+	}
+	for partnerId := range session.connections{
+		s.send(session, partnerId, quizzes.LiveMessageModel{Type: quizzes.LIVE_PARTNER_LEFT})
+	}
+}
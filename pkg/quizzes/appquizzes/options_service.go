@@ -0,0 +1,134 @@
+package appquizzes
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/google/uuid"
+)
+
+func (s *UserService) CreateQuestionOptions(ctx context.Context, questionType string, parentId uuid.UUID, questionId uuid.UUID, optionsJson map[string]any, images map[string]io.Reader) (string, error){
+	creator, ok := s.creators[questionType]
+	if !ok{
+		return "", quizzes.ErrInvalidQuestionType
+	}
+	if err := validateInputPlaceholders(optionsJson); err != nil{
+		return "", err
+	}
+	inputOptionsJson, err := json.Marshal(optionsJson)
+	if err != nil{
+		return "", quizzes.ErrInvalidQuestionOptions
+	}
+	return creator(ctx, parentId, string(inputOptionsJson), images, questionId)
+}
+
+
+func (s *UserService) DeleteQuestionOptions(ctx context.Context, questionType string, optionsJson string) error{
+	deletor, ok := s.deletors[questionType]
+	if !ok{
+		return quizzes.ErrInvalidQuestionType
+	}
+	return deletor(ctx, &quizzes.QuestionPlainModel{QuestionType: questionType, OptionsJson: optionsJson})
+}
+
+
+func (s *UserService) ValidateAnswer(questionType string, optionsJson string, answer quizzes.AnswerRequest) (string, error){
+	validator, ok := s.answerValidators[questionType]
+	if !ok{
+		return "", quizzes.ErrInvalidQuestionType
+	}
+	return validator(&quizzes.QuestionPlainModel{QuestionType: questionType, OptionsJson: optionsJson}, answer)
+}
+
+
+// how right the stored answer is against the answer key of the question, from 0 to 1. Returns nil
+// if the question has no answer key
+func (s *UserService) ScoreAnswer(questionType string, optionsJson string, answerJson string) (*float64, error){
+	key, err := getAnswerKey(optionsJson)
+	if err != nil{
+		return nil, err
+	}else if key == nil{
+		return nil, nil
+	}
+	ownAnswer, err := getOwnAnswer(answerJson)
+	if err != nil{
+		return nil, err
+	}
+	scorer, ok := s.scorers[questionType]
+	if !ok{
+		return nil, quizzes.ErrInvalidQuestionType
+	}
+	rightness, err := scorer(&quizzes.QuestionPlainModel{QuestionType: questionType, OptionsJson: optionsJson}, ownAnswer, key)
+	if err != nil{
+		return nil, err
+	}
+	return &rightness, nil
+}
+
+
+func (s *UserService) ValidatePlaceholders(text string) error{
+	return validatePlaceholders(text)
+}
+
+
+// replaces the placeholders of the questions with the names of the user and their partner, and
+// hides the answer keys
+func (s *UserService) RenderQuestions(ctx context.Context, userId uuid.UUID, questions []quizzes.QuestionModel) error{
+	replacer, err := s.getPlaceholdersReplacer(ctx, userId)
+	if err != nil{
+		return err
+	}
+	for i := range questions{
+		if err := s.renderQuestion(replacer, &questions[i]); err != nil{
+			return err
+		}
+	}
+	return nil
+}
+
+
+// the players can't see the key of the question, nor the strategic types of the options, otherwise
+// they could pick the options to shape their profiles
+func (s *UserService) HideAnswerKey(optionsJson string) (string, error){
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(optionsJson), &options); err != nil{
+		return "", quizzes.ErrRetrievingQuestions
+	}
+	_, hidden := options[answerKeyField]
+	delete(options, answerKeyField)
+	for field, value := range options{
+		//only the lists of options have strategic types
+		var list []map[string]json.RawMessage
+		if err := json.Unmarshal(value, &list); err != nil{
+			continue
+		}
+		stripped := false
+		for _, option := range list{
+			for _, strategicField := range strategicOptionFields{
+				if _, ok := option[strategicField]; ok{
+					delete(option, strategicField)
+					stripped = true
+				}
+			}
+		}
+		if !stripped{
+			continue
+		}
+		listBytes, err := json.Marshal(list)
+		if err != nil{
+			return "", quizzes.ErrRetrievingQuestions
+		}
+		options[field] = listBytes
+		hidden = true
+	}
+	if !hidden{
+		return optionsJson, nil
+	}
+	jsonBytes, err := json.Marshal(options)
+	if err != nil{
+		return "", quizzes.ErrRetrievingQuestions
+	}
+	return string(jsonBytes), nil
+}
@@ -0,0 +1,144 @@
+package appquizzes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+// any % followed by a letter that isn't part of a known placeholder
+var invalidPlaceholderRegex = regexp.MustCompile(`%[A-Za-z]`)
+
+var placeholdersRemover = strings.NewReplacer(quizzes.YOU_PLACEHOLDER, "", quizzes.PARTNER_PLACEHOLDER, "")
+
+
+// checks that the text only contains known and well formed placeholders
+func validatePlaceholders(text string) error{
+	if invalidPlaceholderRegex.MatchString(placeholdersRemover.Replace(text)){
+		return quizzes.ErrInvalidPlaceholder
+	}
+	return nil
+}
+
+// walks the raw input options checking the placeholders of every text
+func validateInputPlaceholders(value any) error{
+	switch v := value.(type){
+	case string:
+		return validatePlaceholders(v)
+	case map[string]any:
+		for _, item := range v{
+			if err := validateInputPlaceholders(item); err != nil{
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v{
+			if err := validateInputPlaceholders(item); err != nil{
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+
+// returns the replacer of the placeholders for the user, the partner placeholder
+// is left as it is if the user has no couple
+func (s *UserService) getPlaceholdersReplacer(ctx context.Context, userId uuid.UUID) (*strings.Replacer, error){
+	user, err := s.userService.GetUserById(ctx, userId)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingQuestions
+	}
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return strings.NewReplacer(quizzes.YOU_PLACEHOLDER, user.FirstName), nil
+	}else if err != nil{
+		return nil, quizzes.ErrRetrievingQuestions
+	}
+	partnerId := couple.GetPartnerId(userId)
+	partner, err := s.userService.GetUserById(ctx, partnerId)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingQuestions
+	}
+	partnerName := partner.NickName
+	if partnerName == ""{
+		partnerName = partner.FirstName
+	}
+	return strings.NewReplacer(
+		quizzes.YOU_PLACEHOLDER, user.FirstName,
+		quizzes.PARTNER_PLACEHOLDER, partnerName,
+	), nil
+}
+
+
+// renders the question text and its options texts, without the answer key
+func (s *UserService) renderQuestion(replacer *strings.Replacer, question *quizzes.QuestionModel) error{
+	question.Question = replacer.Replace(question.Question)
+	options, err := mapOptionsTexts(question.QuestionType, string(question.Options), replacer.Replace)
+	if err != nil{
+		return quizzes.ErrRetrievingQuestions
+	}
+	options, err = s.HideAnswerKey(options)
+	if err != nil{
+		return err
+	}
+	question.Options = json.RawMessage(options)
+	return nil
+}
+
+
+// applies the mapper to every option text of the stored options JSON
+func mapOptionsTexts(questionType string, optionsJson string, mapper func(string) string) (string, error){
+	mapOptions := func(options []questionOption){
+		for i := range options{
+			options[i].Text = mapper(options[i].Text)
+		}
+	}
+	var output any
+	switch questionType{
+	case quizzes.ORDERING_TYPE:
+		var options orderingOptionsFormat
+		if err := json.Unmarshal([]byte(optionsJson), &options); err != nil{
+			return "", err
+		}
+		mapOptions(options.Options)
+		output = options
+	case quizzes.MULTIPLE_CH_TYPE:
+		var options multipleOptionsFormat
+		if err := json.Unmarshal([]byte(optionsJson), &options); err != nil{
+			return "", err
+		}
+		mapOptions(options.Options)
+		output = options
+	case quizzes.MATCHING_TYPE:
+		var options matchingOptionsFormat
+		if err := json.Unmarshal([]byte(optionsJson), &options); err != nil{
+			return "", err
+		}
+		mapOptions(options.Options1)
+		mapOptions(options.Options2)
+		output = options
+	case quizzes.DRAG_AND_DROP_TYPE:
+		var options dragAndDropOptionsFormat
+		if err := json.Unmarshal([]byte(optionsJson), &options); err != nil{
+			return "", err
+		}
+		mapOptions(options.Boxes)
+		mapOptions(options.Options)
+		output = options
+	default:
+		//the rest of the types don't have texts in their options
+		return optionsJson, nil
+	}
+	jsonBytes, err := json.Marshal(output)
+	if err != nil{
+		return "", err
+	}
+	return string(jsonBytes), nil
+}
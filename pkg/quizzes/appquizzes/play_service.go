@@ -0,0 +1,506 @@
+package appquizzes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+// a pending invitation of the partner to the quiz is accepted when the user starts it
+func (s *UserService) StartQuiz(ctx context.Context, quizId uuid.UUID, userId uuid.UUID, mode string) (*uuid.UUID, error){
+	if !playModes[mode]{
+		return nil, quizzes.ErrInvalidPlayMode
+	}
+	invitation, err := s.getPendingInvitation(ctx, quizId, userId)
+	if err != nil{
+		return nil, quizzes.ErrStartingQuiz
+	}
+	var playId uuid.UUID
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		play, err := s.startPlay(ctx, quizId, userId, invitation != nil, mode)
+		if err != nil{
+			return err
+		}
+		if play.CompletedAt != nil{
+			return quizzes.ErrQuizAlreadyPlayed
+		}
+		playId = play.Id
+		if invitation == nil{
+			return nil
+		}
+		acceptedAt := time.Now()
+		invitation.Status = quizzes.INVITATION_ACCEPTED
+		invitation.AcceptedAt = &acceptedAt
+		if num, err := s.repo.UpdateQuizInvitation(ctx, invitation); err != nil || num == 0{
+			return quizzes.ErrStartingQuiz
+		}
+		s.publishCoupleEvent(ctx, userId, events.EVENT_QUIZ_INVITATION_ACCEPTED, map[string]any{
+			"quizId" : quizId,
+			"invitationId" : invitation.Id,
+		})
+		return nil
+	})
+	if err != nil{
+		return nil, err
+	}
+	return &playId, nil
+}
+
+
+func (s *UserService) GetPlayQuestions(ctx context.Context, playId uuid.UUID, userId uuid.UUID) ([]quizzes.QuestionModel, error){
+	play, err := s.getUserPlay(ctx, playId, userId)
+	if err != nil{
+		return nil, err
+	}
+	questions, err := s.repo.GetQuestions(ctx, quizzes.QuestionFilter{QuizId: &play.QuizId})
+	if err != nil{
+		return nil, quizzes.ErrRetrievingQuestions
+	}
+	answers, err := s.repo.GetUserAnswersFromPlay(ctx, play.Id)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingQuestions
+	}
+	answered := make(map[uuid.UUID]bool, len(answers))
+	for _, answer := range answers{
+		answered[answer.QuestionId] = true
+	}
+	replacer, err := s.getPlaceholdersReplacer(ctx, userId)
+	if err != nil{
+		return nil, err
+	}
+
+	models := make([]quizzes.QuestionModel, 0, len(questions))
+	for _, q := range questions{
+		model := quizzes.QuestionModel{
+			Id: q.Id,
+			Ordering: q.Ordering,
+			Question: q.Question,
+			QuestionType: q.QuestionType,
+			Options: json.RawMessage(q.OptionsJson),
+			Answered: answered[q.Id],
+		}
+		if err := s.renderQuestion(replacer, &model); err != nil{
+			return nil, err
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+
+
+func (s *UserService) AnswerQuestion(ctx context.Context, playId uuid.UUID, questionId uuid.UUID, userId uuid.UUID, answer quizzes.AnswerRequest) error{
+	play, err := s.getUserPlay(ctx, playId, userId)
+	if err != nil{
+		return err
+	}
+	if play.CompletedAt != nil{
+		return quizzes.ErrQuizPlayAlreadyCompleted
+	}
+	question, err := s.repo.GetQuestionById(ctx, questionId)
+	if err != nil{
+		return quizzes.ErrAnsweringQuestion
+	}else if question == nil{
+		return quizzes.ErrQuestionNotFound
+	}
+	if question.QuizId != play.QuizId{
+		return quizzes.ErrQuestionNotInQuiz
+	}
+	if play.Mode == quizzes.PLAY_MODE_GUESS && isEmptyJson(answer.GuessedPartner){
+		return quizzes.ErrMissingGuess
+	}
+
+	validator, ok := s.answerValidators[question.QuestionType]
+	if !ok{
+		return quizzes.ErrInvalidQuestionType
+	}
+	answerJson, err := validator(question, answer)
+	if err != nil{
+		return err
+	}
+
+	// if the user already answered the question, the answer is replaced
+	previous, err := s.repo.GetUsersAnswers(ctx, quizzes.UserAnswerFilter{QuestionId: &questionId, PlayId: &play.Id})
+	if err != nil{
+		return quizzes.ErrAnsweringQuestion
+	}
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		var num int
+		var err error
+		var previousAnswer *string
+		if len(previous) > 0{
+			model := previous[0]
+			previousAnswer = &previous[0].Answers
+			model.Answers = answerJson
+			model.AnsweredAt = time.Now()
+			num, err = s.repo.UpdateUserAnswer(ctx, &model)
+		}else{
+			num, err = s.repo.CreateUserAnswer(ctx, &quizzes.UserAnswerPlainModel{
+				Id: uuid.New(),
+				UserId: userId,
+				QuestionId: questionId,
+				PlayId: &play.Id,
+				Answers: answerJson,
+				AnsweredAt: time.Now(),
+			})
+		}
+		if err != nil || num == 0{
+			return quizzes.ErrAnsweringQuestion
+		}
+		return s.updateStrategicProfile(ctx, question, userId, previousAnswer, answerJson)
+	})
+	if err != nil{
+		return err
+	}
+	s.publishCoupleEvent(ctx, userId, events.EVENT_QUESTION_ANSWERED, map[string]any{
+		"quizId" : play.QuizId,
+		"questionId" : questionId,
+	})
+	return nil
+}
+
+
+func (s *UserService) CompleteQuiz(ctx context.Context, playId uuid.UUID, userId uuid.UUID) (*quizzes.QuizPlayedModel, error){
+	play, err := s.getUserPlay(ctx, playId, userId)
+	if err != nil{
+		return nil, err
+	}
+	if play.CompletedAt != nil{
+		return nil, quizzes.ErrQuizPlayAlreadyCompleted
+	}
+	questions, err := s.repo.GetQuestions(ctx, quizzes.QuestionFilter{QuizId: &play.QuizId})
+	if err != nil{
+		return nil, quizzes.ErrCompletingQuiz
+	}
+	answers, err := s.repo.GetUserAnswersFromPlay(ctx, play.Id)
+	if err != nil{
+		return nil, quizzes.ErrCompletingQuiz
+	}
+	answersByQuestion := make(map[uuid.UUID]string, len(answers))
+	for _, answer := range answers{
+		answersByQuestion[answer.QuestionId] = answer.Answers
+	}
+	for _, q := range questions{
+		answer, ok := answersByQuestion[q.Id]
+		if !ok{
+			return nil, quizzes.ErrMissingAnswers
+		}
+		//the answers given before switching to the guess mode may not have the guess
+		if play.Mode == quizzes.PLAY_MODE_GUESS{
+			if guess, err := getGuess(answer); err != nil{
+				return nil, quizzes.ErrCompletingQuiz
+			}else if guess == nil{
+				return nil, quizzes.ErrMissingGuess
+			}
+		}
+	}
+
+	score, err := s.scorePlay(questions, answersByQuestion)
+	if err != nil{
+		return nil, err
+	}
+	completedAt := time.Now()
+	play.Score = &score
+	play.CompletedAt = &completedAt
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		if num, err := s.repo.CompleteQuizPlayed(ctx, play); err != nil{
+			return quizzes.ErrCompletingQuiz
+		}else if num == 0{
+			return quizzes.ErrQuizPlayAlreadyCompleted
+		}
+		if err := s.pointsService.AwardPoints(ctx, userId, score, users.POINTS_REASON_QUIZ_COMPLETED, &play.Id); err != nil{
+			return quizzes.ErrCompletingQuiz
+		}
+		return s.scoreGuesses(ctx, play, questions)
+	})
+	if err != nil{
+		return nil, err
+	}
+	if play.Shared{
+		s.notifyResultsUnlocked(ctx, play)
+	}
+	s.publishCoupleEvent(ctx, userId, events.EVENT_QUIZ_COMPLETED, map[string]any{
+		"quizId" : play.QuizId,
+		"playId" : play.Id,
+		"score" : score,
+	})
+	return &quizzes.QuizPlayedModel{
+		Id: play.Id,
+		QuizId: play.QuizId,
+		Mode: play.Mode,
+		Score: play.Score,
+		StartedAt: play.StartedAt,
+		CompletedAt: play.CompletedAt,
+	}, nil
+}
+
+
+// the partner answers are only revealed once both partners completed the quiz
+func (s *UserService) GetQuizComparison(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*quizzes.QuizComparisonModel, error){
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, quizzes.ErrUserWithoutCouple
+	}else if err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}
+	partnerId := couple.GetPartnerId(userId)
+
+	ownPlay, err := s.repo.GetCoupleQuizPlayed(ctx, quizId, userId, &couple.Id)
+	if err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}else if ownPlay == nil || ownPlay.CompletedAt == nil{
+		return nil, quizzes.ErrQuizNotCompleted
+	}
+	partnerPlay, err := s.repo.GetCoupleQuizPlayed(ctx, quizId, partnerId, &couple.Id)
+	if err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}else if partnerPlay == nil || partnerPlay.CompletedAt == nil{
+		return nil, quizzes.ErrPartnerHasntCompleted
+	}
+
+	questions, err := s.repo.GetQuestions(ctx, quizzes.QuestionFilter{QuizId: &quizId})
+	if err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}
+	ownAnswers, err := s.getAnswersByQuestion(ctx, ownPlay.Id)
+	if err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}
+	partnerAnswers, err := s.getAnswersByQuestion(ctx, partnerPlay.Id)
+	if err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}
+	replacer, err := s.getPlaceholdersReplacer(ctx, userId)
+	if err != nil{
+		return nil, err
+	}
+
+	comparison := &quizzes.QuizComparisonModel{
+		QuizId: quizId,
+		Questions: make([]quizzes.QuestionComparisonModel, 0, len(questions)),
+	}
+	for _, q := range questions{
+		own, ok1 := ownAnswers[q.Id]
+		partner, ok2 := partnerAnswers[q.Id]
+		//questions added after the plays can't be compared
+		if !ok1 || !ok2{
+			continue
+		}
+		comparator, ok := s.comparators[q.QuestionType]
+		if !ok{
+			return nil, quizzes.ErrInvalidQuestionType
+		}
+		match, similarity, err := comparator(&q, own.Answers, partner.Answers)
+		if err != nil{
+			return nil, err
+		}
+		if match{
+			comparison.Matches++
+		}
+		yourAnswer, err := getOwnAnswer(own.Answers)
+		if err != nil{
+			return nil, err
+		}
+		partnerAnswer, err := getOwnAnswer(partner.Answers)
+		if err != nil{
+			return nil, err
+		}
+		question := quizzes.QuestionModel{
+			Id: q.Id,
+			Ordering: q.Ordering,
+			Question: q.Question,
+			QuestionType: q.QuestionType,
+			Options: json.RawMessage(q.OptionsJson),
+			Answered: true,
+		}
+		if err := s.renderQuestion(replacer, &question); err != nil{
+			return nil, err
+		}
+		comparison.Questions = append(comparison.Questions, quizzes.QuestionComparisonModel{
+			Question: question,
+			YourAnswer: yourAnswer,
+			PartnerAnswer: partnerAnswer,
+			Match: match,
+			Similarity: similarity,
+		})
+	}
+	return comparison, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+// returns the play of the user in the quiz with their current couple (even if it's completed), or
+// starts a new one. Shared plays are the ones of a couple invitation. A play in progress can be
+// switched to the guess mode, but never back to the normal one
+func (s *UserService) startPlay(ctx context.Context, quizId uuid.UUID, userId uuid.UUID, shared bool, mode string) (*quizzes.QuizPlayedPlainModel, error){
+	quiz, err := s.repo.GetQuizById(ctx, quizId)
+	if err != nil{
+		return nil, quizzes.ErrStartingQuiz
+	}else if quiz == nil{
+		return nil, quizzes.ErrQuizNotFound
+	}
+	if !quiz.Published{
+		return nil, quizzes.ErrQuizNotPublished
+	}
+
+	//if there's already a play from the user, we resume it
+	play, coupleId, err := s.getCouplePlay(ctx, quizId, userId)
+	if err != nil{
+		return nil, quizzes.ErrStartingQuiz
+	}
+	if play != nil{
+		toGuessMode := play.CompletedAt == nil && mode == quizzes.PLAY_MODE_GUESS && play.Mode != mode
+		if (shared && !play.Shared) || toGuessMode{
+			play.Shared = play.Shared || shared
+			if toGuessMode{
+				play.Mode = mode
+			}
+			if num, err := s.repo.UpdateQuizPlayed(ctx, play); err != nil || num == 0{
+				return nil, quizzes.ErrStartingQuiz
+			}
+		}
+		return play, nil
+	}
+
+	model := quizzes.QuizPlayedPlainModel{
+		Id: uuid.New(),
+		QuizId: quizId,
+		UserId: userId,
+		CoupleId: coupleId,
+		Shared: shared,
+		Mode: mode,
+		StartedAt: time.Now(),
+	}
+	if num, err := s.repo.CreateQuizPlayed(ctx, &model); err != nil || num == 0{
+		return nil, quizzes.ErrStartingQuiz
+	}
+	s.publishCoupleEvent(ctx, userId, events.EVENT_QUIZ_STARTED, map[string]any{
+		"quizId" : quizId,
+		"playId" : model.Id,
+		"mode" : mode,
+	})
+	return &model, nil
+}
+
+// returns the play only if it belongs to the user
+func (s *UserService) getUserPlay(ctx context.Context, playId uuid.UUID, userId uuid.UUID) (*quizzes.QuizPlayedPlainModel, error){
+	play, err := s.repo.GetQuizPlayedById(ctx, playId)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingQuiz
+	}
+	if play == nil || play.UserId != userId{
+		return nil, quizzes.ErrQuizPlayNotFound
+	}
+	return play, nil
+}
+
+// the plays of the user with previous couples don't count, the couple id is nil for users without couple
+func (s *UserService) getCouplePlay(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*quizzes.QuizPlayedPlainModel, *uuid.UUID, error){
+	var coupleId *uuid.UUID
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if err == nil{
+		coupleId = &couple.Id
+	}else if !errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, nil, err
+	}
+	play, err := s.repo.GetCoupleQuizPlayed(ctx, quizId, userId, coupleId)
+	if err != nil{
+		return nil, nil, err
+	}
+	return play, coupleId, nil
+}
+
+func (s *UserService) hasCompletedQuiz(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (bool, error){
+	play, _, err := s.getCouplePlay(ctx, quizId, userId)
+	if err != nil{
+		return false, err
+	}
+	return play != nil && play.CompletedAt != nil, nil
+}
+
+func (s *UserService) getAnswersByQuestion(ctx context.Context, playId uuid.UUID) (map[uuid.UUID]quizzes.UserAnswerPlainModel, error){
+	answers, err := s.repo.GetUserAnswersFromPlay(ctx, playId)
+	if err != nil{
+		return nil, err
+	}
+	byQuestion := make(map[uuid.UUID]quizzes.UserAnswerPlainModel, len(answers))
+	for _, answer := range answers{
+		byQuestion[answer.QuestionId] = answer
+	}
+	return byQuestion, nil
+}
+
+// every answered question gives its points, and the ones with an answer key give more
+// depending on how right the own answer is
+func (s *UserService) scorePlay(questions []quizzes.QuestionPlainModel, answers map[uuid.UUID]string) (int, error){
+	score := 0
+	for _, q := range questions{
+		score += quizzes.POINTS_PER_ANSWERED_QUESTION
+		rightness, err := s.ScoreAnswer(q.QuestionType, q.OptionsJson, answers[q.Id])
+		if errors.Is(err, quizzes.ErrInvalidQuestionType){
+			return 0, err
+		}else if err != nil{
+			return 0, quizzes.ErrCompletingQuiz
+		}else if rightness == nil{
+			continue
+		}
+		score += int(math.Round(*rightness * quizzes.POINTS_PER_RIGHT_ANSWER))
+	}
+	return score, nil
+}
+
+
+// the event is only published for users with a couple, and once the changes are committed
+func (s *UserService) publishCoupleEvent(ctx context.Context, userId uuid.UUID, eventType string, data any){
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if err != nil{
+		return
+	}
+	infraestructure.AfterCommit(ctx, func(){
+		s.publisher.Publish(context.Background(), events.EventModel{
+			Type: eventType,
+			CoupleId: couple.Id,
+			UserId: userId,
+			Data: data,
+		})
+	})
+}
+
+// returns nil if the answer has no guess of the partner answer
+func getGuess(answerJson string) (json.RawMessage, error){
+	var stored storedAnswer[json.RawMessage]
+	if err := json.Unmarshal([]byte(answerJson), &stored); err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}
+	if stored.GuessedPartner == nil || isEmptyJson(*stored.GuessedPartner){
+		return nil, nil
+	}
+	return *stored.GuessedPartner, nil
+}
+
+// returns only the own answer of the stored answer, the guess about the partner stays private
+func getOwnAnswer(answerJson string) (json.RawMessage, error){
+	var stored storedAnswer[json.RawMessage]
+	if err := json.Unmarshal([]byte(answerJson), &stored); err != nil{
+		return nil, quizzes.ErrComparingAnswers
+	}
+	return stored.OwnAnswer, nil
+}
+
+
+// returns nil if the question has no answer key
+func getAnswerKey(optionsJson string) (json.RawMessage, error){
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(optionsJson), &options); err != nil{
+		return nil, err
+	}
+	return options[answerKeyField], nil
+}
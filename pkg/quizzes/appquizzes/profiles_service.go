@@ -0,0 +1,194 @@
+package appquizzes
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+// the types come in the language of the user when they are translated to it
+func (s *UserService) GetStrategicProfile(ctx context.Context, userId uuid.UUID) (*quizzes.StrategicProfileModel, error){
+	languageCode, err := s.userService.GetUserLanguage(ctx, userId)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingProfile
+	}
+	return s.getStrategicProfile(ctx, userId, languageCode)
+}
+
+
+// the compatibility is the part of both profiles that overlaps, so two partners with the same
+// shares of every strategic type are fully compatible
+func (s *UserService) GetCoupleCompatibility(ctx context.Context, userId uuid.UUID) (*quizzes.CompatibilityModel, error){
+	couple, err := s.userService.GetCoupleFromUser(ctx, userId)
+	if errors.Is(err, users.ErrorNoCoupleFound){
+		return nil, quizzes.ErrUserWithoutCouple
+	}else if err != nil{
+		return nil, quizzes.ErrRetrievingProfile
+	}
+	languageCode, err := s.userService.GetUserLanguage(ctx, userId)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingProfile
+	}
+	you, err := s.getStrategicProfile(ctx, userId, languageCode)
+	if err != nil{
+		return nil, err
+	}
+	partner, err := s.getStrategicProfile(ctx, couple.GetPartnerId(userId), languageCode)
+	if err != nil{
+		return nil, err
+	}
+	compatibility := &quizzes.CompatibilityModel{
+		You: *you,
+		Partner: *partner,
+	}
+	if len(you.Types) == 0 || len(partner.Types) == 0{
+		return compatibility, nil
+	}
+	partnerShares := make(map[uuid.UUID]float64, len(partner.Types))
+	for _, st := range partner.Types{
+		partnerShares[st.Id] = st.Share
+	}
+	overlap := 0.0
+	for _, st := range you.Types{
+		overlap += math.Min(st.Share, partnerShares[st.Id])
+	}
+	overlap = math.Round(overlap * 100) / 100
+	compatibility.Compatibility = &overlap
+	return compatibility, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+func (s *UserService) getStrategicProfile(ctx context.Context, userId uuid.UUID, languageCode string) (*quizzes.StrategicProfileModel, error){
+	scores, err := s.repo.GetStrategicProfile(ctx, userId, languageCode)
+	if err != nil{
+		return nil, quizzes.ErrRetrievingProfile
+	}
+	return buildStrategicProfile(userId, scores), nil
+}
+
+// resolves the strategic type of a question, by id or by name. With the catalog only mode the type
+// must be an active entry of the catalog, otherwise a name that isn't in the catalog creates the type
+// and an id that isn't in the catalog is ignored
+func (s *UserService) getStrategicType(ctx context.Context, id *uuid.UUID, name, description *string) (*uuid.UUID, error){
+	if id != nil{
+		st, err := s.repo.GetStrategicTypeAnswerById(ctx, *id)
+		if err != nil{
+			return nil, quizzes.ErrRetrievingStrategicTypes
+		}else if st == nil{
+			if s.strategicCatalogOnly{
+				return nil, quizzes.ErrStrategicTypeNotFound
+			}
+			return nil, nil
+		}else if !st.Active{
+			return nil, quizzes.ErrStrategicTypeRetired
+		}
+		return &st.Id, nil
+	}
+	if s.strategicCatalogOnly{
+		return nil, quizzes.ErrStrategicTypeNotInCatalog
+	}
+	if name == nil || strings.TrimSpace(*name) == ""{
+		return nil, nil
+	}
+	st, err := s.repo.GetStrategicTypeAnswerByName(ctx, strings.TrimSpace(*name))
+	if err != nil{
+		return nil, quizzes.ErrRetrievingStrategicTypes
+	}else if st != nil{
+		if !st.Active{
+			return nil, quizzes.ErrStrategicTypeRetired
+		}
+		return &st.Id, nil
+	}
+	model := quizzes.StrategicAnswerModel{
+		Id: uuid.New(),
+		Name: strings.TrimSpace(*name),
+		Active: true,
+	}
+	if description != nil{
+		model.Description = *description
+	}
+	if num, err := s.repo.CreateStrategicTypeAnswer(ctx, &model); err != nil || num == 0{
+		return nil, quizzes.ErrCreatingStrategicType
+	}
+	return &model.Id, nil
+}
+
+// takes back the credits of the replaced answer and adds the ones of the new answer, it runs
+// inside the transaction that stores the answer
+func (s *UserService) updateStrategicProfile(ctx context.Context, question *quizzes.QuestionPlainModel, userId uuid.UUID, previousAnswer *string, answerJson string) error{
+	crediter, ok := s.crediters[question.QuestionType]
+	if !ok{
+		return quizzes.ErrInvalidQuestionType
+	}
+	deltas := map[uuid.UUID]*quizzes.StrategicScorePlainModel{}
+	addCredits := func(answerJson string, sign int) error{
+		ownAnswer, err := getOwnAnswer(answerJson)
+		if err != nil{
+			return err
+		}
+		credits, err := crediter(question, ownAnswer)
+		if err != nil{
+			return err
+		}
+		for id, weight := range credits{
+			delta, ok := deltas[id]
+			if !ok{
+				delta = &quizzes.StrategicScorePlainModel{UserId: userId, StrategicAnswerId: id, UpdatedAt: time.Now()}
+				deltas[id] = delta
+			}
+			delta.Score += float64(sign) * weight
+			delta.Answers += sign
+		}
+		return nil
+	}
+	if previousAnswer != nil{
+		if err := addCredits(*previousAnswer, -1); err != nil{
+			return quizzes.ErrAnsweringQuestion
+		}
+	}
+	if err := addCredits(answerJson, 1); err != nil{
+		return quizzes.ErrAnsweringQuestion
+	}
+	for _, delta := range deltas{
+		if delta.Score == 0 && delta.Answers == 0{
+			continue
+		}
+		if num, err := s.repo.AddStrategicScore(ctx, delta); err != nil || num == 0{
+			return quizzes.ErrAnsweringQuestion
+		}
+	}
+	return nil
+}
+
+// the share of every type is its percentage of the total score of the user
+func buildStrategicProfile(userId uuid.UUID, scores []quizzes.StrategicScoreModel) *quizzes.StrategicProfileModel{
+	profile := &quizzes.StrategicProfileModel{
+		UserId: userId,
+		Types: make([]quizzes.StrategicScoreModel, 0, len(scores)),
+	}
+	total := 0.0
+	for _, score := range scores{
+		if score.Score > 0{
+			total += score.Score
+		}
+	}
+	if total == 0{
+		return profile
+	}
+	for _, score := range scores{
+		if score.Score <= 0{
+			continue
+		}
+		score.Share = math.Round(score.Score / total * 10000) / 100
+		profile.Types = append(profile.Types, score)
+	}
+	return profile
+}
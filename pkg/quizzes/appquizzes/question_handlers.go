@@ -11,9 +11,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// field of the options JSON where the answer key is stored
+const answerKeyField = "key"
+
+// fields of every option where its strategic type and weight are stored
+var strategicOptionFields = []string{"stId", "stW"}
+
+// the strategic type of an option is credited when the user picks it, the weight goes from 0 to 1
+// and it's 1 when not given. In matching and drag and drop the weight also scales the type of the
+// option it gets paired with
 type inputOption struct{
 	Text 		string	`json:"text" validate:"required"`
 	ImageName	string	`json:"imageName"`
+	StrategicAnswerId 	*uuid.UUID 	`json:"strategicAnswerId"`
+	StrategicWeight 	*float64 	`json:"strategicWeight"`
 }
 
 type questionOption struct{
@@ -21,27 +32,44 @@ type questionOption struct{
 	Text 		string	`json:"txt" validate:"required"`
 	ImageId		*uuid.UUID	`json:"imId"`
 	ImageUrl	*string		`json:"imUrl"`
+	StrategicAnswerId 	*uuid.UUID 	`json:"stId,omitempty"`
+	StrategicWeight 	*float64 	`json:"stW,omitempty"`
+}
+
+// TRUE FALSE AND SLIDER MODELS
+// these types only have the optional answer key
+
+type keyInput struct{
+	AnswerKey 	json.RawMessage 	`json:"answerKey"`
+}
+
+type keyOptionsFormat struct{
+	Key 	json.RawMessage 	`json:"key,omitempty"`
 }
 
 // ORDERING QUESTIONS MODELS
 type orderingInput struct{
 	SortingType		string 			`json:"sortingType" validate:"required"`
 	Options 		[]inputOption	`json:"options" validate:"required"`
+	AnswerKey 		json.RawMessage `json:"answerKey"`
 }
 
 type orderingOptionsFormat struct{
 	SortingType		string 				`json:"sortTp" validate:"required"`
 	Options 		[]questionOption	`json:"opts" validate:"required"`
+	Key 			json.RawMessage 	`json:"key,omitempty"`
 }
 
 // OPEN QUESTION MODELS 
 
 type openInput struct{
 	NumAnswers	int 	`json:"numAnswers" validate:"required"`
+	AnswerKey 	json.RawMessage `json:"answerKey"`
 }
 
 type openOptionsFormat struct{
 	NumAnswers int		`json:"nAnsw" validate:"required"`
+	Key 	json.RawMessage `json:"key,omitempty"`
 }
 
 
@@ -51,11 +79,13 @@ type openOptionsFormat struct{
 type multipleInput struct {
 	MultipleAnswer 		*bool 	`json:"multipleAnswer"`
 	Options 			[]inputOption	`json:"options" validate:"required"`
+	AnswerKey 			json.RawMessage `json:"answerKey"`
 }
 
 type multipleOptionsFormat struct{
 	MultipleAnswer 		bool 	`json:"multAns" validate:"required"`
 	Options 			[]questionOption	`json:"opts" validate:"required"`
+	Key 				json.RawMessage 	`json:"key,omitempty"`
 }
 
 //MATCHING OPTIONS
@@ -63,11 +93,13 @@ type multipleOptionsFormat struct{
 type matchingInput struct{
 	Options1 		[]inputOption	`json:"options1" validate:"required"`
 	Options2 		[]inputOption	`json:"options2" validate:"required"`
+	AnswerKey 		json.RawMessage `json:"answerKey"`
 }
 
 type matchingOptionsFormat struct{
 	Options1		[]questionOption	`json:"opts1" validate:"required"`
 	Options2		[]questionOption	`json:"opts2" validate:"required"`
+	Key 			json.RawMessage 	`json:"key,omitempty"`
 }
 
 // DRAG AND DROP OPTIONS
@@ -75,13 +107,14 @@ type matchingOptionsFormat struct{
 type dragAndDropInput struct{
 	Boxes 		[]inputOption	`json:"boxes" validate:"required"`
 	Options		[]inputOption	`json:"options" validate:"required"`
+	AnswerKey 	json.RawMessage `json:"answerKey"`
 }
 
 
 type dragAndDropOptionsFormat struct{
 	Boxes 	[]questionOption	`json:"boxes" validate:"required"`
 	Options []questionOption	`json:"options" validate:"required"`
-
+	Key 	json.RawMessage 	`json:"key,omitempty"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
@@ -91,11 +124,11 @@ type dragAndDropOptionsFormat struct{
 ///// 			CREATORS 			//////
 
 func (s *UserService) trueFalseCreator(ctx context.Context, quizId uuid.UUID, optionsJSON string, images map[string]io.Reader, questionId uuid.UUID) (string, error) {
-	return "{}", nil
+	return s.keyOnlyCreator(quizzes.TRUE_FALSE_TYPE, optionsJSON)
 }
 
 func (s *UserService) sliderCreator(ctx context.Context, quizId uuid.UUID, optionsJSON string, images map[string]io.Reader, questionId uuid.UUID) (string, error) {
-	return "{}", nil
+	return s.keyOnlyCreator(quizzes.SLIDER_TYPE, optionsJSON)
 }
 
 func (s *UserService) orderingCreator(ctx context.Context, quizId uuid.UUID, optionsJSON string, images map[string]io.Reader, questionId uuid.UUID) (string, error) {
@@ -112,8 +145,16 @@ func (s *UserService) orderingCreator(ctx context.Context, quizId uuid.UUID, opt
 	var output orderingOptionsFormat
 	output.SortingType = input.SortingType
 
+	if err := s.validateOptionsStrategicTypes(ctx, input.Options); err != nil{
+		return "", err
+	}
 	output.Options = make([]questionOption, 0, len(input.Options))
 	output.Options = s.readOptions(ctx, input.Options, output.Options, images, quizId,questionId)
+	key, err := s.readAnswerKey(quizzes.ORDERING_TYPE, output, input.AnswerKey)
+	if err != nil{
+		return "", err
+	}
+	output.Key = key
 
 	jsonBytes, err := json.Marshal(output)
 	if err != nil{
@@ -132,6 +173,11 @@ func (s *UserService) openCreator(ctx context.Context, quizId uuid.UUID, options
 
 	var output openOptionsFormat
 	output.NumAnswers = input.NumAnswers
+	key, err := s.readAnswerKey(quizzes.OPEN_TYPE, output, input.AnswerKey)
+	if err != nil{
+		return "", err
+	}
+	output.Key = key
 
 	jsonBytes, err := json.Marshal(output)
 	if err != nil{
@@ -154,8 +200,16 @@ func (s *UserService) multipleChCreator(ctx context.Context, quizId uuid.UUID, o
 		output.MultipleAnswer = *input.MultipleAnswer
 	}
 
+	if err := s.validateOptionsStrategicTypes(ctx, input.Options); err != nil{
+		return "", err
+	}
 	output.Options = make([]questionOption, 0, len(input.Options))
 	output.Options = s.readOptions(ctx, input.Options, output.Options, images, quizId, questionId)
+	key, err := s.readAnswerKey(quizzes.MULTIPLE_CH_TYPE, output, input.AnswerKey)
+	if err != nil{
+		return "", err
+	}
+	output.Key = key
 
 	jsonBytes, err := json.Marshal(output)
 	if err != nil{
@@ -175,13 +229,21 @@ func (s *UserService) matchingCreator(ctx context.Context, quizId uuid.UUID, opt
 		return "", quizzes.ErrInvalidQuestionOptions
 	}
 
+	if err := s.validateOptionsStrategicTypes(ctx, input.Options1, input.Options2); err != nil{
+		return "", err
+	}
 	output := matchingOptionsFormat{}
 
 	output.Options1 = make([]questionOption, 0, numberOptions)
-	output.Options1 = make([]questionOption, 0,  numberOptions)
+	output.Options2 = make([]questionOption, 0,  numberOptions)
 	output.Options1 = s.readOptions(ctx, input.Options1, output.Options1, images, quizId, questionId)
-	output.Options2 = s.readOptions(ctx, input.Options2, output.Options1, images, quizId, questionId)
-	
+	output.Options2 = s.readOptions(ctx, input.Options2, output.Options2, images, quizId, questionId)
+	key, err := s.readAnswerKey(quizzes.MATCHING_TYPE, output, input.AnswerKey)
+	if err != nil{
+		return "", err
+	}
+	output.Key = key
+
 	jsonBytes, err := json.Marshal(output)
 	if err != nil{
 		return "", quizzes.ErrCreatingQuestion
@@ -196,12 +258,20 @@ func (s *UserService) dragAndDropCreator(ctx context.Context, quizId uuid.UUID,
 		return "", quizzes.ErrInvalidQuestionOptions
 	}
 
+	if err := s.validateOptionsStrategicTypes(ctx, input.Boxes, input.Options); err != nil{
+		return "", err
+	}
 	output := dragAndDropOptionsFormat{}
 
 	output.Boxes = make([]questionOption,  0, len(input.Boxes))
 	output.Options = make([]questionOption, 0, len(input.Options))
 	output.Boxes = s.readOptions(ctx, input.Boxes, output.Boxes, images, quizId, questionId)
 	output.Options = s.readOptions(ctx, input.Options, output.Options, images, quizId, questionId)
+	key, err := s.readAnswerKey(quizzes.DRAG_AND_DROP_TYPE, output, input.AnswerKey)
+	if err != nil{
+		return "", err
+	}
+	output.Key = key
 
 	jsonBytes, err := json.Marshal(output)
 	if err != nil{
@@ -294,6 +364,8 @@ func (s *UserService) readOptions(ctx context.Context, inputOptions []inputOptio
 			OptId: ind,
 		}
 		option.Text = inputOption.Text
+		option.StrategicAnswerId = inputOption.StrategicAnswerId
+		option.StrategicWeight = inputOption.StrategicWeight
 
 		if inputOption.ImageName != ""{
 			waitGroup.Add(1)
@@ -322,6 +394,72 @@ func (s *UserService) readOptions(ctx context.Context, inputOptions []inputOptio
 }
 
 
+// the strategic types of the options must be active entries of the catalog
+func (s *UserService) validateOptionsStrategicTypes(ctx context.Context, optionsLists ...[]inputOption) error{
+	checked := map[uuid.UUID]bool{}
+	for _, options := range optionsLists{
+		for _, option := range options{
+			if option.StrategicWeight != nil && (*option.StrategicWeight <= 0 || *option.StrategicWeight > 1){
+				return quizzes.ErrInvalidQuestionOptions
+			}
+			if option.StrategicAnswerId == nil || checked[*option.StrategicAnswerId]{
+				continue
+			}
+			st, err := s.repo.GetStrategicTypeAnswerById(ctx, *option.StrategicAnswerId)
+			if err != nil{
+				return quizzes.ErrCreatingQuestion
+			}else if st == nil{
+				return quizzes.ErrStrategicTypeNotFound
+			}else if !st.Active{
+				return quizzes.ErrStrategicTypeRetired
+			}
+			checked[st.Id] = true
+		}
+	}
+	return nil
+}
+
+
+// the key is checked as an own answer to the question, so it has the same format as the answers,
+// the ids of the options are their positions in the input
+func (s *UserService) readAnswerKey(questionType string, options any, answerKey json.RawMessage) (json.RawMessage, error){
+	if isEmptyJson(answerKey){
+		return nil, nil
+	}
+	optionsJson, err := json.Marshal(options)
+	if err != nil{
+		return nil, quizzes.ErrCreatingQuestion
+	}
+	question := &quizzes.QuestionPlainModel{QuestionType: questionType, OptionsJson: string(optionsJson)}
+	answerJson, err := s.answerValidators[questionType](question, quizzes.AnswerRequest{OwnAnswer: answerKey})
+	if err != nil{
+		return nil, quizzes.ErrInvalidAnswerKey
+	}
+	return getOwnAnswer(answerJson)
+}
+
+
+// creator of the types whose options are only the answer key
+func (s *UserService) keyOnlyCreator(questionType string, optionsJSON string) (string, error){
+	var input keyInput
+	if err := json.Unmarshal([]byte(optionsJSON), &input); err != nil{
+		return "", quizzes.ErrInvalidQuestionOptions
+	}
+	var output keyOptionsFormat
+	key, err := s.readAnswerKey(questionType, output, input.AnswerKey)
+	if err != nil{
+		return "", err
+	}
+	output.Key = key
+
+	jsonBytes, err := json.Marshal(output)
+	if err != nil{
+		return "", quizzes.ErrCreatingQuestion
+	}
+	return string(jsonBytes), nil
+}
+
+
 func (s *UserService) deleteOptionsImages(ctx context.Context, options []questionOption) error{
 	for _, opt := range options{
 		if opt.ImageId != nil{
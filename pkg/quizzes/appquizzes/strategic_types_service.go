@@ -0,0 +1,192 @@
+package appquizzes
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
+	"github.com/google/uuid"
+)
+
+func (s *AdminServiceImpl) GetStrategicTypes(ctx context.Context, active *bool) ([]quizzes.StrategicTypeModel, error){
+	types, err := s.quizzesRepo.GetStrategicTypeAnswers(ctx, quizzes.StrategicTypeFilter{Active: active})
+	if err != nil{
+		return nil, quizzes.ErrRetrievingStrategicTypes
+	}
+	translations, err := s.quizzesRepo.GetStrategicTranslations(ctx, quizzes.StrategicTranslationFilter{})
+	if err != nil{
+		return nil, quizzes.ErrRetrievingStrategicTypes
+	}
+	translationsByType := map[uuid.UUID][]quizzes.StrategicTranslationModel{}
+	for _, translation := range translations{
+		translationsByType[translation.StrategicAnswerId] = append(translationsByType[translation.StrategicAnswerId], translation)
+	}
+	models := make([]quizzes.StrategicTypeModel, len(types))
+	for i, st := range types{
+		models[i] = quizzes.StrategicTypeModel{
+			Id: st.Id,
+			Name: st.Name,
+			Description: st.Description,
+			Active: st.Active,
+			Translations: translationsByType[st.Id],
+		}
+		if models[i].Translations == nil{
+			models[i].Translations = []quizzes.StrategicTranslationModel{}
+		}
+	}
+	return models, nil
+}
+
+
+func (s *AdminServiceImpl) CreateStrategicType(ctx context.Context, request quizzes.StrategicTypeRequest) (*uuid.UUID, error){
+	if request.Name == nil || request.Description == nil || strings.TrimSpace(*request.Name) == "" || *request.Description == ""{
+		return nil, quizzes.ErrMissingStrategicAttributes
+	}
+	if err := s.validateTranslations(request.Translations); err != nil{
+		return nil, err
+	}
+	st, err := s.quizzesRepo.GetStrategicTypeAnswerByName(ctx, strings.TrimSpace(*request.Name))
+	if err != nil{
+		return nil, quizzes.ErrCreatingStrategicType
+	}else if st != nil{
+		return nil, quizzes.ErrStrategicTypeAlreadyExists
+	}
+
+	model := quizzes.StrategicAnswerModel{
+		Id: uuid.New(),
+		Name: strings.TrimSpace(*request.Name),
+		Description: *request.Description,
+		Active: true,
+	}
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		if num, err := s.quizzesRepo.CreateStrategicTypeAnswer(ctx, &model); err != nil || num == 0{
+			return quizzes.ErrCreatingStrategicType
+		}
+		if err := s.saveTranslations(ctx, model.Id, request.Translations); err != nil{
+			return quizzes.ErrCreatingStrategicType
+		}
+		return nil
+	})
+	if err != nil{
+		return nil, err
+	}
+	return &model.Id, nil
+}
+
+
+func (s *AdminServiceImpl) UpdateStrategicType(ctx context.Context, id uuid.UUID, request quizzes.StrategicTypeRequest) error{
+	st, err := s.quizzesRepo.GetStrategicTypeAnswerById(ctx, id)
+	if err != nil{
+		return quizzes.ErrUpdatingStrategicType
+	}else if st == nil{
+		return quizzes.ErrStrategicTypeNotFound
+	}
+	if err := s.validateTranslations(request.Translations); err != nil{
+		return err
+	}
+	if request.Name != nil && strings.TrimSpace(*request.Name) != ""{
+		name := strings.TrimSpace(*request.Name)
+		existing, err := s.quizzesRepo.GetStrategicTypeAnswerByName(ctx, name)
+		if err != nil{
+			return quizzes.ErrUpdatingStrategicType
+		}else if existing != nil && existing.Id != id{
+			return quizzes.ErrStrategicTypeAlreadyExists
+		}
+		st.Name = name
+	}
+	if request.Description != nil && *request.Description != ""{
+		st.Description = *request.Description
+	}
+	if request.Active != nil{
+		st.Active = *request.Active
+	}
+	return s.transactions.Do(ctx, func(ctx context.Context) error {
+		if num, err := s.quizzesRepo.UpdateStrategicTypeAnswer(ctx, st); err != nil || num == 0{
+			return quizzes.ErrUpdatingStrategicType
+		}
+		if err := s.saveTranslations(ctx, id, request.Translations); err != nil{
+			return quizzes.ErrUpdatingStrategicType
+		}
+		return nil
+	})
+}
+
+
+// the questions and profiles of the source type move to the target type and the source is deleted,
+// used to clean the duplicates that were created along with the questions
+func (s *AdminServiceImpl) MergeStrategicTypes(ctx context.Context, sourceId uuid.UUID, targetId uuid.UUID) error{
+	if sourceId == targetId{
+		return quizzes.ErrMergingStrategicTypeIntoItself
+	}
+	source, err := s.quizzesRepo.GetStrategicTypeAnswerById(ctx, sourceId)
+	if err != nil{
+		return quizzes.ErrMergingStrategicTypes
+	}else if source == nil{
+		return quizzes.ErrStrategicTypeNotFound
+	}
+	target, err := s.quizzesRepo.GetStrategicTypeAnswerById(ctx, targetId)
+	if err != nil{
+		return quizzes.ErrMergingStrategicTypes
+	}else if target == nil{
+		return quizzes.ErrStrategicTypeNotFound
+	}else if !target.Active{
+		return quizzes.ErrStrategicTypeRetired
+	}
+
+	return s.transactions.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.quizzesRepo.MoveStrategicQuestions(ctx, sourceId, targetId); err != nil{
+			return quizzes.ErrMergingStrategicTypes
+		}
+		if _, err := s.quizzesRepo.MergeStrategicProfiles(ctx, sourceId, targetId, time.Now()); err != nil{
+			return quizzes.ErrMergingStrategicTypes
+		}
+		if num, err := s.quizzesRepo.DeleteStrategicTypeAnswer(ctx, sourceId); err != nil || num == 0{
+			return quizzes.ErrMergingStrategicTypes
+		}
+		return nil
+	})
+}
+
+
+// retired types keep their questions and profiles, but they can't be assigned to questions anymore
+func (s *AdminServiceImpl) RetireStrategicType(ctx context.Context, id uuid.UUID) error{
+	st, err := s.quizzesRepo.GetStrategicTypeAnswerById(ctx, id)
+	if err != nil{
+		return quizzes.ErrUpdatingStrategicType
+	}else if st == nil{
+		return quizzes.ErrStrategicTypeNotFound
+	}else if !st.Active{
+		return nil
+	}
+	st.Active = false
+	if num, err := s.quizzesRepo.UpdateStrategicTypeAnswer(ctx, st); err != nil || num == 0{
+		return quizzes.ErrUpdatingStrategicType
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+func (s *AdminServiceImpl) validateTranslations(translations []quizzes.StrategicTranslationModel) error{
+	for _, translation := range translations{
+		if translation.LanguageCode == "" || translation.Name == "" || translation.Description == ""{
+			return quizzes.ErrMissingStrategicAttributes
+		}
+		if err := s.loacalizationService.ValidateLanguage(translation.LanguageCode); err != nil{
+			return quizzes.ErrInvalidLanguage
+		}
+	}
+	return nil
+}
+
+func (s *AdminServiceImpl) saveTranslations(ctx context.Context, strategicAnswerId uuid.UUID, translations []quizzes.StrategicTranslationModel) error{
+	for _, translation := range translations{
+		translation.StrategicAnswerId = strategicAnswerId
+		if num, err := s.quizzesRepo.SaveStrategicTranslation(ctx, &translation); err != nil || num == 0{
+			return quizzes.ErrUpdatingStrategicType
+		}
+	}
+	return nil
+}
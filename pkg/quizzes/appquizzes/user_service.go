@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/diegobermudez03/couples-backend/pkg/events"
 	"github.com/diegobermudez03/couples-backend/pkg/files"
 	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
 	"github.com/diegobermudez03/couples-backend/pkg/localization"
@@ -18,35 +19,55 @@ import (
 
 type QuestionOptionsCreator func(ctx context.Context, quizId uuid.UUID, inputOptions string, images map[string]io.Reader, questionId uuid.UUID) (string, error)
 type QuestionDeletor func(ctx context.Context, question *quizzes.QuestionPlainModel) error
+type QuestionAnswerValidator func(question *quizzes.QuestionPlainModel, answer quizzes.AnswerRequest) (string, error)
+type QuestionAnswerComparator func(question *quizzes.QuestionPlainModel, ownAnswer, partnerAnswer string) (match bool, similarity float64, err error)
+type QuestionAnswerScorer func(question *quizzes.QuestionPlainModel, answer, key json.RawMessage) (score float64, err error)
+type QuestionStrategicCrediter func(question *quizzes.QuestionPlainModel, answer json.RawMessage) (credits map[uuid.UUID]float64, err error)
 
 type UserService struct{
 	transactions 	infraestructure.Transaction
 	fileService		files.Service
 	userService 	users.UsersService
+	pointsService 	users.PointsService
 	loacalizationService localization.LocalizationService
+	publisher 		events.Publisher
 	repo 			quizzes.QuizzesRepository
 	creators 		map[string]QuestionOptionsCreator
 	deletors 		map[string]QuestionDeletor
+	answerValidators map[string]QuestionAnswerValidator
+	comparators 	map[string]QuestionAnswerComparator
+	scorers 		map[string]QuestionAnswerScorer
+	crediters 		map[string]QuestionStrategicCrediter
 	jsonValidator 	*validator.Validate
 	maxFetchLimit	int
+	invitationLife 	int64
+	strategicCatalogOnly bool
 }
 
 func NewUserService(
 	transactions infraestructure.Transaction,
 	fileService	files.Service, 
 	userService users.UsersService,
+	pointsService users.PointsService,
 	loacalizationService localization.LocalizationService, 
+	publisher events.Publisher,
 	repo quizzes.QuizzesRepository,
 	maxFetchLimit int,
+	invitationLife int64,
+	strategicCatalogOnly bool,
 	) quizzes.UserService{
 	service := &UserService{
 		transactions: transactions,
 		fileService: fileService,
 		userService :userService,
+		pointsService: pointsService,
 		loacalizationService: loacalizationService,
+		publisher: publisher,
 		repo: repo,
 		jsonValidator: validator.New(),
 		maxFetchLimit:maxFetchLimit,
+		invitationLife: invitationLife,
+		strategicCatalogOnly: strategicCatalogOnly,
 	}
 	service.creators = map[string]QuestionOptionsCreator{
 		quizzes.TRUE_FALSE_TYPE : service.trueFalseCreator,
@@ -66,6 +87,42 @@ func NewUserService(
 		quizzes.MATCHING_TYPE : service.deleteMatching,
 		quizzes.DRAG_AND_DROP_TYPE : service.deleteDragAndDrop,
 	}
+	service.answerValidators = map[string]QuestionAnswerValidator{
+		quizzes.TRUE_FALSE_TYPE : service.trueFalseValidator,
+		quizzes.SLIDER_TYPE : service.sliderValidator,
+		quizzes.ORDERING_TYPE : service.orderingValidator,
+		quizzes.OPEN_TYPE : service.openValidator,
+		quizzes.MULTIPLE_CH_TYPE : service.multipleChValidator,
+		quizzes.MATCHING_TYPE : service.matchingValidator,
+		quizzes.DRAG_AND_DROP_TYPE : service.dragAndDropValidator,
+	}
+	service.comparators = map[string]QuestionAnswerComparator{
+		quizzes.TRUE_FALSE_TYPE : service.trueFalseComparator,
+		quizzes.SLIDER_TYPE : service.sliderComparator,
+		quizzes.ORDERING_TYPE : service.orderingComparator,
+		quizzes.OPEN_TYPE : service.openComparator,
+		quizzes.MULTIPLE_CH_TYPE : service.multipleChComparator,
+		quizzes.MATCHING_TYPE : service.matchingComparator,
+		quizzes.DRAG_AND_DROP_TYPE : service.dragAndDropComparator,
+	}
+	service.scorers = map[string]QuestionAnswerScorer{
+		quizzes.TRUE_FALSE_TYPE : service.trueFalseScorer,
+		quizzes.SLIDER_TYPE : service.sliderScorer,
+		quizzes.ORDERING_TYPE : service.orderingScorer,
+		quizzes.OPEN_TYPE : service.openScorer,
+		quizzes.MULTIPLE_CH_TYPE : service.multipleChScorer,
+		quizzes.MATCHING_TYPE : service.matchingScorer,
+		quizzes.DRAG_AND_DROP_TYPE : service.dragAndDropScorer,
+	}
+	service.crediters = map[string]QuestionStrategicCrediter{
+		quizzes.TRUE_FALSE_TYPE : service.trueFalseCrediter,
+		quizzes.SLIDER_TYPE : service.sliderCrediter,
+		quizzes.ORDERING_TYPE : service.orderingCrediter,
+		quizzes.OPEN_TYPE : service.openCrediter,
+		quizzes.MULTIPLE_CH_TYPE : service.multipleChCrediter,
+		quizzes.MATCHING_TYPE : service.matchingCrediter,
+		quizzes.DRAG_AND_DROP_TYPE : service.dragAndDropCrediter,
+	}
 	return service
 }
 
@@ -187,6 +244,12 @@ func (s *UserService) CreateQuestion(ctx context.Context, quizId uuid.UUID, para
 	if !ok{
 		return nil, quizzes.ErrInvalidQuestionType
 	}
+	if err := validatePlaceholders(parameters.Question); err != nil{
+		return nil, err
+	}
+	if err := validateInputPlaceholders(parameters.OptionsJson); err != nil{
+		return nil, err
+	}
 	questionId := uuid.New()
 	inputOptionsJson, err := json.Marshal(parameters.OptionsJson)
 	if err != nil{
@@ -208,21 +271,12 @@ func (s *UserService) CreateQuestion(ctx context.Context, quizId uuid.UUID, para
 	}
 
 	//create strategic question if needed
-	if parameters.StrategicAnswerId != nil{
-		st, err := s.repo.GetStrategicTypeAnswerById(ctx, *parameters.StrategicAnswerId)
-		if st != nil && err == nil{
-			questionModel.StrategicAnswerId = &st.Id
-		}
-	}else if parameters.StrategicName != nil && parameters.StrategicDescription != nil{
-		stId := uuid.New()
-		stModel := quizzes.StrategicAnswerModel{
-			Id: stId,
-			Name: *parameters.StrategicName,
-			Description: *parameters.StrategicDescription,
-		}
-		if num, err := s.repo.CreateStrategicTypeAnswer(ctx, &stModel); err == nil && num != 0{
-			questionModel.StrategicAnswerId = &stId
+	if parameters.StrategicAnswerId != nil || parameters.StrategicName != nil{
+		stId, err := s.getStrategicType(ctx, parameters.StrategicAnswerId, parameters.StrategicName, parameters.StrategicDescription)
+		if err != nil{
+			return nil, err
 		}
+		questionModel.StrategicAnswerId = stId
 	}
 	//calculate ordering 
 	maxOrder, err := s.repo.GetMaxOrderQuestionFromQuiz(ctx, quizId)
@@ -318,24 +372,20 @@ func (s *UserService) UpdateQuestion(ctx context.Context, questionId uuid.UUID,
 		return quizzes.ErrQuestionNotFound
 	}
 	if parameters.Question != nil{
+		if err := validatePlaceholders(*parameters.Question); err != nil{
+			return err
+		}
 		question.Question = *parameters.Question
 	}
-	if parameters.StrategicAnswerId != nil{
-		question.StrategicAnswerId = parameters.StrategicAnswerId
-	}else if parameters.StrategicName != nil{
-		var description string
-		if parameters.StrategicDescription != nil{
-			description = *parameters.StrategicDescription
-		}
-		strId := uuid.New()
-		strategicQuestion := quizzes.StrategicAnswerModel{
-			Id: strId,
-			Name: *parameters.StrategicName,
-			Description: description,
-		}
-		num, err := s.repo.CreateStrategicTypeAnswer(ctx, &strategicQuestion)
-		if num != 0  && err == nil{
-			question.StrategicAnswerId = &strId
+	if err := validateInputPlaceholders(parameters.OptionsJson); err != nil{
+		return err
+	}
+	if parameters.StrategicAnswerId != nil || parameters.StrategicName != nil{
+		stId, err := s.getStrategicType(ctx, parameters.StrategicAnswerId, parameters.StrategicName, parameters.StrategicDescription)
+		if err != nil{
+			return err
+		}else if stId != nil{
+			question.StrategicAnswerId = stId
 		}
 	}
 	if len(parameters.OptionsJson) != 0{
@@ -2,6 +2,7 @@ package quizzes
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 
 	"github.com/google/uuid"
@@ -12,9 +13,28 @@ type AdminService interface {
 	CreateQuizCategory(ctx context.Context, name, description string, image io.Reader) error
 	UpdateQuizCategory(ctx context.Context, id uuid.UUID, name, description string, image io.Reader) error
 	DeleteQuizCategory(ctx context.Context, id uuid.UUID) error
+
+	GetStrategicTypes(ctx context.Context, active *bool) ([]StrategicTypeModel, error)
+	CreateStrategicType(ctx context.Context, request StrategicTypeRequest) (*uuid.UUID, error)
+	UpdateStrategicType(ctx context.Context, id uuid.UUID, request StrategicTypeRequest) error
+	MergeStrategicTypes(ctx context.Context, sourceId uuid.UUID, targetId uuid.UUID) error
+	RetireStrategicType(ctx context.Context, id uuid.UUID) error
+}
+
+// options logic of every question type, shared with the domains that also have questions
+type QuestionOptionsService interface{
+	CreateQuestionOptions(ctx context.Context, questionType string, parentId uuid.UUID, questionId uuid.UUID, optionsJson map[string]any, images map[string]io.Reader) (string, error)
+	DeleteQuestionOptions(ctx context.Context, questionType string, optionsJson string) error
+	ValidateAnswer(questionType string, optionsJson string, answer AnswerRequest) (string, error)
+	HideAnswerKey(optionsJson string) (string, error)
+	RenderQuestions(ctx context.Context, userId uuid.UUID, questions []QuestionModel) error
+	ScoreAnswer(questionType string, optionsJson string, answerJson string) (*float64, error)
+	ValidatePlaceholders(text string) error
 }
 
 type UserService interface{
+	QuestionOptionsService
+
 	GetQuizesHomePage(ctx context.Context, userId uuid.UUID)(*QuizPage, error)
 	
 	AuthorizeQuizCreator(ctx context.Context, quizId *uuid.UUID, questionId *uuid.UUID, userId uuid.UUID) error
@@ -30,6 +50,29 @@ type UserService interface{
 	CreateQuestion(ctx context.Context, quizId uuid.UUID, parameters CreateQuestionRequest, images map[string]io.Reader)(*uuid.UUID, error)
 	UpdateQuestion(ctx context.Context, questionId uuid.UUID, parameters UpdateQuestionRequest, images map[string]io.Reader) error
 	DeleteQuestion(ctx context.Context, questionId uuid.UUID) error
+
+	StartQuiz(ctx context.Context, quizId uuid.UUID, userId uuid.UUID, mode string) (*uuid.UUID, error)
+	GetPlayQuestions(ctx context.Context, playId uuid.UUID, userId uuid.UUID) ([]QuestionModel, error)
+	AnswerQuestion(ctx context.Context, playId uuid.UUID, questionId uuid.UUID, userId uuid.UUID, answer AnswerRequest) error
+	CompleteQuiz(ctx context.Context, playId uuid.UUID, userId uuid.UUID) (*QuizPlayedModel, error)
+	GetQuizComparison(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*QuizComparisonModel, error)
+	GetQuizKnowledge(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*KnowledgeModel, error)
+	GetCoupleKnowledge(ctx context.Context, userId uuid.UUID) (*KnowledgeModel, error)
+	GetStrategicProfile(ctx context.Context, userId uuid.UUID) (*StrategicProfileModel, error)
+	GetCoupleCompatibility(ctx context.Context, userId uuid.UUID) (*CompatibilityModel, error)
+
+	InvitePartnerToQuiz(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*QuizInvitationModel, error)
+	GetQuizInvitations(ctx context.Context, userId uuid.UUID, status *string) ([]QuizInvitationModel, error)
+	AcceptQuizInvitation(ctx context.Context, invitationId uuid.UUID, userId uuid.UUID) (*uuid.UUID, error)
+}
+
+// sessions where both partners play the same quiz at the same time, the server moves to the
+// next question when both answered or when the time of the question runs out
+type LiveService interface{
+	JoinLiveSession(ctx context.Context, quizId uuid.UUID, userId uuid.UUID) (*LiveConnectionModel, error)
+	SetAnswering(connection *LiveConnectionModel, questionId uuid.UUID)
+	AnswerLiveQuestion(ctx context.Context, connection *LiveConnectionModel, questionId uuid.UUID, answer AnswerRequest) error
+	LeaveLiveSession(connection *LiveConnectionModel)
 }
 
 const OrderByDate = "date"
@@ -58,6 +101,20 @@ type UpdateQuestionRequest struct{
 	StrategicDescription *string 
 }
 
+// nil fields are kept when updating, the translations replace the ones of the same language
+type StrategicTypeRequest struct{
+	Name 			*string
+	Description 	*string
+	Active 			*bool
+	Translations 	[]StrategicTranslationModel
+}
+
+// the raw answers are validated against the question type, guessedPartner is optional
+type AnswerRequest struct{
+	OwnAnswer 		json.RawMessage
+	GuessedPartner 	json.RawMessage
+}
+
 
 const DOMAIN_NAME = "quizzes"
 const CATEGORIES = "categories"
@@ -82,6 +139,53 @@ const YOU_PLACEHOLDER = "%y%"
 const PARTNER_PLACEHOLDER = "%r%"
 
 
+//SLIDER RANGE
+const SLIDER_MIN_VALUE = 0
+const SLIDER_MAX_VALUE = 100
+// max distance between two slider answers to be considered a match
+const SLIDER_MATCH_TOLERANCE = 10
+
+
+//PLAY MODES
+// in the guess mode every answer must include the guess of the partner answer
+const (
+	PLAY_MODE_NORMAL = "NORMAL"
+	PLAY_MODE_GUESS = "GUESS"
+)
+
+
+//INVITATIONS STATUS
+const (
+	INVITATION_PENDING = "PENDING"
+	INVITATION_ACCEPTED = "ACCEPTED"
+	INVITATION_EXPIRED = "EXPIRED"
+)
+
+
+//LIVE MESSAGES
+const (
+	LIVE_WAITING_PARTNER = "WAITING_PARTNER"
+	LIVE_PARTNER_JOINED = "PARTNER_JOINED"
+	LIVE_PARTNER_LEFT = "PARTNER_LEFT"
+	LIVE_QUESTION = "QUESTION"
+	LIVE_PARTNER_ANSWERING = "PARTNER_ANSWERING"
+	LIVE_PARTNER_ANSWERED = "PARTNER_ANSWERED"
+	LIVE_FINISHED = "FINISHED"
+	LIVE_ERROR = "ERROR"
+)
+
+// a connection that falls this behind is dropped, the client reconnects to the session
+const LIVE_MESSAGES_BUFFER_SIZE = 16
+
+
+//POINTS
+const POINTS_PER_ANSWERED_QUESTION = 10
+// the questions with an answer key give up to these points more, depending on how right the answer is
+const POINTS_PER_RIGHT_ANSWER = 10
+// given to the guesser for every right guess once both partners completed the quiz
+const POINTS_PER_RIGHT_GUESS = 10
+
+
 //sorting types
 const LEAST_TO_MOST = "L-M"
 const MOST_TO_LEAST = "M-L"
\ No newline at end of file
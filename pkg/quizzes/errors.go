@@ -29,4 +29,48 @@ var (
 	ErrRetrievingQuizzes = errors.New("UNABLE_TO_RETRIEVE_QUIZES")
 	ErrUnableToPublish = errors.New("UNABLE_TO_PUBLISH_QUIZ")
 	ErrQuizAlreadyPublished = errors.New("QUIZ_ALREADY_PUBLISHED")
+	ErrStartingQuiz = errors.New("UNABLE_TO_START_QUIZ")
+	ErrQuizNotPublished = errors.New("QUIZ_NOT_PUBLISHED")
+	ErrQuizAlreadyPlayed = errors.New("QUIZ_ALREADY_PLAYED")
+	ErrQuizPlayNotFound = errors.New("QUIZ_PLAY_NOT_FOUND")
+	ErrQuizPlayAlreadyCompleted = errors.New("QUIZ_PLAY_ALREADY_COMPLETED")
+	ErrRetrievingQuestions = errors.New("UNABLE_TO_RETRIEVE_QUESTIONS")
+	ErrQuestionNotInQuiz = errors.New("QUESTION_NOT_IN_QUIZ")
+	ErrInvalidAnswer = errors.New("INVALID_ANSWER")
+	ErrAnsweringQuestion = errors.New("UNABLE_TO_ANSWER_QUESTION")
+	ErrMissingAnswers = errors.New("QUIZ_HAS_UNANSWERED_QUESTIONS")
+	ErrCompletingQuiz = errors.New("UNABLE_TO_COMPLETE_QUIZ")
+	ErrQuizNotCompleted = errors.New("QUIZ_NOT_COMPLETED")
+	ErrPartnerHasntCompleted = errors.New("PARTNER_HASNT_COMPLETED_QUIZ")
+	ErrComparingAnswers = errors.New("UNABLE_TO_COMPARE_ANSWERS")
+	ErrUserWithoutCouple = errors.New("USER_WITHOUT_COUPLE")
+	ErrInvalidPlaceholder = errors.New("INVALID_PLACEHOLDER")
+	ErrJoiningLiveSession = errors.New("UNABLE_TO_JOIN_LIVE_SESSION")
+	ErrLiveSessionNotStarted = errors.New("LIVE_SESSION_NOT_STARTED")
+	ErrQuestionNotCurrent = errors.New("QUESTION_NOT_CURRENT")
+	ErrInvalidLiveMessage = errors.New("INVALID_LIVE_MESSAGE")
+	ErrInvitingPartner = errors.New("UNABLE_TO_INVITE_PARTNER")
+	ErrPartnerAlreadyPlayed = errors.New("PARTNER_ALREADY_PLAYED_QUIZ")
+	ErrQuizInvitationAlreadyExists = errors.New("QUIZ_INVITATION_ALREADY_EXISTS")
+	ErrQuizInvitationNotFound = errors.New("QUIZ_INVITATION_NOT_FOUND")
+	ErrQuizInvitationExpired = errors.New("QUIZ_INVITATION_EXPIRED")
+	ErrQuizInvitationAlreadyAccepted = errors.New("QUIZ_INVITATION_ALREADY_ACCEPTED")
+	ErrRetrievingInvitations = errors.New("UNABLE_TO_RETRIEVE_INVITATIONS")
+	ErrInvalidInvitationStatus = errors.New("INVALID_INVITATION_STATUS")
+	ErrInvalidAnswerKey = errors.New("INVALID_ANSWER_KEY")
+	ErrInvalidPlayMode = errors.New("INVALID_PLAY_MODE")
+	ErrMissingGuess = errors.New("GUESS_OF_PARTNER_REQUIRED")
+	ErrScoringGuesses = errors.New("UNABLE_TO_SCORE_GUESSES")
+	ErrRetrievingKnowledge = errors.New("UNABLE_TO_RETRIEVE_KNOWLEDGE")
+	ErrRetrievingProfile = errors.New("UNABLE_TO_RETRIEVE_STRATEGIC_PROFILE")
+	ErrMissingStrategicAttributes = errors.New("MISSING_STRATEGIC_TYPE_ATTRIBUTES")
+	ErrStrategicTypeAlreadyExists = errors.New("STRATEGIC_TYPE_ALREADY_EXISTS")
+	ErrStrategicTypeNotFound = errors.New("STRATEGIC_TYPE_NOT_FOUND")
+	ErrStrategicTypeRetired = errors.New("STRATEGIC_TYPE_RETIRED")
+	ErrStrategicTypeNotInCatalog = errors.New("STRATEGIC_TYPE_NOT_IN_CATALOG")
+	ErrMergingStrategicTypeIntoItself = errors.New("CANT_MERGE_STRATEGIC_TYPE_INTO_ITSELF")
+	ErrCreatingStrategicType = errors.New("UNABLE_TO_CREATE_STRATEGIC_TYPE")
+	ErrUpdatingStrategicType = errors.New("UNABLE_TO_UPDATE_STRATEGIC_TYPE")
+	ErrMergingStrategicTypes = errors.New("UNABLE_TO_MERGE_STRATEGIC_TYPES")
+	ErrRetrievingStrategicTypes = errors.New("UNABLE_TO_RETRIEVE_STRATEGIC_TYPES")
 )
\ No newline at end of file
@@ -10,11 +10,38 @@ type QuestionFilter struct {
 type QuizPlayedFilter struct {
 	Id     *uuid.UUID
 	QuizId *uuid.UUID
+	UserId *uuid.UUID
+}
+
+type QuizGuessesFilter struct {
+	QuizId   *uuid.UUID
+	CoupleId *uuid.UUID
+	UserId   *uuid.UUID
+}
+
+type StrategicTypeFilter struct {
+	Id     *uuid.UUID
+	Active *bool
+}
+
+type StrategicTranslationFilter struct {
+	StrategicAnswerId *uuid.UUID
+	LanguageCode      *string
 }
 
 type UserAnswerFilter struct {
 	Id         *uuid.UUID
 	QuestionId *uuid.UUID
+	UserId     *uuid.UUID
+	PlayId     *uuid.UUID
+}
+
+type QuizInvitationFilter struct {
+	Id        *uuid.UUID
+	QuizId    *uuid.UUID
+	CoupleId  *uuid.UUID
+	InviteeId *uuid.UUID
+	Status    *string
 }
 
 type QuizFilter struct{
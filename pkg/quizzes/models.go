@@ -1,6 +1,7 @@
 package quizzes
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -69,22 +70,176 @@ type StrategicAnswerModel struct{
 	Id 				uuid.UUID 
 	Name 			string 
 	Description 	string
+	Active 			bool
+}
+
+type StrategicTranslationModel struct{
+	StrategicAnswerId 	uuid.UUID 	`json:"-"`
+	LanguageCode 		string 		`json:"languageCode"`
+	Name 				string 		`json:"name"`
+	Description 		string 		`json:"description"`
+}
+
+// entry of the strategic types catalog, with the name and description in every language it has
+type StrategicTypeModel struct{
+	Id 				uuid.UUID 					`json:"id"`
+	Name 			string 						`json:"name"`
+	Description 	string 						`json:"description"`
+	Active 			bool 						`json:"active"`
+	Translations 	[]StrategicTranslationModel `json:"translations"`
+}
+
+// accumulated weight of the answers of the user that map to the strategic type
+type StrategicScorePlainModel struct{
+	UserId 				uuid.UUID
+	StrategicAnswerId 	uuid.UUID
+	Score 				float64
+	Answers 			int
+	UpdatedAt 			time.Time
+}
+
+type StrategicScoreModel struct{
+	Id 				uuid.UUID 	`json:"id"`
+	Name 			string 		`json:"name"`
+	Description 	string 		`json:"description"`
+	Score 			float64 	`json:"score"`
+	Answers 		int 		`json:"answers"`
+	Share 			float64 	`json:"share"`
+}
+
+// the types are sorted from the biggest share of the profile, the shares are percentages
+type StrategicProfileModel struct{
+	UserId 		uuid.UUID 				`json:"userId"`
+	Types 		[]StrategicScoreModel 	`json:"types"`
+}
+
+// compatibility is the percentage of the profiles that overlaps, nil while a profile is empty
+type CompatibilityModel struct{
+	You 			StrategicProfileModel 	`json:"you"`
+	Partner 		StrategicProfileModel 	`json:"partner"`
+	Compatibility 	*float64 				`json:"compatibility"`
 }
 
 type UserAnswerPlainModel struct{
 	Id 			uuid.UUID
 	UserId 		uuid.UUID
 	QuestionId 	uuid.UUID
+	PlayId 		*uuid.UUID
 	Answers 	string 
 	AnsweredAt 	time.Time
 }
 
+// the couple is the one the user had when starting the play, nil when the user had none
 type QuizPlayedPlainModel struct{
 	Id 		uuid.UUID
 	QuizId 	uuid.UUID
 	UserId 	uuid.UUID
+	CoupleId 	*uuid.UUID
 	Shared 	bool 
+	Mode 	string
 	Score 	*int
 	StartedAt 	time.Time
 	CompletedAt *time.Time 
+}
+
+type QuestionModel struct{
+	Id 				uuid.UUID		`json:"id"`
+	Ordering 		int 			`json:"ordering"`
+	Question 		string 			`json:"question"`
+	QuestionType 	string 			`json:"questionType"`
+	Options 		json.RawMessage	`json:"options"`
+	Answered 		bool 			`json:"answered"`
+}
+
+type QuizPlayedModel struct{
+	Id 			uuid.UUID	`json:"id"`
+	QuizId 		uuid.UUID	`json:"quizId"`
+	Mode 		string 		`json:"mode"`
+	Score 		*int 		`json:"score"`
+	StartedAt 	time.Time	`json:"startedAt"`
+	CompletedAt *time.Time	`json:"completedAt"`
+}
+
+type QuestionComparisonModel struct{
+	Question 		QuestionModel 	`json:"question"`
+	YourAnswer 		json.RawMessage	`json:"yourAnswer"`
+	PartnerAnswer 	json.RawMessage	`json:"partnerAnswer"`
+	Match 			bool 			`json:"match"`
+	Similarity 		float64 		`json:"similarity"`
+}
+
+type QuizComparisonModel struct{
+	QuizId 		uuid.UUID					`json:"quizId"`
+	Matches 	int 						`json:"matches"`
+	Questions 	[]QuestionComparisonModel	`json:"questions"`
+}
+
+// guessedRight is the sum of how right every guess was, each one from 0 to 1
+type QuizGuessesPlainModel struct{
+	Id 				uuid.UUID
+	PlayId 			uuid.UUID
+	QuizId 			uuid.UUID
+	CoupleId 		uuid.UUID
+	UserId 			uuid.UUID
+	GuessedRight 	float64
+	Guesses 		int
+	ScoredAt 		time.Time
+}
+
+// percentages from 0 to 100 of how right the guesses about the partner were, nil while there are no guesses
+type KnowledgeModel struct{
+	QuizId 			*uuid.UUID 	`json:"quizId,omitempty"`
+	YouKnowPartner 	*float64 	`json:"youKnowPartner"`
+	PartnerKnowsYou *float64 	`json:"partnerKnowsYou"`
+	Couple 			*float64 	`json:"couple"`
+	Guesses 		int 		`json:"guesses"`
+}
+
+// the play is the one of the inviter, the invitee gets its own play when accepting
+type QuizInvitationPlainModel struct{
+	Id 			uuid.UUID
+	QuizId 		uuid.UUID
+	CoupleId 	uuid.UUID
+	InviterId 	uuid.UUID
+	InviteeId 	uuid.UUID
+	PlayId 		uuid.UUID
+	Status 		string
+	CreatedAt 	time.Time
+	ExpiresAt 	time.Time
+	RemindedAt 	*time.Time
+	AcceptedAt 	*time.Time
+}
+
+// the results are unlocked once both partners completed the quiz
+type QuizInvitationModel struct{
+	Id 				uuid.UUID 	`json:"id"`
+	QuizId 			uuid.UUID 	`json:"quizId"`
+	InviterId 		uuid.UUID 	`json:"inviterId"`
+	InviteeId 		uuid.UUID 	`json:"inviteeId"`
+	PlayId 			*uuid.UUID 	`json:"playId,omitempty"`
+	Status 			string 		`json:"status"`
+	CreatedAt 		time.Time 	`json:"createdAt"`
+	ExpiresAt 		time.Time 	`json:"expiresAt"`
+	AcceptedAt 		*time.Time 	`json:"acceptedAt"`
+	ResultsUnlocked bool 		`json:"resultsUnlocked"`
+}
+
+// the messages channel is closed when the connection leaves the session or is replaced by a new one
+type LiveConnectionModel struct{
+	QuizId 		uuid.UUID
+	CoupleId 	uuid.UUID
+	UserId 		uuid.UUID
+	PlayId 		uuid.UUID
+	Messages 	chan LiveMessageModel
+}
+
+type LiveMessageModel struct{
+	Type 			string 				`json:"type"`
+	Question 		*QuestionModel 		`json:"question,omitempty"`
+	QuestionId 		*uuid.UUID 			`json:"questionId,omitempty"`
+	Number 			int 				`json:"number,omitempty"`
+	Total 			int 				`json:"total,omitempty"`
+	Deadline 		*time.Time 			`json:"deadline,omitempty"`
+	Play 			*QuizPlayedModel 	`json:"play,omitempty"`
+	Error 			string 				`json:"error,omitempty"`
 }
\ No newline at end of file
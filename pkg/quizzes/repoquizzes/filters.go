@@ -13,6 +13,29 @@ func quizzesPlayedFilter(filter *quizzes.QuizPlayedFilter) map[string]any{
 	return map[string]any{
 		"id" : filter.Id,
 		"quiz_id" : filter.QuizId,
+		"user_id" : filter.UserId,
+	}
+}
+
+func quizGuessesFilter(filter *quizzes.QuizGuessesFilter) map[string]any{
+	return map[string]any{
+		"quiz_id" : filter.QuizId,
+		"couple_id" : filter.CoupleId,
+		"user_id" : filter.UserId,
+	}
+}
+
+func strategicTypeFilter(filter *quizzes.StrategicTypeFilter) map[string]any{
+	return map[string]any{
+		"id" : filter.Id,
+		"active" : filter.Active,
+	}
+}
+
+func strategicTranslationFilter(filter *quizzes.StrategicTranslationFilter) map[string]any{
+	return map[string]any{
+		"strategic_answer_id" : filter.StrategicAnswerId,
+		"language_code" : filter.LanguageCode,
 	}
 }
 
@@ -20,10 +43,21 @@ func userAnswerFilter(filter *quizzes.UserAnswerFilter) map[string]any{
 	return map[string]any{
 		"id" : filter.Id,
 		"question_id" : filter.QuestionId,
+		"user_id" : filter.UserId,
+		"play_id" : filter.PlayId,
 	}
 }
 
 
+func quizInvitationFilter(filter *quizzes.QuizInvitationFilter) map[string]any{
+	return map[string]any{
+		"id" : filter.Id,
+		"quiz_id" : filter.QuizId,
+		"couple_id" : filter.CoupleId,
+		"invitee_id" : filter.InviteeId,
+		"status" : filter.Status,
+	}
+}
 
 func quizFilter(filter *quizzes.QuizFilter) map[string]any{
 	return map[string]any{
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
 	"github.com/diegobermudez03/couples-backend/pkg/quizzes"
@@ -123,9 +124,76 @@ func (r *QuizzesPostgresRepo) CreateStrategicTypeAnswer(ctx context.Context, mod
 	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
 		return ex.ExecContext(
 			ctx, 
-			`INSERT INTO strategic_type_answers(id, name, description)
-			VALUES($1, $2, $3)`,
-			model.Id, model.Name, model.Description,
+			`INSERT INTO strategic_type_answers(id, name, description, active)
+			VALUES($1, $2, $3, $4)`,
+			model.Id, model.Name, model.Description, model.Active,
+		)
+	})
+}
+
+func (r *QuizzesPostgresRepo) UpdateStrategicTypeAnswer(ctx context.Context, model *quizzes.StrategicAnswerModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`UPDATE strategic_type_answers SET name = $1, description = $2, active = $3
+			WHERE id = $4`,
+			model.Name, model.Description, model.Active, model.Id,
+		)
+	})
+}
+
+// the profiles and translations of the type are deleted with it, its questions must be moved before
+func (r *QuizzesPostgresRepo) DeleteStrategicTypeAnswer(ctx context.Context, id uuid.UUID) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`DELETE FROM strategic_type_answers WHERE id = $1`,
+			id,
+		)
+	})
+}
+
+// includes the inactive questions, so none of them keeps pointing to the source, the options
+// that carry the source type are moved too
+func (r *QuizzesPostgresRepo) MoveStrategicQuestions(ctx context.Context, sourceId uuid.UUID, targetId uuid.UUID) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`UPDATE quiz_questions SET 
+			strategic_answer_id = CASE WHEN strategic_answer_id = $2 THEN $1 ELSE strategic_answer_id END,
+			options_json = REPLACE(options_json::text, $4, $3)::jsonb
+			WHERE strategic_answer_id = $2 OR options_json::text LIKE '%' || $4 || '%'`,
+			targetId, sourceId, targetId.String(), sourceId.String(),
+		)
+	})
+}
+
+// adds the scores of the source type to the ones of the target type of every user
+func (r *QuizzesPostgresRepo) MergeStrategicProfiles(ctx context.Context, sourceId uuid.UUID, targetId uuid.UUID, updatedAt time.Time) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`INSERT INTO strategic_profiles(user_id, strategic_answer_id, score, answers, updated_at)
+			SELECT user_id, $1, score, answers, $2 FROM strategic_profiles WHERE strategic_answer_id = $3
+			ON CONFLICT (user_id, strategic_answer_id) DO UPDATE SET 
+			score = strategic_profiles.score + EXCLUDED.score, 
+			answers = strategic_profiles.answers + EXCLUDED.answers,
+			updated_at = EXCLUDED.updated_at`,
+			targetId, updatedAt, sourceId,
+		)
+	})
+}
+
+// adds the translation or replaces the one of the same language
+func (r *QuizzesPostgresRepo) SaveStrategicTranslation(ctx context.Context, model *quizzes.StrategicTranslationModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx, 
+			`INSERT INTO strategic_type_translations(strategic_answer_id, language_code, name, description)
+			VALUES($1, $2, $3, $4)
+			ON CONFLICT (strategic_answer_id, language_code) DO UPDATE SET 
+			name = EXCLUDED.name, description = EXCLUDED.description`,
+			model.StrategicAnswerId, model.LanguageCode, model.Name, model.Description,
 		)
 	})
 }
@@ -145,18 +213,114 @@ func (r *QuizzesPostgresRepo) GetMaxOrderQuestionFromQuiz(ctx context.Context, q
 }
 
 
+func (r *QuizzesPostgresRepo) GetStrategicTypeAnswers(ctx context.Context, filter quizzes.StrategicTypeFilter) ([]quizzes.StrategicAnswerModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, name, description, active
+		FROM strategic_type_answers WHERE 1=1 `,
+		strategicTypeFilter(&filter),
+	)
+	query = query + " ORDER BY name"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	types := []quizzes.StrategicAnswerModel{}
+	for rows.Next(){
+		model, err := r.rowToStrategicAnswer(rows)
+		if err != nil{
+			return nil, err 
+		}
+		types = append(types, *model)
+	}
+	return types, rows.Err()
+}
+
 func (r *QuizzesPostgresRepo) GetStrategicTypeAnswerById(ctx context.Context, id uuid.UUID) (*quizzes.StrategicAnswerModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, name, description
+		`SELECT id, name, description, active
 		FROM strategic_type_answers WHERE id = $1`,
 		id,
 	)
-	model := new(quizzes.StrategicAnswerModel)
-	if err := row.Scan(&model.Id, &model.Name, &model.Description); err != nil{
+	return r.rowToStrategicAnswer(row)
+}
+
+// the names are compared ignoring the case, so the catalog doesn't get the same type twice
+func (r *QuizzesPostgresRepo) GetStrategicTypeAnswerByName(ctx context.Context, name string) (*quizzes.StrategicAnswerModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, name, description, active
+		FROM strategic_type_answers WHERE LOWER(name) = LOWER($1)
+		ORDER BY active DESC LIMIT 1`,
+		name,
+	)
+	return r.rowToStrategicAnswer(row)
+}
+
+func (r *QuizzesPostgresRepo) GetStrategicTranslations(ctx context.Context, filter quizzes.StrategicTranslationFilter) ([]quizzes.StrategicTranslationModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT strategic_answer_id, language_code, name, description
+		FROM strategic_type_translations WHERE 1=1 `,
+		strategicTranslationFilter(&filter),
+	)
+	query = query + " ORDER BY language_code"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
 		return nil, err 
 	}
-	return model, nil
+	defer rows.Close()
+	translations := []quizzes.StrategicTranslationModel{}
+	for rows.Next(){
+		model := quizzes.StrategicTranslationModel{}
+		if err := rows.Scan(&model.StrategicAnswerId, &model.LanguageCode, &model.Name, &model.Description); err != nil{
+			return nil, err 
+		}
+		translations = append(translations, model)
+	}
+	return translations, rows.Err()
+}
+
+// the scores of the user with the name and description of their types, translated to the language
+// when the type has it
+func (r *QuizzesPostgresRepo) GetStrategicProfile(ctx context.Context, userId uuid.UUID, languageCode string) ([]quizzes.StrategicScoreModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT s.id, COALESCE(t.name, s.name), COALESCE(t.description, s.description), p.score, p.answers
+		FROM strategic_profiles p JOIN strategic_type_answers s ON s.id = p.strategic_answer_id
+		LEFT JOIN strategic_type_translations t ON t.strategic_answer_id = s.id AND t.language_code = $2
+		WHERE p.user_id = $1 ORDER BY p.score DESC`,
+		userId, languageCode,
+	)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	scores := []quizzes.StrategicScoreModel{}
+	for rows.Next(){
+		model := quizzes.StrategicScoreModel{}
+		if err := rows.Scan(&model.Id, &model.Name, &model.Description, &model.Score, &model.Answers); err != nil{
+			return nil, err 
+		}
+		scores = append(scores, model)
+	}
+	return scores, rows.Err()
+}
+
+// adds the score and answers of the model to the ones of the user, negative values take them back
+func (r *QuizzesPostgresRepo) AddStrategicScore(ctx context.Context, model *quizzes.StrategicScorePlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO strategic_profiles(user_id, strategic_answer_id, score, answers, updated_at)
+			VALUES($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, strategic_answer_id) DO UPDATE SET 
+			score = strategic_profiles.score + EXCLUDED.score, 
+			answers = strategic_profiles.answers + EXCLUDED.answers,
+			updated_at = EXCLUDED.updated_at`,
+			model.UserId, model.StrategicAnswerId, model.Score, model.Answers, model.UpdatedAt,
+		)
+	})
 }
 
 func (r *QuizzesPostgresRepo) DeleteCategoryById(ctx context.Context, id uuid.UUID) (int, error){
@@ -248,6 +412,7 @@ func (r *QuizzesPostgresRepo) GetQuestions(ctx context.Context, filter quizzes.Q
 		FROM quiz_questions WHERE active=TRUE `,
 		questionFilter(&filter),
 	)
+	query = query + " ORDER BY ordering"
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil{
 		return nil, err 
@@ -410,6 +575,261 @@ func (r *QuizzesPostgresRepo) GetBatchCategories(ctx context.Context, ids []uuid
 	return categories, nil
 }
 
+func (r *QuizzesPostgresRepo) GetQuizzesPlayed(ctx context.Context, filter quizzes.QuizPlayedFilter) ([]quizzes.QuizPlayedPlainModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, quiz_id, user_id, couple_id, shared, mode, score, started_at, completed_at
+		FROM quizzes_played WHERE 1=1 `,
+		quizzesPlayedFilter(&filter),
+	)
+	query = query + " ORDER BY started_at DESC"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	plays := []quizzes.QuizPlayedPlainModel{}
+	for rows.Next(){
+		model, err := r.rowToQuizPlayed(rows)
+		if err != nil{
+			return nil, err 
+		}
+		plays = append(plays, *model)
+	}
+	return plays, nil
+}
+
+func (r *QuizzesPostgresRepo) GetQuizPlayedById(ctx context.Context, id uuid.UUID) (*quizzes.QuizPlayedPlainModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, quiz_id, user_id, couple_id, shared, mode, score, started_at, completed_at
+		FROM quizzes_played WHERE id = $1`,
+		id,
+	)
+	return r.rowToQuizPlayed(row)
+}
+
+// the last play of the user in the quiz while in the couple, or without couple when it's nil
+func (r *QuizzesPostgresRepo) GetCoupleQuizPlayed(ctx context.Context, quizId uuid.UUID, userId uuid.UUID, coupleId *uuid.UUID) (*quizzes.QuizPlayedPlainModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, quiz_id, user_id, couple_id, shared, mode, score, started_at, completed_at
+		FROM quizzes_played WHERE quiz_id = $1 AND user_id = $2 AND couple_id IS NOT DISTINCT FROM $3
+		ORDER BY started_at DESC LIMIT 1`,
+		quizId, userId, coupleId,
+	)
+	return r.rowToQuizPlayed(row)
+}
+
+func (r *QuizzesPostgresRepo) CreateQuizPlayed(ctx context.Context, model *quizzes.QuizPlayedPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO quizzes_played(id, quiz_id, user_id, couple_id, shared, mode, score, started_at, completed_at)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			model.Id, model.QuizId, model.UserId, model.CoupleId, model.Shared, model.Mode, model.Score, model.StartedAt, model.CompletedAt,
+		)
+	})
+}
+
+func (r *QuizzesPostgresRepo) UpdateQuizPlayed(ctx context.Context, model *quizzes.QuizPlayedPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE quizzes_played SET shared = $1, mode = $2, score = $3, completed_at = $4
+			WHERE id = $5`,
+			model.Shared, model.Mode, model.Score, model.CompletedAt, model.Id,
+		)
+	})
+}
+
+// only completes the play if it wasn't completed yet, so concurrent completions can't both succeed
+func (r *QuizzesPostgresRepo) CompleteQuizPlayed(ctx context.Context, model *quizzes.QuizPlayedPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE quizzes_played SET score = $1, completed_at = $2
+			WHERE id = $3 AND completed_at IS NULL`,
+			model.Score, model.CompletedAt, model.Id,
+		)
+	})
+}
+
+func (r *QuizzesPostgresRepo) GetUsersAnswers(ctx context.Context, filter quizzes.UserAnswerFilter) ([]quizzes.UserAnswerPlainModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, user_id, question_id, play_id, answers, answered_at
+		FROM user_answers WHERE 1=1 `,
+		userAnswerFilter(&filter),
+	)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	return r.rowsToUserAnswers(rows)
+}
+
+func (r *QuizzesPostgresRepo) GetUserAnswersFromPlay(ctx context.Context, playId uuid.UUID) ([]quizzes.UserAnswerPlainModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT a.id, a.user_id, a.question_id, a.play_id, a.answers, a.answered_at
+		FROM user_answers a JOIN quiz_questions q ON q.id = a.question_id
+		WHERE a.play_id = $1 AND q.active = TRUE`,
+		playId,
+	)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	return r.rowsToUserAnswers(rows)
+}
+
+func (r *QuizzesPostgresRepo) CreateUserAnswer(ctx context.Context, model *quizzes.UserAnswerPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO user_answers(id, user_id, question_id, play_id, answers, answered_at)
+			VALUES($1, $2, $3, $4, $5, $6)`,
+			model.Id, model.UserId, model.QuestionId, model.PlayId, model.Answers, model.AnsweredAt,
+		)
+	})
+}
+
+func (r *QuizzesPostgresRepo) UpdateUserAnswer(ctx context.Context, model *quizzes.UserAnswerPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE user_answers SET answers = $1, answered_at = $2 WHERE id = $3`,
+			model.Answers, model.AnsweredAt, model.Id,
+		)
+	})
+}
+
+func (r *QuizzesPostgresRepo) GetQuizInvitations(ctx context.Context, filter quizzes.QuizInvitationFilter) ([]quizzes.QuizInvitationPlainModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, quiz_id, couple_id, inviter_id, invitee_id, play_id, status, created_at, expires_at, reminded_at, accepted_at
+		FROM quiz_invitations WHERE 1=1 `,
+		quizInvitationFilter(&filter),
+	)
+	query = query + " ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	return r.rowsToQuizInvitations(rows)
+}
+
+func (r *QuizzesPostgresRepo) GetQuizInvitationById(ctx context.Context, id uuid.UUID) (*quizzes.QuizInvitationPlainModel, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, quiz_id, couple_id, inviter_id, invitee_id, play_id, status, created_at, expires_at, reminded_at, accepted_at
+		FROM quiz_invitations WHERE id = $1`,
+		id,
+	)
+	return r.rowToQuizInvitation(row)
+}
+
+func (r *QuizzesPostgresRepo) CreateQuizInvitation(ctx context.Context, model *quizzes.QuizInvitationPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO quiz_invitations(id, quiz_id, couple_id, inviter_id, invitee_id, play_id, status, created_at, expires_at, reminded_at, accepted_at)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			model.Id, model.QuizId, model.CoupleId, model.InviterId, model.InviteeId, model.PlayId, model.Status, 
+			model.CreatedAt, model.ExpiresAt, model.RemindedAt, model.AcceptedAt,
+		)
+	})
+}
+
+func (r *QuizzesPostgresRepo) UpdateQuizInvitation(ctx context.Context, model *quizzes.QuizInvitationPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE quiz_invitations SET status = $1, reminded_at = $2, accepted_at = $3
+			WHERE id = $4`,
+			model.Status, model.RemindedAt, model.AcceptedAt, model.Id,
+		)
+	})
+}
+
+func (r *QuizzesPostgresRepo) GetQuizInvitationsToRemind(ctx context.Context, createdBefore time.Time, now time.Time) ([]quizzes.QuizInvitationPlainModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, quiz_id, couple_id, inviter_id, invitee_id, play_id, status, created_at, expires_at, reminded_at, accepted_at
+		FROM quiz_invitations 
+		WHERE status = $1 AND reminded_at IS NULL AND created_at <= $2 AND expires_at > $3`,
+		quizzes.INVITATION_PENDING, createdBefore, now,
+	)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	return r.rowsToQuizInvitations(rows)
+}
+
+func (r *QuizzesPostgresRepo) ExpireQuizInvitations(ctx context.Context, now time.Time) ([]quizzes.QuizInvitationPlainModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`UPDATE quiz_invitations SET status = $1 
+		WHERE status = $2 AND expires_at <= $3
+		RETURNING id, quiz_id, couple_id, inviter_id, invitee_id, play_id, status, created_at, expires_at, reminded_at, accepted_at`,
+		quizzes.INVITATION_EXPIRED, quizzes.INVITATION_PENDING, now,
+	)
+	if err != nil{
+		log.Print("error expiring quiz invitations: ", err.Error())
+		return nil, err 
+	}
+	defer rows.Close()
+	return r.rowsToQuizInvitations(rows)
+}
+
+func (r *QuizzesPostgresRepo) GetQuizGuesses(ctx context.Context, filter quizzes.QuizGuessesFilter) ([]quizzes.QuizGuessesPlainModel, error){
+	query, args := infraestructure.GetFilteredQuery(
+		`SELECT id, play_id, quiz_id, couple_id, user_id, guessed_right, guesses, scored_at
+		FROM quiz_guesses WHERE 1=1 `,
+		quizGuessesFilter(&filter),
+	)
+	query = query + " ORDER BY scored_at DESC"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return nil, err 
+	}
+	defer rows.Close()
+	guesses := []quizzes.QuizGuessesPlainModel{}
+	for rows.Next(){
+		model := quizzes.QuizGuessesPlainModel{}
+		err := rows.Scan(&model.Id, &model.PlayId, &model.QuizId, &model.CoupleId, &model.UserId, &model.GuessedRight, &model.Guesses, &model.ScoredAt)
+		if err != nil{
+			return nil, err 
+		}
+		guesses = append(guesses, model)
+	}
+	return guesses, rows.Err()
+}
+
+func (r *QuizzesPostgresRepo) CreateQuizGuesses(ctx context.Context, model *quizzes.QuizGuessesPlainModel) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`INSERT INTO quiz_guesses(id, play_id, quiz_id, couple_id, user_id, guessed_right, guesses, scored_at)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8)`,
+			model.Id, model.PlayId, model.QuizId, model.CoupleId, model.UserId, model.GuessedRight, model.Guesses, model.ScoredAt,
+		)
+	})
+}
+
+// the lock is held until the transaction ends, so the partners completing the same quiz are serialized
+func (r *QuizzesPostgresRepo) LockCoupleQuiz(ctx context.Context, coupleId uuid.UUID, quizId uuid.UUID) error{
+	_, err := infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`SELECT pg_advisory_xact_lock(hashtext($1::text || $2::text))`,
+			coupleId, quizId,
+		)
+	})
+	return err
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 ///////////////////////////////////////////////////////////////////////////////
 /////////////////////////////////////////////////////////////////////////////////
@@ -438,6 +858,18 @@ func (r *QuizzesPostgresRepo) rowToQuestion(row infraestructure.Scanable) (*quiz
 	return model, nil
 }
 
+func (r *QuizzesPostgresRepo) rowToStrategicAnswer(row infraestructure.Scanable) (*quizzes.StrategicAnswerModel, error){
+	model := new(quizzes.StrategicAnswerModel)
+	err := row.Scan(&model.Id, &model.Name, &model.Description, &model.Active)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, nil 
+		}
+		return nil, err 
+	}
+	return model, nil
+}
+
 func (r *QuizzesPostgresRepo) rowToQuiz(row infraestructure.Scanable) (*quizzes.QuizPlainModel, error){
 	model := new(quizzes.QuizPlainModel)
 	err := row.Scan(&model.Id, &model.Name, &model.Description, &model.LanguageCode, &model.ImageId, 
@@ -449,4 +881,53 @@ func (r *QuizzesPostgresRepo) rowToQuiz(row infraestructure.Scanable) (*quizzes.
 		return nil, err 
 	}
 	return model, nil
+}
+
+func (r *QuizzesPostgresRepo) rowToQuizPlayed(row infraestructure.Scanable) (*quizzes.QuizPlayedPlainModel, error){
+	model := new(quizzes.QuizPlayedPlainModel)
+	err := row.Scan(&model.Id, &model.QuizId, &model.UserId, &model.CoupleId, &model.Shared, &model.Mode, &model.Score, &model.StartedAt, &model.CompletedAt)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, nil 
+		}
+		return nil, err 
+	}
+	return model, nil
+}
+
+func (r *QuizzesPostgresRepo) rowsToUserAnswers(rows *sql.Rows) ([]quizzes.UserAnswerPlainModel, error){
+	answers := []quizzes.UserAnswerPlainModel{}
+	for rows.Next(){
+		model := quizzes.UserAnswerPlainModel{}
+		if err := rows.Scan(&model.Id, &model.UserId, &model.QuestionId, &model.PlayId, &model.Answers, &model.AnsweredAt); err != nil{
+			return nil, err 
+		}
+		answers = append(answers, model)
+	}
+	return answers, nil
+}
+
+func (r *QuizzesPostgresRepo) rowToQuizInvitation(row infraestructure.Scanable) (*quizzes.QuizInvitationPlainModel, error){
+	model := new(quizzes.QuizInvitationPlainModel)
+	err := row.Scan(&model.Id, &model.QuizId, &model.CoupleId, &model.InviterId, &model.InviteeId, &model.PlayId, 
+	&model.Status, &model.CreatedAt, &model.ExpiresAt, &model.RemindedAt, &model.AcceptedAt)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, nil 
+		}
+		return nil, err 
+	}
+	return model, nil
+}
+
+func (r *QuizzesPostgresRepo) rowsToQuizInvitations(rows *sql.Rows) ([]quizzes.QuizInvitationPlainModel, error){
+	invitations := []quizzes.QuizInvitationPlainModel{}
+	for rows.Next(){
+		model, err := r.rowToQuizInvitation(rows)
+		if err != nil{
+			return nil, err 
+		}
+		invitations = append(invitations, *model)
+	}
+	return invitations, rows.Err()
 }
\ No newline at end of file
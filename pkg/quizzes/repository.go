@@ -2,6 +2,7 @@ package quizzes
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -33,10 +34,41 @@ type QuizzesRepository interface {
 
 	GetQuizzesPlayedCount(ctx context.Context, filter QuizPlayedFilter) (int, error)
 	DeleteQuizzesPlayed(ctx context.Context, filter QuizPlayedFilter) (int, error)
+	GetQuizzesPlayed(ctx context.Context, filter QuizPlayedFilter) ([]QuizPlayedPlainModel, error)
+	GetQuizPlayedById(ctx context.Context, id uuid.UUID) (*QuizPlayedPlainModel, error)
+	GetCoupleQuizPlayed(ctx context.Context, quizId uuid.UUID, userId uuid.UUID, coupleId *uuid.UUID) (*QuizPlayedPlainModel, error)
+	CreateQuizPlayed(ctx context.Context, model *QuizPlayedPlainModel) (int, error)
+	UpdateQuizPlayed(ctx context.Context, model *QuizPlayedPlainModel) (int, error)
+	CompleteQuizPlayed(ctx context.Context, model *QuizPlayedPlainModel) (int, error)
 
+	GetStrategicTypeAnswers(ctx context.Context, filter StrategicTypeFilter) ([]StrategicAnswerModel, error)
 	GetStrategicTypeAnswerById(ctx context.Context, id uuid.UUID) (*StrategicAnswerModel, error)
+	GetStrategicTypeAnswerByName(ctx context.Context, name string) (*StrategicAnswerModel, error)
 	CreateStrategicTypeAnswer(ctx context.Context, model *StrategicAnswerModel) (int, error)
+	UpdateStrategicTypeAnswer(ctx context.Context, model *StrategicAnswerModel) (int, error)
+	DeleteStrategicTypeAnswer(ctx context.Context, id uuid.UUID) (int, error)
+	GetStrategicTranslations(ctx context.Context, filter StrategicTranslationFilter) ([]StrategicTranslationModel, error)
+	SaveStrategicTranslation(ctx context.Context, model *StrategicTranslationModel) (int, error)
+	MoveStrategicQuestions(ctx context.Context, sourceId uuid.UUID, targetId uuid.UUID) (int, error)
+	MergeStrategicProfiles(ctx context.Context, sourceId uuid.UUID, targetId uuid.UUID, updatedAt time.Time) (int, error)
+	GetStrategicProfile(ctx context.Context, userId uuid.UUID, languageCode string) ([]StrategicScoreModel, error)
+	AddStrategicScore(ctx context.Context, model *StrategicScorePlainModel) (int, error)
 
 	GetUsersAnswersCount(ctx context.Context, filter UserAnswerFilter) (int, error)
 	DeleteUsersAnswers(ctx context.Context, filter UserAnswerFilter)(int, error)
+	GetUsersAnswers(ctx context.Context, filter UserAnswerFilter) ([]UserAnswerPlainModel, error)
+	GetUserAnswersFromPlay(ctx context.Context, playId uuid.UUID) ([]UserAnswerPlainModel, error)
+	CreateUserAnswer(ctx context.Context, model *UserAnswerPlainModel) (int, error)
+	UpdateUserAnswer(ctx context.Context, model *UserAnswerPlainModel) (int, error)
+
+	GetQuizInvitations(ctx context.Context, filter QuizInvitationFilter) ([]QuizInvitationPlainModel, error)
+	GetQuizInvitationById(ctx context.Context, id uuid.UUID) (*QuizInvitationPlainModel, error)
+	CreateQuizInvitation(ctx context.Context, model *QuizInvitationPlainModel) (int, error)
+	UpdateQuizInvitation(ctx context.Context, model *QuizInvitationPlainModel) (int, error)
+	GetQuizInvitationsToRemind(ctx context.Context, createdBefore time.Time, now time.Time) ([]QuizInvitationPlainModel, error)
+	ExpireQuizInvitations(ctx context.Context, now time.Time) ([]QuizInvitationPlainModel, error)
+
+	GetQuizGuesses(ctx context.Context, filter QuizGuessesFilter) ([]QuizGuessesPlainModel, error)
+	CreateQuizGuesses(ctx context.Context, model *QuizGuessesPlainModel) (int, error)
+	LockCoupleQuiz(ctx context.Context, coupleId uuid.UUID, quizId uuid.UUID) error
 }
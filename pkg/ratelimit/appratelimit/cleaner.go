@@ -0,0 +1,59 @@
+package appratelimit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
+)
+
+// deletes periodically the keys that are no longer used until it's stopped
+type StaleKeysCleaner struct{
+	store 		ratelimit.Store
+	interval 	time.Duration
+	stop 		chan struct{}
+	done 		chan struct{}
+	stopOnce 	sync.Once
+}
+
+func NewStaleKeysCleaner(store ratelimit.Store, interval time.Duration) *StaleKeysCleaner{
+	return &StaleKeysCleaner{
+		store: store,
+		interval: interval,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+func (c *StaleKeysCleaner) Start(){
+	go func(){
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for{
+			select{
+			case <- c.stop:
+				return
+			case <- ticker.C:
+				c.clean()
+			}
+		}
+	}()
+}
+
+func (c *StaleKeysCleaner) Stop(){
+	c.stopOnce.Do(func(){
+		close(c.stop)
+		<- c.done
+	})
+}
+
+func (c *StaleKeysCleaner) clean(){
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := c.store.DeleteStale(ctx, time.Now().Add(-ratelimit.STALE_KEYS_TIME)); err != nil{
+		log.Print("error cleaning rate limit keys: ", err.Error())
+	}
+}
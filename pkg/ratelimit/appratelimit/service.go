@@ -0,0 +1,55 @@
+package appratelimit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
+)
+
+type ServiceImpl struct{
+	store 	ratelimit.Store
+}
+
+func NewServiceImpl(store ratelimit.Store) ratelimit.Service{
+	return &ServiceImpl{
+		store: store,
+	}
+}
+
+func (s *ServiceImpl) Allow(ctx context.Context, policy ratelimit.PolicyModel, key string) (time.Duration, error){
+	storeKey := getStoreKey(policy, key)
+	now := time.Now()
+
+	lockedUntil, err := s.store.GetLockedUntil(ctx, storeKey)
+	if err != nil{
+		return 0, ratelimit.ErrCheckingLimit
+	}
+	if lockedUntil != nil && lockedUntil.After(now){
+		return lockedUntil.Sub(now), ratelimit.ErrTooManyAttempts
+	}
+	hits, err := s.store.Hit(ctx, storeKey, now, policy.Window)
+	if err != nil{
+		return 0, ratelimit.ErrCheckingLimit
+	}
+	if hits <= policy.MaxAttempts{
+		return 0, nil
+	}
+	if err := s.store.Lock(ctx, storeKey, now.Add(policy.Lockout)); err != nil{
+		return 0, ratelimit.ErrCheckingLimit
+	}
+	log.Printf("Locked %s during %s", storeKey, policy.Lockout)
+	return policy.Lockout, ratelimit.ErrTooManyAttempts
+}
+
+func (s *ServiceImpl) Reset(ctx context.Context, policy ratelimit.PolicyModel, key string) error{
+	if err := s.store.Reset(ctx, getStoreKey(policy, key)); err != nil{
+		return ratelimit.ErrCheckingLimit
+	}
+	return nil
+}
+
+func getStoreKey(policy ratelimit.PolicyModel, key string) string{
+	return policy.Name + ":" + key
+}
@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+type Service interface{
+	// registers an attempt of the key for the policy, if the key is locked it returns the time until it can try again
+	Allow(ctx context.Context, policy PolicyModel, key string) (retryAfter time.Duration, err error)
+	Reset(ctx context.Context, policy PolicyModel, key string) error
+}
+
+// keeps the attempts of every key, the hits are counted in fixed windows
+type Store interface{
+	Hit(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	GetLockedUntil(ctx context.Context, key string) (*time.Time, error)
+	Reset(ctx context.Context, key string) error
+	DeleteStale(ctx context.Context, before time.Time) error
+}
+
+
+const MEMORY_STORE = "memory"
+const POSTGRES_STORE = "postgres"
+
+// the keys not used in this time are deleted, it must be longer than any window or lockout
+const STALE_KEYS_TIME = 24 * time.Hour
@@ -0,0 +1,8 @@
+package ratelimit
+
+import "errors"
+
+var (
+	ErrTooManyAttempts = errors.New("TOO_MANY_ATTEMPTS")
+	ErrCheckingLimit = errors.New("UNABLE_TO_CHECK_RATE_LIMIT")
+)
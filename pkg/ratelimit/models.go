@@ -0,0 +1,11 @@
+package ratelimit
+
+import "time"
+
+// after MaxAttempts inside the Window the key is locked during Lockout
+type PolicyModel struct{
+	Name 			string
+	MaxAttempts 	int
+	Window 			time.Duration
+	Lockout 		time.Duration
+}
@@ -0,0 +1,83 @@
+package reporatelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
+)
+
+type memoryEntry struct{
+	hits 			int
+	windowStart 	time.Time
+	lockedUntil 	*time.Time
+}
+
+// only valid for a single instance, the limits aren't shared
+type MemoryStore struct{
+	entries 	map[string]*memoryEntry
+	mutex 		sync.Mutex
+}
+
+func NewMemoryStore() ratelimit.Store{
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+func (s *MemoryStore) Hit(ctx context.Context, key string, now time.Time, window time.Duration) (int, error){
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, ok := s.entries[key]
+	if !ok{
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+	//a new window starts
+	if !entry.windowStart.After(now.Add(-window)){
+		entry.hits = 0
+		entry.windowStart = now
+	}
+	entry.hits++
+	return entry.hits, nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key string, until time.Time) error{
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries[key] = &memoryEntry{
+		hits: 0,
+		windowStart: time.Now(),
+		lockedUntil: &until,
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetLockedUntil(ctx context.Context, key string) (*time.Time, error){
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, ok := s.entries[key]
+	if !ok{
+		return nil, nil
+	}
+	return entry.lockedUntil, nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error{
+	s.mutex.Lock()
+	delete(s.entries, key)
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) DeleteStale(ctx context.Context, before time.Time) error{
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, entry := range s.entries{
+		if entry.windowStart.Before(before) && (entry.lockedUntil == nil || entry.lockedUntil.Before(before)){
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package reporatelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/ratelimit"
+)
+
+// shares the limits between every instance of the API
+type PostgresStore struct{
+	db 	*sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) ratelimit.Store{
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+// the hit is counted atomically, so concurrent attempts in different instances are all counted
+func (s *PostgresStore) Hit(ctx context.Context, key string, now time.Time, window time.Duration) (int, error){
+	row := s.db.QueryRowContext(
+		ctx,
+		`INSERT INTO rate_limits(key, hits, window_start) VALUES($1, 1, $2)
+		ON CONFLICT(key) DO UPDATE SET
+			hits = CASE WHEN rate_limits.window_start <= $3 THEN 1 ELSE rate_limits.hits + 1 END,
+			window_start = CASE WHEN rate_limits.window_start <= $3 THEN $2 ELSE rate_limits.window_start END
+		RETURNING hits`,
+		key, now, now.Add(-window),
+	)
+	var hits int
+	if err := row.Scan(&hits); err != nil{
+		log.Print("error registering rate limit hit: ", err.Error())
+		return 0, err
+	}
+	return hits, nil
+}
+
+func (s *PostgresStore) Lock(ctx context.Context, key string, until time.Time) error{
+	_, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO rate_limits(key, hits, window_start, locked_until) VALUES($1, 0, $2, $3)
+		ON CONFLICT(key) DO UPDATE SET hits = 0, window_start = $2, locked_until = $3`,
+		key, time.Now(), until,
+	)
+	if err != nil{
+		log.Print("error locking rate limit key: ", err.Error())
+	}
+	return err
+}
+
+func (s *PostgresStore) GetLockedUntil(ctx context.Context, key string) (*time.Time, error){
+	row := s.db.QueryRowContext(
+		ctx,
+		`SELECT locked_until FROM rate_limits WHERE key = $1`,
+		key,
+	)
+	var lockedUntil *time.Time
+	err := row.Scan(&lockedUntil)
+	if errors.Is(err, sql.ErrNoRows){
+		return nil, nil
+	}else if err != nil{
+		log.Print("error getting rate limit lock: ", err.Error())
+		return nil, err
+	}
+	return lockedUntil, nil
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) error{
+	_, err := s.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE key = $1`, key)
+	return err
+}
+
+func (s *PostgresStore) DeleteStale(ctx context.Context, before time.Time) error{
+	_, err := s.db.ExecContext(
+		ctx,
+		`DELETE FROM rate_limits WHERE window_start < $1 AND (locked_until IS NULL OR locked_until < $1)`,
+		before,
+	)
+	return err
+}
@@ -0,0 +1,167 @@
+package appusers
+
+import (
+	"context"
+	"time"
+
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
+	"github.com/diegobermudez03/couples-backend/pkg/users"
+	"github.com/google/uuid"
+)
+
+/////////  HELPERS
+var pointsReasons = map[string]bool{
+	users.POINTS_REASON_CONNECTED : true,
+	users.POINTS_REASON_QUIZ_COMPLETED : true,
+	users.POINTS_REASON_CHALLENGE_WON : true,
+	users.POINTS_REASON_STREAK : true,
+	users.POINTS_REASON_QUIZ_GUESSED : true,
+}
+
+// reasons that count as playing for the streak
+var streakReasons = map[string]bool{
+	users.POINTS_REASON_QUIZ_COMPLETED : true,
+	users.POINTS_REASON_CHALLENGE_WON : true,
+}
+
+const dayFormat = "2006-01-02"
+
+///////////////////////////////////////////
+
+type PointsServiceImpl struct {
+	usersRepo 	users.UsersRepo
+	publisher 	events.Publisher
+}
+
+func NewPointsServiceImpl(usersRepo users.UsersRepo, publisher events.Publisher) users.PointsService {
+	return &PointsServiceImpl{
+		usersRepo: usersRepo,
+		publisher: publisher,
+	}
+}
+
+// writes the points using the context, so if the caller is inside a transaction the points are part of it
+func (s *PointsServiceImpl) AwardPoints(ctx context.Context, userId uuid.UUID, points int, reason string, sourceId *uuid.UUID) error{
+	if !pointsReasons[reason]{
+		return users.ErrorInvalidPointsReason
+	}
+	couple, err := s.usersRepo.GetCoupleByUserId(ctx, userId)
+	if err != nil{
+		return users.ErrorCreatingPoints
+	}
+	model := users.PointsModel{
+		Id: uuid.New(),
+		Day: time.Now(),
+		Points: points,
+		UserId: &userId,
+		Reason: reason,
+		SourceId: sourceId,
+	}
+	if couple != nil{
+		model.CoupleId = &couple.Id
+	}
+	if num, err := s.usersRepo.CreateCouplePoints(ctx, &model); err != nil || num == 0{
+		return users.ErrorCreatingPoints
+	}
+	if couple == nil{
+		return nil
+	}
+	s.publishPoints(ctx, userId, couple.Id, points, reason)
+	if streakReasons[reason]{
+		return s.awardStreak(ctx, userId, couple.Id)
+	}
+	return nil
+}
+
+
+func (s *PointsServiceImpl) GetCouplePoints(ctx context.Context, coupleId uuid.UUID) (int, error){
+	if coupleId == uuid.Nil{
+		return 0, users.ErrorNoCoupleFound
+	}
+	total, err := s.usersRepo.GetCouplePointsTotal(ctx, coupleId)
+	if err != nil{
+		return 0, users.ErrorGettingPoints
+	}
+	return total, nil
+}
+
+
+func (s *PointsServiceImpl) GetCouplePointsHistory(ctx context.Context, coupleId uuid.UUID, days int) ([]users.DailyPointsModel, error){
+	if coupleId == uuid.Nil{
+		return nil, users.ErrorNoCoupleFound
+	}
+	if days <= 0 || days > users.MAX_POINTS_HISTORY_DAYS{
+		days = users.MAX_POINTS_HISTORY_DAYS
+	}
+	history, err := s.usersRepo.GetCoupleDailyPoints(ctx, coupleId, time.Now().AddDate(0, 0, -days))
+	if err != nil{
+		return nil, users.ErrorGettingPoints
+	}
+	return history, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+// awards the streak bonus once a day, if the couple played in each of the previous days of the streak.
+// The couple is locked first, so when two plays are completed at the same time the last one waits for
+// the first to commit and sees its bonus
+func (s *PointsServiceImpl) awardStreak(ctx context.Context, userId uuid.UUID, coupleId uuid.UUID) error{
+	if err := s.usersRepo.LockCouplePoints(ctx, coupleId); err != nil{
+		return users.ErrorCreatingPoints
+	}
+	now := time.Now()
+	awarded, err := s.usersRepo.CheckCouplePointsReasonOnDay(ctx, coupleId, users.POINTS_REASON_STREAK, now)
+	if err != nil{
+		return users.ErrorCreatingPoints
+	}else if awarded{
+		return nil
+	}
+	reasons := make([]string, 0, len(streakReasons))
+	for reason := range streakReasons{
+		reasons = append(reasons, reason)
+	}
+	days, err := s.usersRepo.GetCoupleActiveDays(ctx, coupleId, reasons, now.AddDate(0, 0, -(users.STREAK_DAYS - 1)))
+	if err != nil{
+		return users.ErrorCreatingPoints
+	}
+	activeDays := make(map[string]bool, len(days))
+	for _, day := range days{
+		activeDays[day.Format(dayFormat)] = true
+	}
+	//today is active since the points were just awarded
+	for i := 1; i < users.STREAK_DAYS; i++{
+		if !activeDays[now.AddDate(0, 0, -i).Format(dayFormat)]{
+			return nil
+		}
+	}
+	model := users.PointsModel{
+		Id: uuid.New(),
+		Day: now,
+		Points: users.COUPLE_POINTS_FOR_STREAK,
+		UserId: &userId,
+		CoupleId: &coupleId,
+		Reason: users.POINTS_REASON_STREAK,
+	}
+	if num, err := s.usersRepo.CreateCouplePoints(ctx, &model); err != nil || num == 0{
+		return users.ErrorCreatingPoints
+	}
+	s.publishPoints(ctx, userId, coupleId, users.COUPLE_POINTS_FOR_STREAK, users.POINTS_REASON_STREAK)
+	return nil
+}
+
+// the points are notified once they're committed, since they're usually awarded inside the transaction of the caller
+func (s *PointsServiceImpl) publishPoints(ctx context.Context, userId uuid.UUID, coupleId uuid.UUID, points int, reason string){
+	infraestructure.AfterCommit(ctx, func(){
+		s.publisher.Publish(context.Background(), events.EventModel{
+			Type: events.EVENT_POINTS_EARNED,
+			CoupleId: coupleId,
+			UserId: userId,
+			Data: map[string]any{
+				"points" : points,
+				"reason" : reason,
+			},
+		})
+	})
+}
@@ -2,10 +2,19 @@ package appusers
 
 import (
 	"context"
-	"math/rand"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"math/big"
+	"net/url"
 	"strings"
 	"time"
 
+	"github.com/diegobermudez03/couples-backend/pkg/events"
+	"github.com/diegobermudez03/couples-backend/pkg/files"
+	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
 	"github.com/diegobermudez03/couples-backend/pkg/localization"
 	"github.com/diegobermudez03/couples-backend/pkg/users"
 	"github.com/google/uuid"
@@ -14,26 +23,38 @@ import (
 const (
 	MALE_GENDER = "male"
 	FEMALE_GENDER = "female"
+	NON_BINARY_GENDER = "non-binary"
 )
 
 /////////  HELPERS
 var genders = map[string]bool{
 	MALE_GENDER : true,
 	FEMALE_GENDER : true,
+	NON_BINARY_GENDER : true,
 }
 
 
 ///////////////////////////////////////////
 
 type UsersServiceImpl struct {
+	transactions 		infraestructure.Transaction
 	localizationService localization.LocalizationService
+	filesService 		files.Service
+	publisher 			events.Publisher
 	usersRepo 			users.UsersRepo
+	tempCoupleLife 		int64
+	inviteLinksUrl 		string
 }
 
-func NewUsersServiceImpl(localizationService localization.LocalizationService, usersRepo users.UsersRepo) users.UsersService {
+func NewUsersServiceImpl(transactions infraestructure.Transaction, localizationService localization.LocalizationService, filesService files.Service, publisher events.Publisher, usersRepo users.UsersRepo, tempCoupleLife int64, inviteLinksUrl string) users.UsersService {
 	return &UsersServiceImpl{
+		transactions: transactions,
 		usersRepo: usersRepo,
 		localizationService: localizationService,
+		filesService: filesService,
+		publisher: publisher,
+		tempCoupleLife: tempCoupleLife,
+		inviteLinksUrl: inviteLinksUrl,
 	}
 }
 
@@ -99,41 +120,67 @@ func (s *UsersServiceImpl) DeleteUserById(ctx context.Context, userId uuid.UUID)
 	return nil
 }
 
-func (s *UsersServiceImpl) CreateTempCouple(ctx context.Context, userId uuid.UUID, startDate int) (int, error){
+// the code expires after the configured life and is deleted once used, the invite link
+// is an alternative to the code that the partner can open directly
+func (s *UsersServiceImpl) CreateTempCouple(ctx context.Context, userId uuid.UUID, startDate int) (*users.TempCoupleModel, string, error){
 	couple, _ := s.usersRepo.GetCoupleByUserId(ctx, userId)
 	if couple != nil{
-		return 0, users.ErrorUserHasActiveCouple
-	}
-	var code int 
-	//unique code creation
-	for{
-		code = rand.Intn(89999) + 10000
-		if couple,err := s.usersRepo.GetTempCoupleByCode(ctx, code); err == nil && couple == nil{
-			break
-		}
+		return nil, "", users.ErrorUserHasActiveCouple
 	}
-	exists, err := s.usersRepo.CheckTempCoupleById(ctx, userId)
+	previous, err := s.usersRepo.GetTempCoupleFromUser(ctx, userId)
 	if err != nil{
-		return 0, users.ErrorCreatingTempCouple 
+		return nil, "", users.ErrorCreatingTempCouple
 	}
+	//the expired codes are freed so they can be drawn again
+	s.deleteExpiredTempCouples(ctx)
 
+	inviteToken, err := generateInviteToken()
+	if err != nil{
+		return nil, "", users.ErrorCreatingTempCouple
+	}
+	inviteLink := s.getInviteLink(inviteToken)
+	qrImageId, qrUrl, err := s.filesService.UploadQrCode(ctx, inviteLink, files.QR_CODE_SIZE, true, users.DOMAIN_NAME, users.INVITES, userId.String(), uuid.NewString())
+	if err != nil{
+		return nil, "", users.ErrorCreatingTempCouple
+	}
+	tokenHash := hashInviteToken(inviteToken)
 	tempCouple := users.TempCoupleModel{
 		UserId: userId,
-		Code: code,
 		StartDate: time.Unix(int64(startDate), 0),
-
-	}
-	var num int
-	if exists{
-		num, err = s.usersRepo.UpdateTempCouple(ctx, &tempCouple)
-	}else{
-		num, err = s.usersRepo.CreateTempCouple(ctx, &tempCouple)
+		ExpiresAt: time.Now().Add(time.Duration(s.tempCoupleLife) * time.Second),
+		InviteToken: &tokenHash,
+		QrImageId: qrImageId,
+		QrUrl: qrUrl,
 	}
 
-	if err != nil || num == 0{
-		return 0,  users.ErrorCreatingTempCouple  
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.usersRepo.DeleteTempCoupleById(ctx, userId); err != nil{
+			return users.ErrorCreatingTempCouple
+		}
+		//a drawn code already in use isn't inserted, so another one is drawn
+		for attempt := 0; attempt < users.MAX_COUPLE_CODE_ATTEMPTS; attempt++{
+			code, err := generateCoupleCode()
+			if err != nil{
+				return users.ErrorCreatingTempCouple
+			}
+			tempCouple.Code = code
+			num, err := s.usersRepo.CreateTempCouple(ctx, &tempCouple)
+			if err != nil{
+				return users.ErrorCreatingTempCouple
+			}else if num == 1{
+				return nil
+			}
+		}
+		return users.ErrorCreatingTempCouple
+	})
+	if err != nil{
+		s.filesService.DeleteImage(ctx, *qrImageId)
+		return nil, "", err
+	}
+	if previous != nil && previous.QrImageId != nil{
+		s.filesService.DeleteImage(ctx, *previous.QrImageId)
 	}
-	return code, nil
+	return &tempCouple, inviteLink, nil
 }
 
 func (s *UsersServiceImpl) GetCoupleFromUser(ctx context.Context, userId uuid.UUID) (*users.CoupleModel, error){
@@ -149,16 +196,28 @@ func (s *UsersServiceImpl) GetCoupleFromUser(ctx context.Context, userId uuid.UU
 
 
 func (s *UsersServiceImpl) ConnectCouple(ctx context.Context, userId uuid.UUID, code int) (*uuid.UUID, *uuid.UUID, error){
+	tempCouple, _ := s.usersRepo.GetTempCoupleByCode(ctx, code)
+	return s.connectWithTempCouple(ctx, userId, tempCouple)
+}
+
+func (s *UsersServiceImpl) ConnectCoupleByInvite(ctx context.Context, userId uuid.UUID, inviteToken string) (*uuid.UUID, *uuid.UUID, error){
+	tempCouple, _ := s.usersRepo.GetTempCoupleByInviteToken(ctx, hashInviteToken(inviteToken))
+	return s.connectWithTempCouple(ctx, userId, tempCouple)
+}
+
+// the temp couples of both partners are deleted, so the code and the invite link can only be used once
+func (s *UsersServiceImpl) connectWithTempCouple(ctx context.Context, userId uuid.UUID, tempCouple *users.TempCoupleModel) (*uuid.UUID, *uuid.UUID, error){
 	// check that the user doesn't have a couple
 	coupleCheck, _ := s.usersRepo.GetCoupleByUserId(ctx, userId)
 	if coupleCheck != nil{
 		return nil, nil, users.ErrorUserHasActiveCouple
 	}
-	
-	tempCouple, _ := s.usersRepo.GetTempCoupleByCode(ctx, code)
 	if tempCouple == nil{
 		return nil, nil, users.ErrorInvalidCode
 	}
+	if tempCouple.IsExpired(){
+		return nil, nil, users.ErrorExpiredCode
+	}
 	//check that the user isn't connecting with himself
 	if userId == tempCouple.UserId{
 		return nil, nil, users.ErrorCantConnectWithYourself
@@ -172,53 +231,54 @@ func (s *UsersServiceImpl) ConnectCouple(ctx context.Context, userId uuid.UUID,
 	if err != nil {
 		return nil, nil, users.ErrorConnectingCouple 
 	}
-
-	var heId uuid.UUID
-	var sheId uuid.UUID
-	if user1.Gender == MALE_GENDER{
-		heId = user1.Id
-		sheId = user2.Id
-	}else{
-		sheId = user1.Id
-		heId = user2.Id
+	if user1 == nil || user2 == nil{
+		return nil, nil, users.ErrorConnectingCouple 
 	}
 
 	coupleId := uuid.New()
 	couple := &users.CoupleModel{
 		Id: coupleId,
 		RelationStart: tempCouple.StartDate,
-		HeId: heId,
-		SheId: sheId,
-	}
-	if num, err := s.usersRepo.CreateCouple(ctx, couple); err != nil || num == 0{
-		return nil, nil, users.ErrorConnectingCouple 
-	}
-
-	//delete temp couples
-	go func(){
-		s.usersRepo.DeleteTempCoupleById(context.Background(), heId)
-		s.usersRepo.DeleteTempCoupleById(context.Background(), sheId)
-
-		//create first points
-		s.usersRepo.CreateCouplePoints(
-			ctx,
-			&users.PointsModel{
-				Id: uuid.New(),
-				Day: time.Now(),
-				Points: users.COUPLE_POINTS_FOR_CONNECTING,
-				UserId: &heId,
-			},
-		)
-		s.usersRepo.CreateCouplePoints(
-			ctx,
-			&users.PointsModel{
+		Partner1Id: user1.Id,
+		Partner2Id: user2.Id,
+	}
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		//the temp couple of the creator is deleted first, so if someone else redeemed it at the same
+		//time only one of them deletes it and the other one fails
+		if num, err := s.usersRepo.DeleteTempCoupleById(ctx, tempCouple.UserId); err != nil{
+			return users.ErrorConnectingCouple
+		}else if num != 1{
+			return users.ErrorInvalidCode
+		}
+		if _, err := s.usersRepo.DeleteTempCoupleById(ctx, userId); err != nil{
+			return users.ErrorConnectingCouple
+		}
+		if num, err := s.usersRepo.CreateCouple(ctx, couple); err != nil || num == 0{
+			return users.ErrorConnectingCouple 
+		}
+		//create first points, the couple isn't committed yet so it's assigned directly
+		for _, partnerId := range []uuid.UUID{couple.Partner1Id, couple.Partner2Id}{
+			points := users.PointsModel{
 				Id: uuid.New(),
 				Day: time.Now(),
 				Points: users.COUPLE_POINTS_FOR_CONNECTING,
-				UserId: &sheId,
-			},
-		)
-	}()
+				UserId: &partnerId,
+				CoupleId: &coupleId,
+				Reason: users.POINTS_REASON_CONNECTED,
+				SourceId: &coupleId,
+			}
+			if num, err := s.usersRepo.CreateCouplePoints(ctx, &points); err != nil || num == 0{
+				return users.ErrorCreatingPoints
+			}
+		}
+		return nil
+	})
+	if err != nil{
+		return nil, nil, err
+	}
+	if tempCouple.QrImageId != nil{
+		s.filesService.DeleteImage(ctx, *tempCouple.QrImageId)
+	}
 	return &coupleId, &tempCouple.UserId, nil
 }
 
@@ -226,17 +286,22 @@ func (s *UsersServiceImpl) EditPartnersNickname(ctx context.Context, userId uuid
 	couple, err := s.usersRepo.GetCoupleById(ctx, coupleId)
 	if err != nil{
 		return users.ErrorUpdatingNickname
+	}else if couple == nil || !couple.HasMember(userId){
+		return users.ErrorNoCoupleFound
 	}
-	var partnerId uuid.UUID
-	if couple.HeId == userId{
-		partnerId = couple.SheId
-	}else{
-		partnerId = couple.HeId
-	}
+	partnerId := couple.GetPartnerId(userId)
 
 	if num, err := s.usersRepo.UpdateUserNicknameById(ctx, partnerId, nickname); err != nil || num == 0{
 		return users.ErrorUpdatingNickname 
 	}
+	infraestructure.AfterCommit(ctx, func(){
+		s.publisher.Publish(context.Background(), events.EventModel{
+			Type: events.EVENT_NICKNAME_CHANGED,
+			CoupleId: couple.Id,
+			UserId: userId,
+			Data: map[string]any{"nickname" : nickname},
+		})
+	})
 	return nil
 }
 
@@ -245,15 +310,11 @@ func (s *UsersServiceImpl)  CheckPartnerNickname(ctx context.Context, userId uui
 	couple, err := s.usersRepo.GetCoupleByUserId(ctx, userId)
 	if err != nil{
 		return false, users.ErrorUnableToCheckPartnerNickname
+	}else if couple == nil{
+		return false, users.ErrorNoCoupleFound
 	}
-	var partnerId uuid.UUID
-	if couple.HeId == userId{
-		partnerId = couple.SheId
-	}else{
-		partnerId = couple.HeId
-	}
-	partner, err := s.usersRepo.GetUserById(ctx, partnerId)
-	if err != nil{
+	partner, err := s.usersRepo.GetUserById(ctx, couple.GetPartnerId(userId))
+	if err != nil || partner == nil{
 		return false, users.ErrorUnableToCheckPartnerNickname
 	}
 	return partner.NickName != partner.FirstName, nil
@@ -276,4 +337,70 @@ func(s *UsersServiceImpl)  GetUserLanguage(ctx context.Context, userId uuid.UUID
 		return "", err 
 	}
 	return user.LanguageCode, nil
-}
\ No newline at end of file
+}
+
+func(s *UsersServiceImpl)  GetUserById(ctx context.Context, userId uuid.UUID) (*users.UserModel, error){
+	user, err := s.usersRepo.GetUserById(ctx, userId)
+	if err != nil{
+		return nil, users.ErrorUnableToGetUser
+	}else if user == nil{
+		return nil, users.ErrorUserNotFound
+	}
+	return user, nil
+}
+
+// ends the active couple of the user, the points stay linked to the ended couple 
+// and the plays stay with each user, so a new couple starts from zero
+func(s *UsersServiceImpl) DisconnectCouple(ctx context.Context, userId uuid.UUID) (*uuid.UUID, error){
+	couple, err := s.usersRepo.GetCoupleByUserId(ctx, userId)
+	if err != nil{
+		return nil, users.ErrorDisconnectingCouple
+	}else if couple == nil{
+		return nil, users.ErrorNoCoupleFound
+	}
+	if num, err := s.usersRepo.UpdateCoupleEndDate(ctx, couple.Id, time.Now()); err != nil || num == 0{
+		return nil, users.ErrorDisconnectingCouple
+	}
+	partnerId := couple.GetPartnerId(userId)
+	return &partnerId, nil
+}
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////
+///				PRIVATE METHODS				/////
+
+func (s *UsersServiceImpl) deleteExpiredTempCouples(ctx context.Context){
+	imagesIds, err := s.usersRepo.DeleteExpiredTempCouples(ctx, time.Now())
+	if err != nil{
+		return
+	}
+	for _, imageId := range imagesIds{
+		s.filesService.DeleteImage(ctx, imageId)
+	}
+}
+
+func (s *UsersServiceImpl) getInviteLink(inviteToken string) string{
+	return s.inviteLinksUrl + "?token=" + url.QueryEscape(inviteToken)
+}
+
+func generateCoupleCode() (int, error){
+	num, err := rand.Int(rand.Reader, big.NewInt(users.MAX_COUPLE_CODE - users.MIN_COUPLE_CODE + 1))
+	if err != nil{
+		log.Print("error generating couple code: ", err.Error())
+		return 0, err
+	}
+	return int(num.Int64()) + users.MIN_COUPLE_CODE, nil
+}
+
+func generateInviteToken() (string, error){
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil{
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func hashInviteToken(token string) string{
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,24 +13,34 @@ type UsersService interface {
 		firstName, lastName, gender, countryCode, languageCode string,
 		birthDate int,
 	) (*uuid.UUID, error) 
-	CreateTempCouple(ctx context.Context, userId uuid.UUID, startDate int) (int, error)
+	CreateTempCouple(ctx context.Context, userId uuid.UUID, startDate int) (tempCouple *TempCoupleModel, inviteLink string, err error)
 	DeleteUserById(ctx context.Context, userId uuid.UUID) error
 	GetCoupleFromUser(ctx context.Context, userId uuid.UUID) (*CoupleModel, error)
 	GetTempCoupleFromUser(ctx context.Context, userId uuid.UUID)(*TempCoupleModel, error)
 	ConnectCouple(ctx context.Context, userId uuid.UUID, code int)(coupleId *uuid.UUID, partnerId *uuid.UUID, err error)
+	ConnectCoupleByInvite(ctx context.Context, userId uuid.UUID, inviteToken string)(coupleId *uuid.UUID, partnerId *uuid.UUID, err error)
 	EditPartnersNickname(ctx context.Context, userId uuid.UUID, coupleId uuid.UUID, nickname string) error
 	CheckPartnerNickname(ctx context.Context, userId uuid.UUID) (hasNickname bool, err error)
 	GetUserLanguage(ctx context.Context, userId uuid.UUID) (string, error)
+	GetUserById(ctx context.Context, userId uuid.UUID) (*UserModel, error)
+	DisconnectCouple(ctx context.Context, userId uuid.UUID) (partnerId *uuid.UUID, err error)
+}
+
+type PointsService interface{
+	AwardPoints(ctx context.Context, userId uuid.UUID, points int, reason string, sourceId *uuid.UUID) error
+	GetCouplePoints(ctx context.Context, coupleId uuid.UUID) (int, error)
+	GetCouplePointsHistory(ctx context.Context, coupleId uuid.UUID, days int) ([]DailyPointsModel, error)
 }
 
 type UsersRepo interface{
 	CreateUser(ctx context.Context, user *UserModel) (int, error)
 	DeleteUserById(ctx context.Context, userId uuid.UUID) (int, error)
 	GetTempCoupleByCode(ctx context.Context, code int) (*TempCoupleModel, error)
-	CheckTempCoupleById(ctx context.Context, userId uuid.UUID) (exists bool, err error)
-	UpdateTempCouple(ctx context.Context, tempCouple *TempCoupleModel) (int, error)
+	GetTempCoupleByInviteToken(ctx context.Context, tokenHash string) (*TempCoupleModel, error)
 	CreateTempCouple(ctx context.Context, tempCouple *TempCoupleModel) (int, error)
+	DeleteExpiredTempCouples(ctx context.Context, before time.Time) (qrImagesIds []uuid.UUID, err error)
 	GetCoupleByUserId(ctx context.Context, userId uuid.UUID) (*CoupleModel, error)
+	UpdateCoupleEndDate(ctx context.Context, coupleId uuid.UUID, endDate time.Time) (int, error)
 	DeleteTempCoupleById(ctx context.Context, id uuid.UUID) (int, error)
 	GetUserById(ctx context.Context, userId uuid.UUID) (*UserModel, error)
 	CreateCouple(ctx context.Context, couple *CoupleModel) (int, error)
@@ -37,9 +48,37 @@ type UsersRepo interface{
 	GetCoupleById(ctx context.Context, coupleId uuid.UUID) (*CoupleModel, error)
 	UpdateUserNicknameById(ctx context.Context, userId uuid.UUID, nickname string) (int, error)
 	GetTempCoupleFromUser(ctx context.Context, userId uuid.UUID)(*TempCoupleModel, error)
+	GetCouplePointsTotal(ctx context.Context, coupleId uuid.UUID) (int, error)
+	GetCoupleDailyPoints(ctx context.Context, coupleId uuid.UUID, from time.Time) ([]DailyPointsModel, error)
+	CheckCouplePointsReasonOnDay(ctx context.Context, coupleId uuid.UUID, reason string, day time.Time) (bool, error)
+	GetCoupleActiveDays(ctx context.Context, coupleId uuid.UUID, reasons []string, from time.Time) ([]time.Time, error)
+	LockCouplePoints(ctx context.Context, coupleId uuid.UUID) error
 }
 
 
 
+///////////////////////// TEMP COUPLES
+const MIN_COUPLE_CODE = 10000
+const MAX_COUPLE_CODE = 99999
+// a new code is drawn if the drawn one is already in use
+const MAX_COUPLE_CODE_ATTEMPTS = 10
+
+// files path of the invites QR codes
+const DOMAIN_NAME = "users"
+const INVITES = "invites"
+
 ///////////////////////// POINTS
-const COUPLE_POINTS_FOR_CONNECTING = 50
\ No newline at end of file
+const COUPLE_POINTS_FOR_CONNECTING = 50
+// bonus given once a day when the couple played every day of the streak
+const COUPLE_POINTS_FOR_STREAK = 30
+const STREAK_DAYS = 3
+const MAX_POINTS_HISTORY_DAYS = 365
+
+// reasons of the points entries
+const (
+	POINTS_REASON_CONNECTED = "CONNECTED"
+	POINTS_REASON_QUIZ_COMPLETED = "QUIZ_COMPLETED"
+	POINTS_REASON_CHALLENGE_WON = "CHALLENGE_WON"
+	POINTS_REASON_STREAK = "STREAK"
+	POINTS_REASON_QUIZ_GUESSED = "QUIZ_GUESSED"
+)
\ No newline at end of file
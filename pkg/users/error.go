@@ -14,6 +14,7 @@ var (
 	ErrorDeletingUser = errors.New("UNABLE_TO_DELETE_USER")
 	ErrorCreatingTempCouple = errors.New("UNABLE_TO_CREATE_CODE")
 	ErrorInvalidCode = errors.New("INVALID_CODE")
+	ErrorExpiredCode = errors.New("EXPIRED_CODE")
 	ErrorCantConnectWithYourself =  errors.New("CANT_CONNECT_WITH_YOURSELF")
 	ErrorConnectingCouple = errors.New("UNABLE_TO_CONNECT_COUPLE")
 	ErrorCreatingPoints = errors.New("UNABLE_TO_ADD_POINTS")
@@ -21,4 +22,9 @@ var (
 	ErrorUnableToCheckPartnerNickname = errors.New("UNABLE_TO_CHECK_PARTNER_NICKNAME")
 	ErrorUnableToGetTempCouple = errors.New("UNABLE_TO_GET_TEMP_COUPLE")
 	ErrorNoTempCoupleFound = errors.New("NO_TEMP_COUPLE_FOUND")
+	ErrorUserNotFound = errors.New("USER_NOT_FOUND")
+	ErrorUnableToGetUser = errors.New("UNABLE_TO_GET_USER")
+	ErrorInvalidPointsReason = errors.New("INVALID_POINTS_REASON")
+	ErrorGettingPoints = errors.New("UNABLE_TO_GET_POINTS")
+	ErrorDisconnectingCouple = errors.New("UNABLE_TO_DISCONNECT_COUPLE")
 )
\ No newline at end of file
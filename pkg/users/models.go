@@ -19,26 +19,56 @@ type UserModel struct {
 	LanguageCode string  
 }
 
+// the invite token is stored hashed, the plain one is only in the invite link
 type TempCoupleModel struct{
 	UserId 		uuid.UUID
 	Code 		int 
 	StartDate 	time.Time 
 	CreatedAt 	time.Time 
 	UpdatedAt 	time.Time
+	ExpiresAt 	time.Time
+	InviteToken *string
+	QrImageId 	*uuid.UUID
+	QrUrl 		*string
 }
 
+func (t *TempCoupleModel) IsExpired() bool{
+	return !t.ExpiresAt.After(time.Now())
+}
+
+// the partners slots have no ordering nor gender meaning
 type CoupleModel struct{
 	Id 		uuid.UUID
-	HeId 	uuid.UUID
-	SheId 	uuid.UUID
+	Partner1Id 	uuid.UUID
+	Partner2Id 	uuid.UUID
 	RelationStart 	time.Time 
 	EndDate 		*time.Time
 }
 
+func (c *CoupleModel) HasMember(userId uuid.UUID) bool{
+	return c.Partner1Id == userId || c.Partner2Id == userId
+}
+
+// returns the other member of the couple
+func (c *CoupleModel) GetPartnerId(userId uuid.UUID) uuid.UUID{
+	if c.Partner1Id == userId{
+		return c.Partner2Id
+	}
+	return c.Partner1Id
+}
+
 
 type PointsModel struct{
 	Id 			uuid.UUID
 	Day 		time.Time
 	Points 		int 
 	UserId 		*uuid.UUID
+	CoupleId 	*uuid.UUID
+	Reason 		string 
+	SourceId 	*uuid.UUID
+}
+
+type DailyPointsModel struct{
+	Day 		time.Time	`json:"day"`
+	Points 		int 		`json:"points"`
 }
\ No newline at end of file
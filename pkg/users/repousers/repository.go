@@ -11,6 +11,7 @@ import (
 	"github.com/diegobermudez03/couples-backend/pkg/infraestructure"
 	"github.com/diegobermudez03/couples-backend/pkg/users"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 
@@ -48,67 +49,71 @@ func (r *UsersPostgresRepo) DeleteUserById(ctx context.Context, userId uuid.UUID
 func (r *UsersPostgresRepo) GetTempCoupleByCode(ctx context.Context, code int) (*users.TempCoupleModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT user_id, code, start_date, created_at, updated_at
+		`SELECT user_id, code, start_date, created_at, updated_at, expires_at, invite_token, qr_image_id, qr_url
 		FROM temp_couples WHERE code = $1`,
 		code,
 	)
-	tempCouple := new(users.TempCoupleModel) 
-	err := row.Scan(&tempCouple.UserId, &tempCouple.Code, &tempCouple.StartDate, &tempCouple.CreatedAt, &tempCouple.UpdatedAt)
-	if errors.Is(err, sql.ErrNoRows){
-		return nil, nil
-	}else if err != nil{
-		log.Print("error getting couple: ", err.Error())
-		return nil, err
-	}
-	return tempCouple, nil
+	return r.scanTempCouple(row)
 }
 
-func (r *UsersPostgresRepo) CheckTempCoupleById(ctx context.Context, userId uuid.UUID) (bool, error){
+func (r *UsersPostgresRepo) GetTempCoupleByInviteToken(ctx context.Context, tokenHash string) (*users.TempCoupleModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT user_id FROM temp_couples WHERE user_id = $1`,
-		userId,
+		`SELECT user_id, code, start_date, created_at, updated_at, expires_at, invite_token, qr_image_id, qr_url
+		FROM temp_couples WHERE invite_token = $1`,
+		tokenHash,
 	)
-	var result uuid.UUID 
-	if err := row.Scan(&result); errors.Is(err, sql.ErrNoRows){
-		return false, nil
-	}else if err != nil{
-		log.Print("error checking couple: ", err.Error())
-		return false, err
-	}
-	return true, nil
+	return r.scanTempCouple(row)
 }
 
-func (r *UsersPostgresRepo) UpdateTempCouple(ctx context.Context, tempCouple *users.TempCoupleModel) (int, error){
+// if the code is already used nothing is inserted
+func (r *UsersPostgresRepo) CreateTempCouple(ctx context.Context, tempCouple *users.TempCoupleModel) (int, error){
 	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
 		return ex.ExecContext(
 			ctx,
-			`UPDATE temp_couples SET code = $1, start_date = $2, updated_at = $3 WHERE user_id = $4`,
-			tempCouple.Code, tempCouple.StartDate ,time.Now(), tempCouple.UserId,
+			`INSERT INTO temp_couples (user_id, code, start_date, updated_at, created_at, expires_at, invite_token, qr_image_id, qr_url)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT DO NOTHING`,
+			tempCouple.UserId, tempCouple.Code, tempCouple.StartDate ,time.Now(), time.Now(), tempCouple.ExpiresAt,
+			tempCouple.InviteToken, tempCouple.QrImageId, tempCouple.QrUrl,
 		)
 	})
 }
 
-func (r *UsersPostgresRepo) CreateTempCouple(ctx context.Context, tempCouple *users.TempCoupleModel) (int, error){
-	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
-		return ex.ExecContext(
-			ctx,
-			`INSERT INTO temp_couples (user_id, code, start_date, updated_at, created_at) VALUES($1, $2, $3, $4, $5)`,
-			tempCouple.UserId, tempCouple.Code, tempCouple.StartDate ,time.Now(), time.Now(),
-		)
-	})
+func (r *UsersPostgresRepo) DeleteExpiredTempCouples(ctx context.Context, before time.Time) ([]uuid.UUID, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`DELETE FROM temp_couples WHERE expires_at <= $1 RETURNING qr_image_id`,
+		before,
+	)
+	if err != nil{
+		log.Print("error deleting expired temp couples: ", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+	imagesIds := []uuid.UUID{}
+	for rows.Next(){
+		var imageId *uuid.UUID
+		if err := rows.Scan(&imageId); err != nil{
+			return nil, err
+		}
+		if imageId != nil{
+			imagesIds = append(imagesIds, *imageId)
+		}
+	}
+	return imagesIds, rows.Err()
 }
 
 func (r *UsersPostgresRepo)  GetCoupleByUserId(ctx context.Context, userId uuid.UUID) (*users.CoupleModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, he_id, she_id, relation_start, end_date
-		FROM couples WHERE he_id = $1 OR she_id = $1`,
+		`SELECT id, partner1_id, partner2_id, relation_start, end_date
+		FROM couples WHERE (partner1_id = $1 OR partner2_id = $1) AND end_date IS NULL`,
 		userId,
 	)
 	coupleModel := new(users.CoupleModel)
 
-	err := row.Scan(&coupleModel.Id, &coupleModel.HeId, &coupleModel.SheId, &coupleModel.RelationStart, &coupleModel.EndDate)
+	err := row.Scan(&coupleModel.Id, &coupleModel.Partner1Id, &coupleModel.Partner2Id, &coupleModel.RelationStart, &coupleModel.EndDate)
 	if errors.Is(err, sql.ErrNoRows){
 		return nil, nil
 	}else if err != nil{
@@ -118,6 +123,16 @@ func (r *UsersPostgresRepo)  GetCoupleByUserId(ctx context.Context, userId uuid.
 	return coupleModel, nil
 }
 
+func (r *UsersPostgresRepo) UpdateCoupleEndDate(ctx context.Context, coupleId uuid.UUID, endDate time.Time) (int, error){
+	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`UPDATE couples SET end_date = $1 WHERE id = $2 AND end_date IS NULL`,
+			endDate, coupleId,
+		)
+	})
+}
+
 func (r *UsersPostgresRepo)  DeleteTempCoupleById(ctx context.Context, id uuid.UUID) (int, error){
 	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
 		return ex.ExecContext(
@@ -132,9 +147,9 @@ func (r *UsersPostgresRepo) CreateCouple(ctx context.Context, couple *users.Coup
 	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
 		return ex.ExecContext(
 			ctx, 
-			`INSERT INTO couples(id, he_id, she_id, relation_start)
+			`INSERT INTO couples(id, partner1_id, partner2_id, relation_start)
 			VALUES($1, $2, $3, $4)`,
-			couple.Id, couple.HeId, couple.SheId, couple.RelationStart,
+			couple.Id, couple.Partner1Id, couple.Partner2Id, couple.RelationStart,
 		)
 	})
 }
@@ -143,9 +158,9 @@ func (r *UsersPostgresRepo) CreateCouplePoints(ctx context.Context, points *user
 	return infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
 		return ex.ExecContext(
 			ctx, 
-			`INSERT INTO points(id, points, day)
-			VALUES($1, $2, $3)`,
-			points.Id, points.Points, points.Day,
+			`INSERT INTO points(id, points, day, user_id, couple_id, reason, source_id)
+			VALUES($1, $2, $3, $4, $5, $6, $7)`,
+			points.Id, points.Points, points.Day, points.UserId, points.CoupleId, points.Reason, points.SourceId,
 		)
 	})
 }
@@ -173,13 +188,13 @@ func (r *UsersPostgresRepo) GetUserById(ctx context.Context, userId uuid.UUID) (
 func (r *UsersPostgresRepo) GetCoupleById(ctx context.Context, coupleId uuid.UUID) (*users.CoupleModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT id, he_id, she_id, relation_start, end_date
+		`SELECT id, partner1_id, partner2_id, relation_start, end_date
 		FROM couples WHERE id = $1`,
 		coupleId,
 	)
 	coupleModel := new(users.CoupleModel)
 
-	err := row.Scan(&coupleModel.Id, &coupleModel.HeId, &coupleModel.SheId, &coupleModel.RelationStart, &coupleModel.EndDate)
+	err := row.Scan(&coupleModel.Id, &coupleModel.Partner1Id, &coupleModel.Partner2Id, &coupleModel.RelationStart, &coupleModel.EndDate)
 	if errors.Is(err, sql.ErrNoRows){
 		return nil, nil
 	}else if err != nil{
@@ -202,17 +217,109 @@ func (r *UsersPostgresRepo) UpdateUserNicknameById(ctx context.Context, userId u
 func (r *UsersPostgresRepo) GetTempCoupleFromUser(ctx context.Context, userId uuid.UUID)(*users.TempCoupleModel, error){
 	row := r.db.QueryRowContext(
 		ctx,
-		`SELECT user_id, code, start_date, updated_at, created_at
+		`SELECT user_id, code, start_date, created_at, updated_at, expires_at, invite_token, qr_image_id, qr_url
 		FROM temp_couples WHERE user_id = $1`,
 		userId,
 	)
-	tempCouple := new(users.TempCoupleModel)
-	if err := row.Scan(&tempCouple.UserId, &tempCouple.Code, &tempCouple.StartDate, &tempCouple.UpdatedAt, &tempCouple.CreatedAt); err != nil{
-		if errors.Is(err, sql.ErrNoRows){
-			return nil, nil 
-		}else{
-			return nil, err 
+	return r.scanTempCouple(row)
+}
+
+func (r *UsersPostgresRepo) GetCouplePointsTotal(ctx context.Context, coupleId uuid.UUID) (int, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT COALESCE(SUM(points), 0) FROM points WHERE couple_id = $1`,
+		coupleId,
+	)
+	var total int
+	if err := row.Scan(&total); err != nil{
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *UsersPostgresRepo) GetCoupleDailyPoints(ctx context.Context, coupleId uuid.UUID, from time.Time) ([]users.DailyPointsModel, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT day, SUM(points) FROM points 
+		WHERE couple_id = $1 AND day >= $2
+		GROUP BY day ORDER BY day DESC`,
+		coupleId, from,
+	)
+	if err != nil{
+		return nil, err
+	}
+	defer rows.Close()
+	history := []users.DailyPointsModel{}
+	for rows.Next(){
+		model := users.DailyPointsModel{}
+		if err := rows.Scan(&model.Day, &model.Points); err != nil{
+			return nil, err
+		}
+		history = append(history, model)
+	}
+	return history, nil
+}
+
+func (r *UsersPostgresRepo) CheckCouplePointsReasonOnDay(ctx context.Context, coupleId uuid.UUID, reason string, day time.Time) (bool, error){
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT count(id) FROM points WHERE couple_id = $1 AND reason = $2 AND day = $3`,
+		coupleId, reason, day,
+	)
+	var count int
+	if err := row.Scan(&count); err != nil{
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// the days since the given one in which the couple earned points for any of the reasons
+func (r *UsersPostgresRepo) GetCoupleActiveDays(ctx context.Context, coupleId uuid.UUID, reasons []string, from time.Time) ([]time.Time, error){
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT DISTINCT day FROM points 
+		WHERE couple_id = $1 AND reason = ANY($2) AND day >= $3
+		ORDER BY day DESC`,
+		coupleId, pq.Array(reasons), from,
+	)
+	if err != nil{
+		return nil, err
+	}
+	defer rows.Close()
+	days := []time.Time{}
+	for rows.Next(){
+		var day time.Time
+		if err := rows.Scan(&day); err != nil{
+			return nil, err
 		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// the lock is held until the transaction ends, so the points of the couple are awarded one at a time
+func (r *UsersPostgresRepo) LockCouplePoints(ctx context.Context, coupleId uuid.UUID) error{
+	_, err := infraestructure.ExecSQL(ctx, r.db, func(ex infraestructure.Executor) (sql.Result, error) {
+		return ex.ExecContext(
+			ctx,
+			`SELECT pg_advisory_xact_lock(hashtext('points' || $1::text))`,
+			coupleId,
+		)
+	})
+	return err
+}
+
+func (r *UsersPostgresRepo) scanTempCouple(row *sql.Row) (*users.TempCoupleModel, error){
+	tempCouple := new(users.TempCoupleModel)
+	err := row.Scan(
+		&tempCouple.UserId, &tempCouple.Code, &tempCouple.StartDate, &tempCouple.CreatedAt, &tempCouple.UpdatedAt,
+		&tempCouple.ExpiresAt, &tempCouple.InviteToken, &tempCouple.QrImageId, &tempCouple.QrUrl,
+	)
+	if errors.Is(err, sql.ErrNoRows){
+		return nil, nil
+	}else if err != nil{
+		log.Print("error getting temp couple: ", err.Error())
+		return nil, err
 	}
 	return tempCouple, nil
-}
\ No newline at end of file
+}